@@ -0,0 +1,539 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ymtdzzz/lazypkg/executors"
+)
+
+var allResultStyle = lipgloss.NewStyle().MarginLeft(2)
+
+// allResult is the outcome of the update of a manager dispatched from the combined list
+type allResult struct {
+	mgr   string
+	count int
+	err   error
+}
+
+// AllModel is the combined list of the packages of every manager
+type AllModel struct {
+	config     Config
+	keyMap     packagesKeyMap
+	spinner    spinner.Model
+	spinnerStr *string
+	mgrs       []string
+	pkglists   map[string]*PackagesModel
+	pkgs       map[string][]*executors.PackageInfo
+	list       list.Model
+	focus      *bool
+	selection  map[string]bool
+	loading    map[string]bool
+	// pending is the number of packages of the managers still being updated by the last run
+	pending map[string]int
+	results *[]allResult
+	plans   PlansModel
+	// plan is the running update plan
+	plan *planRun
+	w, h int
+}
+
+func NewAllModel(config Config, mgrs []string, pkglists map[string]*PackagesModel) AllModel {
+	s := spinner.New()
+	s.Spinner = spinner.Line
+	ss := s.View()
+
+	selection := map[string]bool{}
+	loading := map[string]bool{}
+	focus := false
+	delegate := newItemDelegate(&ss, selection, loading, &focus)
+	delegate.progress = func(k string) (float64, bool) {
+		mgr, name := splitAllKey(k)
+		if pkg, ok := pkglists[mgr]; ok {
+			if reporter, ok := pkg.executor.(executors.ProgressReporter); ok {
+				return reporter.Progress(name)
+			}
+		}
+		return 0, false
+	}
+	delegate.failed = func(k string) bool {
+		mgr, name := splitAllKey(k)
+		if pkg, ok := pkglists[mgr]; ok {
+			_, failed := pkg.failures[name]
+			return failed
+		}
+		return false
+	}
+	l := list.New(
+		[]list.Item{},
+		delegate,
+		0,
+		0,
+	)
+	l.Title = fmt.Sprintf("Packages [%s]", PACKAGE_MANAGER_ALL)
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(true)
+	l.SetShowHelp(false)
+	l.KeyMap = newPackagesListKeyMap(config.File.Keys)
+	l.DisableQuitKeybindings()
+	l.Styles.Title = blurTitleStyle
+	l.Styles.HelpStyle = helpStyle
+	km := newPackagesKeyMap(config.File.Keys)
+	keys := []key.Binding{km.Toggle, km.Back, km.Update, km.UpdateAll, km.Security, km.Retry, km.ErrorOutput, km.SavePlan, km.Plans}
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return keys
+	}
+	l.AdditionalFullHelpKeys = func() []key.Binding {
+		return keys
+	}
+
+	return AllModel{
+		config:     config,
+		keyMap:     km,
+		spinner:    s,
+		spinnerStr: &ss,
+		mgrs:       mgrs,
+		pkglists:   pkglists,
+		pkgs:       map[string][]*executors.PackageInfo{},
+		list:       l,
+		focus:      &focus,
+		selection:  selection,
+		loading:    loading,
+		pending:    map[string]int{},
+		results:    &[]allResult{},
+		plans:      NewPlansModel(config),
+	}
+}
+
+// allKey identifies a package in the combined list, e.g. npm/@angular/cli
+func allKey(mgr, name string) string {
+	return mgr + "/" + name
+}
+
+// splitAllKey returns the manager and the package of the key, the manager names do not contain a slash
+func splitAllKey(k string) (string, string) {
+	mgr, name, _ := strings.Cut(k, "/")
+	return mgr, name
+}
+
+// groupByManager returns the packages of the keys per manager
+func groupByManager(keys []string) map[string][]string {
+	result := map[string][]string{}
+	for _, k := range keys {
+		mgr, name := splitAllKey(k)
+		result[mgr] = append(result[mgr], name)
+	}
+	return result
+}
+
+func (m AllModel) Init() tea.Cmd {
+	return m.spinner.Tick
+}
+
+func (m AllModel) Update(msg tea.Msg) (AllModel, tea.Cmd) {
+	var cmd tea.Cmd
+
+	cmds := []tea.Cmd{m.spinner.Tick}
+
+	switch msg := msg.(type) {
+	case packageUpdateMsg:
+		if _, ok := m.pkglists[msg.name]; ok {
+			m.pkgs[msg.name] = msg.pkgs
+			for k := range m.selection {
+				if mgr, _ := splitAllKey(k); mgr == msg.name {
+					delete(m.selection, k)
+				}
+			}
+			// the packages list of the manager keeps the failed packages listed, which stay selected for a retry
+			for pkg := range m.pkglists[msg.name].failures {
+				m.selection[allKey(msg.name, pkg)] = true
+			}
+			cmds = append(cmds, m.list.SetItems(getAllItems(m.mgrs, m.pkglists, m.pkgs)))
+		}
+	case updatePackagesStartMsg:
+		for _, pkg := range msg.pkgs {
+			m.loading[allKey(msg.name, pkg)] = true
+		}
+	case updatePackagesFinishMsg:
+		for _, pkg := range msg.pkgs {
+			m.loading[allKey(msg.name, pkg)] = false
+		}
+		if count, ok := m.pending[msg.name]; ok {
+			delete(m.pending, msg.name)
+			r := allResult{mgr: msg.name, count: count, err: msg.err}
+			*m.results = append(*m.results, r)
+			m.logResult(r)
+			m.SetSize(m.w, m.h)
+		}
+		if m.plan != nil && m.plan.finish(msg.name, msg.err) {
+			cmds = append(cmds, m.startPhase())
+		}
+	case planStartMsg:
+		phases, err := planPhases(m.config.phases(), msg.plan)
+		if err != nil {
+			logf(PACKAGE_MANAGER_ALL, "Error running plan %s: %v", msg.name, err)
+			break
+		}
+		// a plan still waiting for a manager, e.g. after the password input was canceled, is abandoned
+		m.plan = newPlanRun(msg.name, phases)
+		*m.results = nil
+		m.plans.Close()
+		m.SetSize(m.w, m.h)
+		cmds = append(cmds, m.startPhase())
+	case planDeletedMsg:
+		m.plans, cmd = m.plans.Update(msg)
+		cmds = append(cmds, cmd)
+	case allUpdateStartMsg:
+		*m.results = nil
+		for mgr, count := range msg.counts {
+			m.pending[mgr] = count
+		}
+		m.SetSize(m.w, m.h)
+	case updateAllPackagesMsg:
+		if msg.name == PACKAGE_MANAGER_ALL {
+			cmds = append(cmds, m.updateAllCmd())
+		}
+	case clickMsg:
+		if msg.pane == keyPanePackages && msg.name == PACKAGE_MANAGER_ALL && !m.plans.IsShown() &&
+			clickListItem(&m.list, m.selection, msg.y) && !*m.focus {
+			cmds = append(cmds, func() tea.Msg {
+				return FocusPackagesMsg{Name: PACKAGE_MANAGER_ALL}
+			})
+		}
+	case scrollMsg:
+		if msg.pane == keyPanePackages && msg.name == PACKAGE_MANAGER_ALL && !m.plans.IsShown() {
+			scrollList(&m.list, msg.lines)
+		}
+	case spinner.TickMsg:
+		m.spinner, cmd = m.spinner.Update(msg)
+		*m.spinnerStr = m.spinner.View()
+		return m, cmd
+	}
+
+	if *m.focus && m.plans.IsShown() {
+		if _, ok := msg.(tea.KeyMsg); ok {
+			m.plans, cmd = m.plans.Update(msg)
+			return m, tea.Batch(append(cmds, cmd)...)
+		}
+	}
+
+	if *m.focus {
+		switch msg := msg.(type) {
+		case tea.KeyMsg:
+			if m.IsFiltering() {
+				// the keys are typed into the filter
+				break
+			}
+			switch {
+			case key.Matches(msg, m.keyMap.Toggle):
+				if k := m.selectedKey(); k != "" {
+					m.selection[k] = !m.selection[k]
+				}
+			case key.Matches(msg, m.keyMap.Back):
+				cmds = append(cmds, func() tea.Msg {
+					return FocusManagersMsg{}
+				})
+			case key.Matches(msg, m.keyMap.Update):
+				var keys []string
+				for k, v := range m.selection {
+					if v {
+						keys = append(keys, k)
+					}
+				}
+				sort.Strings(keys)
+				if len(keys) > 0 {
+					for k := range m.selection {
+						m.selection[k] = false
+					}
+					cmds = append(cmds, m.updateCmd(
+						fmt.Sprintf("Selected %d packages of %d managers will be updated", len(keys), len(groupByManager(keys))),
+						keys,
+					))
+				} else if k := m.selectedKey(); k != "" {
+					mgr, pkg := splitAllKey(k)
+					cmds = append(cmds, m.updateCmd(fmt.Sprintf("Package %s of %s will be updated", pkg, mgr), []string{k}))
+				}
+			case key.Matches(msg, m.keyMap.UpdateAll):
+				cmds = append(cmds, m.updateAllCmd())
+			case key.Matches(msg, m.keyMap.Security):
+				keys := m.keys(func(pkg *executors.PackageInfo) bool {
+					return pkg.Security
+				})
+				if len(keys) == 0 {
+					logf(PACKAGE_MANAGER_ALL, "No security updates found")
+					break
+				}
+				cmds = append(cmds, m.updateCmd(
+					fmt.Sprintf("%d security updates of %d managers will be applied", len(keys), len(groupByManager(keys))),
+					keys,
+				))
+			case key.Matches(msg, m.keyMap.Retry):
+				var keys []string
+				for _, mgr := range m.mgrs {
+					for pkg := range m.pkglists[mgr].failures {
+						keys = append(keys, allKey(mgr, pkg))
+					}
+				}
+				if len(keys) == 0 {
+					logf(PACKAGE_MANAGER_ALL, "No failed updates to retry")
+					break
+				}
+				sort.Strings(keys)
+				for _, k := range keys {
+					m.selection[k] = false
+				}
+				cmds = append(cmds, m.updateCmd(
+					fmt.Sprintf("%d failed packages of %d managers will be updated again", len(keys), len(groupByManager(keys))),
+					keys,
+				))
+			case key.Matches(msg, m.keyMap.ErrorOutput):
+				if k := m.selectedKey(); k != "" {
+					mgr, pkg := splitAllKey(k)
+					cmds = append(cmds, m.pkglists[mgr].errorOutputCmd(pkg))
+				}
+			case key.Matches(msg, m.keyMap.SavePlan):
+				var keys []string
+				for k, v := range m.selection {
+					if v {
+						keys = append(keys, k)
+					}
+				}
+				if len(keys) == 0 {
+					logf(PACKAGE_MANAGER_ALL, "Select packages to save them as a plan")
+					break
+				}
+				plan := Plan(groupByManager(keys))
+				for _, pkgs := range plan {
+					sort.Strings(pkgs)
+				}
+				cmds = append(cmds, m.plans.Save(plan))
+			case key.Matches(msg, m.keyMap.Plans):
+				m.plans.Open()
+			}
+		}
+
+		m.list, cmd = m.list.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+// keys returns the keys of the listed packages matching the condition
+func (m AllModel) keys(cond func(pkg *executors.PackageInfo) bool) []string {
+	var keys []string
+	for _, mgr := range m.mgrs {
+		for _, pkg := range m.pkgs[mgr] {
+			if cond(pkg) {
+				keys = append(keys, allKey(mgr, pkg.Name))
+			}
+		}
+	}
+	return keys
+}
+
+// updateAllCmd updates every package which could be checked
+func (m AllModel) updateAllCmd() tea.Cmd {
+	keys := m.keys(func(pkg *executors.PackageInfo) bool {
+		return pkg.CheckError == ""
+	})
+	return m.updateCmd(
+		fmt.Sprintf("All %d packages of %d managers will be updated", len(keys), len(groupByManager(keys))),
+		keys,
+	)
+}
+
+// updateCmd asks for the confirmation and fans out the update to the managers of the packages
+func (m AllModel) updateCmd(msg string, keys []string) tea.Cmd {
+	if len(keys) == 0 {
+		return nil
+	}
+	byMgr := groupByManager(keys)
+	mgrs := make([]string, 0, len(byMgr))
+	for mgr := range byMgr {
+		mgrs = append(mgrs, mgr)
+	}
+	sort.Strings(mgrs)
+
+	var (
+		rows    []string
+		subcmds []tea.Cmd
+	)
+	for _, mgr := range mgrs {
+		pkgs := byMgr[mgr]
+		rows = append(rows, truncate(fmt.Sprintf("%s (%d): %s", mgr, len(pkgs), strings.Join(pkgs, ", ")), DIALOG_MAX_LINE_LENGTH))
+		pm := m.pkglists[mgr]
+		subcmds = append(subcmds, tea.Sequence(
+			func() tea.Msg {
+				return updatePackagesStartMsg{name: mgr, pkgs: pkgs, bulk: true}
+			},
+			pm.bulkUpdatePackageCmd(pkgs),
+		))
+	}
+
+	counts := map[string]int{}
+	for mgr, pkgs := range byMgr {
+		counts[mgr] = len(pkgs)
+	}
+	return showDialogWithDetailCmd(
+		msg,
+		strings.Join(rows, "\n"),
+		tea.Sequence(append([]tea.Cmd{func() tea.Msg {
+			return allUpdateStartMsg{counts: counts}
+		}}, subcmds...)...),
+	)
+}
+
+// startPhase updates the managers of the next phase of the running plan at the same time
+func (m *AllModel) startPhase() tea.Cmd {
+	from := m.plan.current + 1
+	p := m.plan.next()
+	skipped := m.skippedCmd(from)
+	if p == nil {
+		logf(PACKAGE_MANAGER_ALL, "Plan %s finished: %s", m.plan.name, m.plan.summary())
+		m.plan = nil
+		return skipped
+	}
+
+	cmds := []tea.Cmd{skipped}
+	for _, mgr := range p.mgrs {
+		pkgs := p.pkgs[mgr]
+		m.pending[mgr] = len(pkgs)
+		pm, ok := m.pkglists[mgr]
+		if !ok {
+			cmds = append(cmds, func() tea.Msg {
+				return updatePackagesFinishMsg{name: mgr, err: fmt.Errorf("%s is not available", mgr)}
+			})
+			continue
+		}
+		cmds = append(cmds, tea.Sequence(
+			func() tea.Msg {
+				return updatePackagesStartMsg{name: mgr, pkgs: pkgs, bulk: true}
+			},
+			pm.bulkUpdatePackageCmd(pkgs),
+		))
+	}
+	return tea.Batch(cmds...)
+}
+
+// skippedCmd reports the packages of the phases skipped by the running plan since the phase at from
+func (m AllModel) skippedCmd(from int) tea.Cmd {
+	counts := map[string]int{}
+	for _, p := range m.plan.phases[from:] {
+		if m.plan.status[p.name] != phaseSkipped {
+			continue
+		}
+		for _, mgr := range p.mgrs {
+			counts[mgr] = len(p.pkgs[mgr])
+		}
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		return updatesSkippedMsg{counts: counts}
+	}
+}
+
+func (m AllModel) logResult(r allResult) {
+	if r.err != nil {
+		logf(PACKAGE_MANAGER_ALL, "%s: update failed: %v", r.mgr, r.err)
+		return
+	}
+	logf(PACKAGE_MANAGER_ALL, "%s: %d packages updated", r.mgr, r.count)
+}
+
+// resultsView summarizes the last run per manager
+func (m AllModel) resultsView() string {
+	if len(*m.results) == 0 {
+		return ""
+	}
+	var parts []string
+	for _, r := range *m.results {
+		if r.err != nil {
+			first, _, _ := strings.Cut(r.err.Error(), "\n")
+			parts = append(parts, fmt.Sprintf("%s ✗ %s", r.mgr, first))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s ✓ %d", r.mgr, r.count))
+	}
+	return allResultStyle.Render(truncate("Last run: "+strings.Join(parts, "  "), max(m.w, 1)))
+}
+
+func (m AllModel) View() string {
+	if m.plans.IsShown() {
+		return m.plans.View()
+	}
+	if results := m.resultsView(); results != "" {
+		return lipgloss.JoinVertical(lipgloss.Left, m.list.View(), results)
+	}
+	return m.list.View()
+}
+
+// IsFiltering reports whether the filter of the list or the name of a plan is being typed
+func (m AllModel) IsFiltering() bool {
+	return m.list.FilterState() == list.Filtering || m.plans.IsNaming()
+}
+
+func (m AllModel) IsFocus() bool {
+	return *m.focus
+}
+
+func (m AllModel) ShortHelp() []key.Binding {
+	if m.plans.IsShown() {
+		return m.plans.ShortHelp()
+	}
+	return m.list.ShortHelp()
+}
+
+func (m AllModel) FullHelp() [][]key.Binding {
+	if m.plans.IsShown() {
+		return m.plans.FullHelp()
+	}
+	return m.list.FullHelp()
+}
+
+func (m *AllModel) SetSize(w, h int) {
+	m.w, m.h = w, h
+	m.plans.SetSize(w, h)
+	if len(*m.results) > 0 {
+		h--
+	}
+	m.list.SetSize(w, max(h, 0))
+}
+
+func (m *AllModel) Focus(focus bool) {
+	*m.focus = focus
+	if focus {
+		m.list.Styles.Title = titleStyle
+	} else {
+		m.list.Styles.Title = blurTitleStyle
+	}
+}
+
+func (m AllModel) selectedKey() string {
+	if item := m.list.SelectedItem(); item != nil {
+		return item.FilterValue()
+	}
+	return ""
+}
+
+// getAllItems returns the rows of the packages of every manager, tagged with the icon of the manager
+func getAllItems(mgrs []string, pkglists map[string]*PackagesModel, pkgs map[string][]*executors.PackageInfo) []list.Item {
+	rows := []list.Item{}
+	for _, mgr := range mgrs {
+		for _, li := range getPackageItems(sortPackages(pkgs[mgr], sortByName, false), false) {
+			i := li.(item)
+			i.icon = pkglists[mgr].Icon()
+			i.mgr = mgr
+			rows = append(rows, i)
+		}
+	}
+	return rows
+}
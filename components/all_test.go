@@ -0,0 +1,77 @@
+package components
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ymtdzzz/lazypkg/executors"
+)
+
+func TestGroupByManager(t *testing.T) {
+	got := groupByManager([]string{
+		allKey("apt", "curl"),
+		allKey("npm", "@angular/cli"),
+		allKey("apt", "git"),
+		allKey("compose", "monitoring/grafana"),
+	})
+	assert.Equal(t, map[string][]string{
+		"apt":     {"curl", "git"},
+		"npm":     {"@angular/cli"},
+		"compose": {"monitoring/grafana"},
+	}, got)
+}
+
+func TestGetAllItems(t *testing.T) {
+	apt := NewPackageModel(Config{}, PACKAGE_MANAGER_APT, ICON_APT, &executors.AptExecutor{})
+	npm := NewPackageModel(Config{}, PACKAGE_MANAGER_NPM, ICON_NPM, &executors.NpmExecutor{})
+	pkglists := map[string]*PackagesModel{
+		PACKAGE_MANAGER_APT: &apt,
+		PACKAGE_MANAGER_NPM: &npm,
+	}
+	pkgs := map[string][]*executors.PackageInfo{
+		PACKAGE_MANAGER_APT: {
+			{Name: "git", OldVersion: "2.25.1", NewVersion: "2.39.0"},
+			{Name: "curl", OldVersion: "7.68.0", NewVersion: "7.85.0"},
+		},
+		PACKAGE_MANAGER_NPM: {
+			{Name: "@angular/cli", OldVersion: "17.0.0", NewVersion: "18.0.0", Group: "@angular"},
+		},
+	}
+
+	type row struct {
+		key  string
+		icon rune
+	}
+	var got []row
+	for _, li := range getAllItems([]string{PACKAGE_MANAGER_APT, PACKAGE_MANAGER_NPM}, pkglists, pkgs) {
+		i := li.(item)
+		got = append(got, row{i.FilterValue(), i.icon})
+	}
+	assert.Equal(t, []row{
+		{"apt/curl", ICON_APT},
+		{"apt/git", ICON_APT},
+		{"npm/@angular/cli", ICON_NPM},
+	}, got)
+}
+
+func TestStartPhaseSkipped(t *testing.T) {
+	m := NewAllModel(Config{}, nil, map[string]*PackagesModel{})
+	phases, err := planPhases(defaultPhases, Plan{
+		"apt": {"curl"},
+		"npm": {"react", "lodash"},
+	})
+	assert.NoError(t, err)
+	m.plan = newPlanRun("weekly", phases)
+
+	// apt is not available, so the phase of npm depending on it is skipped
+	cmd := m.startPhase()
+	assert.NotNil(t, cmd)
+	assert.True(t, m.plan.finish("apt", errors.New("apt is not available")))
+
+	cmd = m.startPhase()
+	assert.Nil(t, m.plan)
+	if assert.NotNil(t, cmd) {
+		assert.Equal(t, updatesSkippedMsg{counts: map[string]int{"npm": 2}}, cmd())
+	}
+}
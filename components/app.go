@@ -12,23 +12,47 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/ymtdzzz/lazypkg/executors"
+	"github.com/ymtdzzz/lazypkg/osv"
 )
 
 const (
 	PACKAGE_MANAGER_APT      = "apt"
 	PACKAGE_MANAGER_HOMEBREW = "homebrew"
 	PACKAGE_MANAGER_DOCKER   = "docker"
+	PACKAGE_MANAGER_PODMAN   = "podman"
+	PACKAGE_MANAGER_NERDCTL  = "nerdctl"
+	PACKAGE_MANAGER_COMPOSE  = "compose"
 	PACKAGE_MANAGER_NPM      = "npm"
 	PACKAGE_MANAGER_GEM      = "gem"
+	// PACKAGE_MANAGER_ALL is the virtual manager listing the packages of every manager
+	PACKAGE_MANAGER_ALL = "All"
 
 	ICON_APT      = '\uebc6'
 	ICON_HOMEBREW = '\uf0fc'
 	ICON_DOCKER   = '\uf21f'
+	ICON_PODMAN   = '\ue7a7'
+	ICON_NERDCTL  = '\uf1b3'
+	ICON_COMPOSE  = '\uf1b2'
 	ICON_NPM      = '\ued0d'
 	ICON_GEM      = '\uf219'
+	ICON_ALL      = '\uf03a'
 )
 
 var (
+	// baseManagers are available unless excluded, and optionalManagers when enabled as a feature
+	baseManagers     = []string{PACKAGE_MANAGER_APT, PACKAGE_MANAGER_HOMEBREW, PACKAGE_MANAGER_NPM, PACKAGE_MANAGER_GEM}
+	optionalManagers = []string{PACKAGE_MANAGER_DOCKER, PACKAGE_MANAGER_PODMAN, PACKAGE_MANAGER_NERDCTL, PACKAGE_MANAGER_COMPOSE}
+	managerIcons     = map[string]rune{
+		PACKAGE_MANAGER_APT:      ICON_APT,
+		PACKAGE_MANAGER_HOMEBREW: ICON_HOMEBREW,
+		PACKAGE_MANAGER_DOCKER:   ICON_DOCKER,
+		PACKAGE_MANAGER_PODMAN:   ICON_PODMAN,
+		PACKAGE_MANAGER_NERDCTL:  ICON_NERDCTL,
+		PACKAGE_MANAGER_COMPOSE:  ICON_COMPOSE,
+		PACKAGE_MANAGER_NPM:      ICON_NPM,
+		PACKAGE_MANAGER_GEM:      ICON_GEM,
+	}
+
 	docStyle = lipgloss.NewStyle().
 			Margin(1, 2)
 	docStyleRightBorder = docStyle.
@@ -40,55 +64,147 @@ var (
 )
 
 type mainKeyMap struct {
-	quit key.Binding
+	quit           key.Binding
+	help           key.Binding
+	growManagers   key.Binding
+	shrinkManagers key.Binding
+	growLogs       key.Binding
+	shrinkLogs     key.Binding
+	zoomPackages   key.Binding
+	zoomLogs       key.Binding
+	layout         key.Binding
 }
 
-func newMainKeyMap() mainKeyMap {
-	return mainKeyMap{
+func newMainKeyMap(keys KeyConfig) mainKeyMap {
+	km := mainKeyMap{
 		quit: key.NewBinding(
 			key.WithKeys("q"),
 			key.WithHelp("q", "quit"),
 		),
+		help: key.NewBinding(
+			key.WithKeys("?"),
+			key.WithHelp("?", "help"),
+		),
+		growManagers: key.NewBinding(
+			key.WithKeys(">"),
+			key.WithHelp(">", "widen managers"),
+		),
+		shrinkManagers: key.NewBinding(
+			key.WithKeys("<"),
+			key.WithHelp("<", "narrow managers"),
+		),
+		growLogs: key.NewBinding(
+			key.WithKeys("+", "="),
+			key.WithHelp("+", "enlarge logs"),
+		),
+		shrinkLogs: key.NewBinding(
+			key.WithKeys("-"),
+			key.WithHelp("-", "shrink logs"),
+		),
+		zoomPackages: key.NewBinding(
+			key.WithKeys("z"),
+			key.WithHelp("z", "zoom packages"),
+		),
+		zoomLogs: key.NewBinding(
+			key.WithKeys("Z"),
+			key.WithHelp("Z", "zoom logs"),
+		),
+		layout: key.NewBinding(
+			key.WithKeys("L"),
+			key.WithHelp("L", "switch layout"),
+		),
+	}
+	remapKeys(keys, keyPaneGlobal, km.bindings())
+	return km
+}
+
+func (km *mainKeyMap) bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"quit":            &km.quit,
+		"help":            &km.help,
+		"grow_managers":   &km.growManagers,
+		"shrink_managers": &km.shrinkManagers,
+		"grow_logs":       &km.growLogs,
+		"shrink_logs":     &km.shrinkLogs,
+		"zoom_packages":   &km.zoomPackages,
+		"zoom_logs":       &km.zoomLogs,
+		"layout":          &km.layout,
 	}
 }
 
 type AppModel struct {
-	config       Config
-	keyMap       mainKeyMap
-	w, h         int
-	selectedPkg  string
-	mgrlist      ManagersModel
-	pkglists     map[string]*PackagesModel
-	out          OutputModel
-	pdialog      PasswordModel
-	cdialog      ConfirmModel
+	config      Config
+	keyMap      mainKeyMap
+	w, h        int
+	selectedPkg string
+	mgrlist     ManagersModel
+	pkglists    map[string]*PackagesModel
+	all         AllModel
+	out         OutputModel
+	pdialog     PasswordModel
+	cdialog     ConfirmModel
+	scan        ScanModel
+	keyhelp     KeyHelpModel
+	status      StatusModel
+	layout      LayoutConfig
+	// zoom is the pane shown on the whole screen, empty when none is
+	zoom         string
+	zones        layoutZones
 	prevCmd      tea.Cmd
 	globalKeyMap globalKeyMap
 	help         help.Model
 }
 
 func NewAppModel(config Config) (AppModel, error) {
-	apt := NewPackageModel(config, PACKAGE_MANAGER_APT, ICON_APT, &executors.AptExecutor{})
-	homebrew := NewPackageModel(config, PACKAGE_MANAGER_HOMEBREW, ICON_HOMEBREW, &executors.HomebrewExecutor{})
-	de, err := executors.NewDockerExecutor()
+	var advisories *osv.Database
+	if config.OSVDatabase != "" {
+		db, err := osv.Load(config.OSVDatabase)
+		if err != nil {
+			return AppModel{}, fmt.Errorf("failed to load the OSV database: %w", err)
+		}
+		advisories = db
+	}
+
+	if err := validatePhases(config.phases()); err != nil {
+		return AppModel{}, fmt.Errorf("invalid phases in the config file: %w", err)
+	}
+	if err := validateKeys(config.File.Keys); err != nil {
+		return AppModel{}, fmt.Errorf("invalid keys in the config file: %w", err)
+	}
+	theme, err := config.theme()
 	if err != nil {
 		return AppModel{}, err
 	}
-	docker := NewPackageModel(config, PACKAGE_MANAGER_DOCKER, ICON_DOCKER, de)
-	npm := NewPackageModel(config, PACKAGE_MANAGER_NPM, ICON_NPM, &executors.NpmExecutor{})
-	gem := NewPackageModel(config, PACKAGE_MANAGER_GEM, ICON_GEM, &executors.GemExecutor{})
-
-	baseMgrs := map[string]*PackagesModel{
-		PACKAGE_MANAGER_APT:      &apt,
-		PACKAGE_MANAGER_HOMEBREW: &homebrew,
-		PACKAGE_MANAGER_NPM:      &npm,
-		PACKAGE_MANAGER_GEM:      &gem,
+	// the styles are copied when the models are built
+	applyTheme(theme, noColor())
+	showIcons = !config.NoIcons
+
+	newModel := func(name string) (*PackagesModel, error) {
+		e, err := newExecutor(config, name, advisories)
+		if err != nil {
+			return nil, err
+		}
+		m := NewPackageModel(config, name, managerIcons[name], e)
+		return &m, nil
+	}
+	baseMgrs := map[string]*PackagesModel{}
+	for _, name := range baseManagers {
+		m, err := newModel(name)
+		if err != nil {
+			return AppModel{}, err
+		}
+		baseMgrs[name] = m
 	}
 	if config.Demo {
 		baseMgrs = getDemoMgrs(config)
 	}
-	optionalMgrs := map[string]*PackagesModel{
-		PACKAGE_MANAGER_DOCKER: &docker,
+	optionalMgrs := map[string]*PackagesModel{}
+	for _, name := range optionalManagers {
+		m, err := newModel(name)
+		if err != nil {
+			return AppModel{}, err
+		}
+		optionalMgrs[name] = m
 	}
 	for exclude := range config.Excludes {
 		if _, ok := baseMgrs[exclude]; !ok {
@@ -126,10 +242,11 @@ func NewAppModel(config Config) (AppModel, error) {
 		mgrs = append(mgrs, k)
 	}
 	for k, m := range optionalMgrs {
-		if !m.Valid() {
+		// check enabled ones only not to connect to every container engine
+		if v, ok := config.EnableFeatures[k]; !ok || !v {
 			continue
 		}
-		if v, ok := config.EnableFeatures[k]; !ok || !v {
+		if !m.Valid() {
 			continue
 		}
 		pkglists[k] = m
@@ -142,35 +259,71 @@ func NewAppModel(config Config) (AppModel, error) {
 	sort.Slice(mgrs, func(i, j int) bool {
 		return mgrs[i] < mgrs[j]
 	})
-	mgrlist := NewManagersModel(mgrs, pkglists)
+	mgrlist := NewManagersModel(mgrs, pkglists, config.File.Keys)
 	mgrlist.Focus(true)
+	all := NewAllModel(config, mgrs, pkglists)
 
-	out := NewOutputModel()
+	out := NewOutputModel(config.File.Keys)
+	if config.LogFile != "" {
+		if err := out.GetLogWriter().OpenFile(config.LogFile); err != nil {
+			return AppModel{}, fmt.Errorf("failed to open the log file: %w", err)
+		}
+	}
 	log.SetOutput(out.GetLogWriter())
 
 	pdialog := NewPasswordModel()
 	cdialog := NewConfirmModel()
+	scan := NewScanModel(advisories, pkglists, config.File.Keys)
 
-	km := newMainKeyMap()
+	km := newMainKeyMap(config.File.Keys)
 	globalKeyMap := newGlobalKeyMap(km, mgrlist, out)
+	keyhelp := NewKeyHelpModel(config.File.Keys, km.help)
 	help := help.New()
 
 	return AppModel{
 		config: config,
 		keyMap: km,
 		w:      0, h: 0,
-		selectedPkg:  mgrs[0],
+		selectedPkg:  PACKAGE_MANAGER_ALL,
 		mgrlist:      mgrlist,
 		pkglists:     pkglists,
+		all:          all,
 		out:          out,
 		pdialog:      pdialog,
 		cdialog:      cdialog,
+		scan:         scan,
+		keyhelp:      keyhelp,
+		status:       NewStatusModel(),
+		layout:       config.File.Layout.withDefaults(),
 		prevCmd:      nil,
 		globalKeyMap: globalKeyMap,
 		help:         help,
 	}, nil
 }
 
+// newExecutor returns the executor of the package manager
+func newExecutor(config Config, name string, advisories *osv.Database) (executors.Executor, error) {
+	switch name {
+	case PACKAGE_MANAGER_APT:
+		return &executors.AptExecutor{}, nil
+	case PACKAGE_MANAGER_HOMEBREW:
+		return &executors.HomebrewExecutor{}, nil
+	case PACKAGE_MANAGER_DOCKER:
+		return executors.NewDockerExecutor(config.File.Docker)
+	case PACKAGE_MANAGER_PODMAN:
+		return executors.NewPodmanExecutor(config.File.Docker)
+	case PACKAGE_MANAGER_NERDCTL:
+		return executors.NewNerdctlExecutor(config.File.Docker), nil
+	case PACKAGE_MANAGER_COMPOSE:
+		return executors.NewComposeExecutor(config.File.Docker, config.File.Compose)
+	case PACKAGE_MANAGER_NPM:
+		return &executors.NpmExecutor{Advisories: advisories}, nil
+	case PACKAGE_MANAGER_GEM:
+		return &executors.GemExecutor{Advisories: advisories}, nil
+	}
+	return nil, fmt.Errorf("unknown package manager: %s", name)
+}
+
 func (m AppModel) Init() tea.Cmd {
 	cmds := make([]tea.Cmd, 0, 100)
 
@@ -178,6 +331,7 @@ func (m AppModel) Init() tea.Cmd {
 	for _, pkg := range m.pkglists {
 		cmds = append(cmds, pkg.Init())
 	}
+	cmds = append(cmds, m.all.Init())
 	cmds = append(cmds, m.out.Init())
 	cmds = append(cmds, m.pdialog.Init())
 
@@ -190,10 +344,63 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q":
+		if m.keyhelp.IsShown() {
+			// the help takes every key until it is closed
+			m.keyhelp, cmd = m.keyhelp.Update(msg)
+			return m, cmd
+		}
+		if m.out.IsSearching() {
+			// so does the search of the logs
+			m.out, cmd = m.out.Update(msg)
+			return m, cmd
+		}
+		if m.isTyping() {
+			break
+		}
+		switch {
+		case key.Matches(msg, m.keyMap.quit):
 			return m, tea.Quit
+		case key.Matches(msg, m.keyMap.help):
+			m.keyhelp.Open()
+			return m, nil
+		case key.Matches(msg, m.keyMap.growManagers):
+			m.resize(1, 0)
+			return m, tea.Batch(updateLayoutCmd(), m.saveLayoutCmd())
+		case key.Matches(msg, m.keyMap.shrinkManagers):
+			m.resize(-1, 0)
+			return m, tea.Batch(updateLayoutCmd(), m.saveLayoutCmd())
+		case key.Matches(msg, m.keyMap.growLogs):
+			m.resize(0, 1)
+			return m, tea.Batch(updateLayoutCmd(), m.saveLayoutCmd())
+		case key.Matches(msg, m.keyMap.shrinkLogs):
+			m.resize(0, -1)
+			return m, tea.Batch(updateLayoutCmd(), m.saveLayoutCmd())
+		case key.Matches(msg, m.keyMap.layout):
+			m.layout.Mode = m.layout.nextMode()
+			log.Printf("Layout: %s", m.layout.Mode)
+			return m, tea.Batch(updateLayoutCmd(), m.saveLayoutCmd())
+		case key.Matches(msg, m.keyMap.zoomPackages):
+			m.toggleZoom(zoomPackages)
+			cmds = append(cmds, updateLayoutCmd())
+			// the keys go to the packages shown in place of the managers
+			if m.zoom == zoomPackages && m.mgrlist.IsFocus() {
+				name := m.selectedPkg
+				cmds = append(cmds, func() tea.Msg {
+					return FocusPackagesMsg{Name: name}
+				})
+			}
+			return m, tea.Batch(cmds...)
+		case key.Matches(msg, m.keyMap.zoomLogs):
+			m.toggleZoom(zoomLogs)
+			return m, updateLayoutCmd()
 		}
+	case tea.MouseMsg:
+		if m.keyhelp.IsShown() {
+			m.keyhelp, cmd = m.keyhelp.Update(msg)
+			return m, cmd
+		}
+		// the panes get the click or the wheel as a message of their own
+		return m, m.mouseCmd(msg)
 	case tea.WindowSizeMsg:
 		m.w, m.h = msg.Width, msg.Height
 		m.updateLayout(msg.Width, msg.Height)
@@ -202,13 +409,20 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case ChangeManagerSelectionMsg:
 		m.selectedPkg = msg.Name
 	case FocusManagersMsg:
-		m.mgrlist.Focus(true)
-		for _, pkg := range m.pkglists {
-			pkg.Focus(false)
+		// the managers are hidden while the packages are zoomed
+		if m.zoom == zoomPackages {
+			m.zoom = ""
+			m.updateLayout(m.w, m.h)
 		}
+		m.mgrlist.Focus(true)
+		m.blurPackages()
 		m.globalKeyMap = newGlobalKeyMap(m.keyMap, m.mgrlist, m.out)
 	case FocusPackagesMsg:
 		m.mgrlist.Focus(false)
+		m.all.Focus(msg.Name == PACKAGE_MANAGER_ALL)
+		if msg.Name == PACKAGE_MANAGER_ALL {
+			m.globalKeyMap = newGlobalKeyMap(m.keyMap, m.all, m.out)
+		}
 		for k, pkg := range m.pkglists {
 			if k == msg.Name {
 				pkg.Focus(true)
@@ -220,9 +434,7 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case FocusPasswordDialogMsg:
 		m.storePrevCmd()
 		m.mgrlist.Focus(false)
-		for _, pkg := range m.pkglists {
-			pkg.Focus(false)
-		}
+		m.blurPackages()
 		cmds = append(cmds, m.pdialog.Focus())
 		m.globalKeyMap = newGlobalKeyMap(m.keyMap, m.out)
 	case BlurPasswordDialogMsg:
@@ -232,13 +444,19 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case FocusConfirmDialogMsg:
 		m.storePrevCmd()
 		m.mgrlist.Focus(false)
-		for _, pkg := range m.pkglists {
-			pkg.Focus(false)
-		}
+		m.blurPackages()
 		m.globalKeyMap = newGlobalKeyMap(m.keyMap, m.out)
 	case BlurConfirmDialogMsg:
 		cmds = append(cmds, m.prevCmd)
 		m.prevCmd = nil
+	case FocusScanMsg:
+		m.storePrevCmd()
+		m.mgrlist.Focus(false)
+		m.blurPackages()
+		m.globalKeyMap = newGlobalKeyMap(m.keyMap, m.scan, m.out)
+	case BlurScanMsg:
+		cmds = append(cmds, m.prevCmd)
+		m.prevCmd = nil
 	}
 
 	m.mgrlist, cmd = m.mgrlist.Update(msg)
@@ -250,12 +468,24 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 	}
 
-	m.out, cmd = m.out.Update(msg)
+	m.all, cmd = m.all.Update(msg)
 	cmds = append(cmds, cmd)
 
+	// the keys of the logs would be typed into the filter or the password otherwise
+	if _, ok := msg.(tea.KeyMsg); !ok || !m.isTyping() {
+		m.out, cmd = m.out.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
 	m.cdialog, cmd = m.cdialog.Update(msg)
 	cmds = append(cmds, cmd)
 
+	m.scan, cmd = m.scan.Update(msg)
+	cmds = append(cmds, cmd)
+
+	m.status, cmd = m.status.Update(msg)
+	cmds = append(cmds, cmd)
+
 	m.pdialog, cmd = m.pdialog.Update(msg)
 	cmds = append(cmds, cmd)
 
@@ -265,73 +495,66 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
-func (m AppModel) View() string {
-	left := docStyleRightBorder.Render(m.mgrlist.View())
-
-	rightTop := docStyle.Render("")
-	if pkg, ok := m.pkglists[m.selectedPkg]; ok {
-		rightTop = docStyle.Render(pkg.View())
+// isTyping reports whether a filter or the password is being typed, which takes the keys
+func (m AppModel) isTyping() bool {
+	if x, _ := m.pdialog.GetSize(); x > 0 {
+		return true
 	}
-	rightBottom := borderStyle.Render(m.out.View())
+	return m.isFiltering()
+}
 
-	dialog := ""
-	if x, _ := m.cdialog.GetSize(); x > 0 {
-		dialog = m.cdialog.View()
+// isFiltering reports whether a filter of the lists is being typed
+func (m AppModel) isFiltering() bool {
+	if m.mgrlist.IsFocus() && m.mgrlist.IsFiltering() {
+		return true
 	}
-	if x, _ := m.pdialog.GetSize(); x > 0 {
-		dialog = m.pdialog.View()
+	if m.all.IsFocus() && m.all.IsFiltering() {
+		return true
 	}
+	for _, pkg := range m.pkglists {
+		if pkg.IsFocus() && pkg.IsFiltering() {
+			return true
+		}
+	}
+	return false
+}
 
-	right := lipgloss.JoinVertical(
-		lipgloss.Left,
-		rightTop,
-		dialog,
-		rightBottom,
-	)
-
-	layout := lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		left,
-		right,
-	)
+func (m AppModel) View() string {
+	if m.keyhelp.IsShown() {
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			docStyle.Render(m.keyhelp.View()),
+			helpStyle.Render(m.help.View(m.keyhelp)),
+		)
+	}
 
-	layoutWithHelp := lipgloss.JoinVertical(
-		lipgloss.Left,
-		layout,
-		helpStyle.Render(m.help.View(m.globalKeyMap)),
-	)
+	var keys help.KeyMap = m.globalKeyMap
+	if m.out.IsSearching() {
+		keys = m.out
+	}
+	rows := []string{m.layoutView()}
+	if status := m.status.View(); status != "" {
+		rows = append(rows, status)
+	}
+	rows = append(rows, helpStyle.Render(m.help.View(keys)))
 
-	return layoutWithHelp
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
 }
 
 func (m *AppModel) Close() {
 	for _, pkg := range m.pkglists {
 		pkg.executor.Close()
 	}
-}
-
-func (m *AppModel) updateLayout(w, h int) {
-	leftWidth := int(float64(w) * 0.4)
-	rightWidth := w - leftWidth
-	rightHeight := h / 2
-	if h%2 == 0 {
-		rightHeight -= 1
-	} else {
-		rightHeight -= 2
+	if err := m.out.GetLogWriter().Close(); err != nil {
+		fmt.Printf("Error closing the log file: %v\n", err)
 	}
+}
 
-	dfw, dfh := docStyle.GetFrameSize()
-	bfw, bfh := borderStyle.GetFrameSize()
-	_, pdh := m.pdialog.GetSize()
-	_, cdh := m.cdialog.GetSize()
-
-	m.mgrlist.SetSize(leftWidth-dfw, h-dfh)
-
-	for _, l := range m.pkglists {
-		l.SetSize(rightWidth-dfw, rightHeight-dfh-pdh-cdh)
+func (m *AppModel) blurPackages() {
+	m.all.Focus(false)
+	for _, pkg := range m.pkglists {
+		pkg.Focus(false)
 	}
-
-	m.out.SetSize(rightWidth-bfw, rightHeight-bfh)
 }
 
 func (m *AppModel) storePrevCmd() {
@@ -342,6 +565,14 @@ func (m *AppModel) storePrevCmd() {
 		}
 		return
 	}
+	if m.all.IsFocus() {
+		m.prevCmd = func() tea.Msg {
+			return FocusPackagesMsg{
+				Name: PACKAGE_MANAGER_ALL,
+			}
+		}
+		return
+	}
 	for k, pkg := range m.pkglists {
 		if pkg.IsFocus() {
 			m.prevCmd = func() tea.Msg {
@@ -361,6 +592,8 @@ func getDemoMgrs(config Config) map[string]*PackagesModel {
 				Name:       "curl",
 				OldVersion: "7.68.0",
 				NewVersion: "7.85.0",
+				Origin:     "jammy-updates,jammy-security",
+				Security:   true,
 			},
 			{
 				Name:       "git",
@@ -411,6 +644,7 @@ func getDemoMgrs(config Config) map[string]*PackagesModel {
 				Name:       "express",
 				OldVersion: "4.17.1",
 				NewVersion: "4.18.2",
+				Security:   true,
 			},
 			{
 				Name:       "lodash",
@@ -433,8 +667,11 @@ type globalKeyMap struct {
 }
 
 func newGlobalKeyMap(km mainKeyMap, kms ...help.KeyMap) globalKeyMap {
-	short := []key.Binding{km.quit}
-	full := [][]key.Binding{{km.quit}}
+	short := []key.Binding{km.quit, km.help}
+	full := [][]key.Binding{
+		{km.quit, km.help},
+		{km.growManagers, km.shrinkManagers, km.growLogs, km.shrinkLogs, km.zoomPackages, km.zoomLogs, km.layout},
+	}
 
 	for _, km := range kms {
 		short = append(short, km.ShortHelp()...)
@@ -448,7 +685,6 @@ func (m globalKeyMap) ShortHelp() []key.Binding {
 	return m.shortHelp
 }
 
-// TODO: currently not implemented properly
 func (m globalKeyMap) FullHelp() [][]key.Binding {
 	return m.fullHelp
 }
@@ -0,0 +1,137 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ymtdzzz/lazypkg/executors"
+)
+
+type changelogFetchedMsg struct {
+	name    string
+	pkg     string
+	content string
+	err     error
+}
+
+type changelogKeyMap struct {
+	Close key.Binding
+	Up    key.Binding
+	Down  key.Binding
+}
+
+func newChangelogKeyMap(keys KeyConfig) changelogKeyMap {
+	km := changelogKeyMap{
+		Close: key.NewBinding(
+			key.WithKeys("esc", "c"),
+			key.WithHelp("esc | c", "close changelog"),
+		),
+		Up: key.NewBinding(
+			key.WithKeys("up", "k", "pgup"),
+			key.WithHelp("↑ | k | pgup", "scroll up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j", "pgdown"),
+			key.WithHelp("↓ | j | pgdown", "scroll down"),
+		),
+	}
+	remapKeys(keys, keyPaneChangelog, km.bindings())
+	return km
+}
+
+func (km *changelogKeyMap) bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"close": &km.Close,
+		"up":    &km.Up,
+		"down":  &km.Down,
+	}
+}
+
+type ChangelogModel struct {
+	keyMap   changelogKeyMap
+	viewport viewport.Model
+	show     bool
+	pkg      string
+}
+
+func NewChangelogModel(keys KeyConfig) ChangelogModel {
+	vp := viewport.New(0, 0)
+	vp.KeyMap = viewport.KeyMap{}
+
+	return ChangelogModel{
+		keyMap:   newChangelogKeyMap(keys),
+		viewport: vp,
+	}
+}
+
+func (m ChangelogModel) Update(msg tea.Msg) (ChangelogModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case changelogFetchedMsg:
+		if msg.pkg != m.pkg {
+			break
+		}
+		if msg.err != nil {
+			m.viewport.SetContent(fmt.Sprintf("Failed to load the changelog: %v", msg.err))
+		} else {
+			m.viewport.SetContent(lipgloss.NewStyle().Width(m.viewport.Width).Render(msg.content))
+		}
+		m.viewport.GotoTop()
+	case tea.KeyMsg:
+		if !m.show {
+			break
+		}
+		switch {
+		case key.Matches(msg, m.keyMap.Close):
+			m.show = false
+			m.pkg = ""
+		case msg.String() == "pgup":
+			m.viewport.HalfPageUp()
+		case msg.String() == "pgdown":
+			m.viewport.HalfPageDown()
+		case key.Matches(msg, m.keyMap.Up):
+			m.viewport.ScrollUp(1)
+		case key.Matches(msg, m.keyMap.Down):
+			m.viewport.ScrollDown(1)
+		}
+	}
+
+	return m, nil
+}
+
+func (m ChangelogModel) View() string {
+	title := titleStyle.Render(fmt.Sprintf("Changelog [%s]", m.pkg))
+	return lipgloss.JoinVertical(lipgloss.Left, title, "", m.viewport.View())
+}
+
+func (m ChangelogModel) IsShown() bool {
+	return m.show
+}
+
+func (m ChangelogModel) ShortHelp() []key.Binding {
+	return []key.Binding{m.keyMap.Up, m.keyMap.Down, m.keyMap.Close}
+}
+
+func (m ChangelogModel) FullHelp() [][]key.Binding {
+	return [][]key.Binding{m.ShortHelp()}
+}
+
+func (m *ChangelogModel) SetSize(w, h int) {
+	// title and an empty line
+	m.viewport.Width = w
+	m.viewport.Height = max(h-2, 0)
+}
+
+// Open shows the viewer and returns the command fetching the changelog of the package
+func (m *ChangelogModel) Open(name string, fetcher executors.ChangelogFetcher, pkg *executors.PackageInfo) tea.Cmd {
+	m.show = true
+	m.pkg = pkg.Name
+	m.viewport.SetContent(fmt.Sprintf("Loading changelog of %s (%s -> %s)...", pkg.Name, displayVersion(pkg.OldVersion), displayVersion(pkg.NewVersion)))
+
+	return func() tea.Msg {
+		content, err := fetcher.GetChangelog(pkg)
+		return changelogFetchedMsg{name: name, pkg: pkg.Name, content: content, err: err}
+	}
+}
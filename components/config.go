@@ -1,18 +1,185 @@
 package components
 
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ymtdzzz/lazypkg/executors"
+	"gopkg.in/yaml.v3"
+)
+
 type Config struct {
 	DryRun         bool
 	Excludes       map[string]bool
 	EnableFeatures map[string]bool
 	Demo           bool
+	OSVDatabase    string
+	// Theme is the theme given with --theme, which takes precedence over the config file
+	Theme string
+	// NoIcons replaces the Nerd Font icons with ASCII labels
+	NoIcons bool
+	// LogFile is the file every log is appended to, given with --log-file
+	LogFile string
+	// Path is the location of the config file, empty when no file is used
+	Path string
+	File FileConfig
+}
+
+// FileConfig is the content of the config file
+type FileConfig struct {
+	Docker  executors.DockerConfig  `yaml:"docker"`
+	Compose executors.ComposeConfig `yaml:"compose"`
+	// Lists are the settings of the package lists by package manager
+	Lists map[string]ListConfig `yaml:"lists"`
+	// Phases order the package managers when running update plans
+	Phases []PhaseConfig `yaml:"phases"`
+	// Plans are the saved update plans by name
+	Plans map[string]Plan `yaml:"plans"`
+	// Keys remap the key bindings
+	Keys KeyConfig `yaml:"keys"`
+	// Theme is the name of a built-in or user theme
+	Theme string `yaml:"theme"`
+	// Themes are the user themes by name
+	Themes map[string]Theme `yaml:"themes"`
+	// Layout is the arrangement of the panes
+	Layout LayoutConfig `yaml:"layout"`
+}
+
+// ListConfig is the settings of a package list changed in the TUI
+type ListConfig struct {
+	// Sort is the sort mode such as name or severity
+	Sort string `yaml:"sort,omitempty"`
+	// Group lists the packages under a header per group. It is enabled when unset.
+	Group *bool `yaml:"group,omitempty"`
 }
 
-func NewConfig(dryRun bool, excludes []string, enables []string, demo bool) Config {
+func NewConfig(dryRun bool, excludes []string, enables []string, demo bool, osvDatabase string) Config {
 	return Config{
 		DryRun:         dryRun,
 		Excludes:       getBoolMapFromArray(excludes),
 		EnableFeatures: getBoolMapFromArray(enables),
 		Demo:           demo,
+		OSVDatabase:    osvDatabase,
+	}
+}
+
+// DefaultConfigPath returns the path of the config file in the user config directory
+func DefaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "lazypkg", "config.yaml")
+}
+
+// LoadFile reads the config file at path. A missing file is not an error.
+func (c *Config) LoadFile(path string) error {
+	c.Path = path
+	if path == "" {
+		return nil
+	}
+
+	b, err := os.ReadFile(path) // #nosec G304: path is given by the user
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(b, &c.File); err != nil {
+		return fmt.Errorf("failed to parse the config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// configFileMu serializes the writes to the config file
+var configFileMu sync.Mutex
+
+// saveListConfig stores the settings of the package list in the config file
+func saveListConfig(path, name string, lc ListConfig) error {
+	return saveConfigEntry(path, "lists", name, lc)
+}
+
+// saveConfigEntry stores the value as the entry of the section in the config file, removing the entry when the value is nil.
+// The value replaces the whole section when name is empty.
+// Only the entry is replaced so that the rest of the file keeps its comments and quoting,
+// and its indentation as long as it is indented by 2 spaces.
+func saveConfigEntry(path, section, name string, v any) error {
+	if path == "" {
+		return nil
+	}
+	configFileMu.Lock()
+	defer configFileMu.Unlock()
+
+	var doc yaml.Node
+	b, err := os.ReadFile(path) // #nosec G304: path is given by the user
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return fmt.Errorf("failed to parse the config file %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	parent, key := yamlMappingEntry(doc.Content[0], section), name
+	if name == "" {
+		parent, key = doc.Content[0], section
+	}
+	if v == nil {
+		yamlDeleteEntry(parent, key)
+	} else {
+		var value yaml.Node
+		if err := value.Encode(v); err != nil {
+			return err
+		}
+		*yamlMappingEntry(parent, key) = value
+	}
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(path, out.Bytes(), 0o600)
+}
+
+// yamlMappingEntry returns the value of the key of the mapping node, adding an empty mapping when it does not exist
+func yamlMappingEntry(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			if node.Content[i+1].Kind != yaml.MappingNode {
+				node.Content[i+1] = &yaml.Node{Kind: yaml.MappingNode}
+			}
+			return node.Content[i+1]
+		}
+	}
+	value := &yaml.Node{Kind: yaml.MappingNode}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	return value
+}
+
+// yamlDeleteEntry removes the key from the mapping node
+func yamlDeleteEntry(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
 	}
 }
 
@@ -1,9 +1,12 @@
 package components
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/ymtdzzz/lazypkg/executors"
 )
 
 func TestNewConfig(t *testing.T) {
@@ -12,6 +15,7 @@ func TestNewConfig(t *testing.T) {
 		excludes []string
 		enables  []string
 		demo     bool
+		osvDB    string
 	}
 
 	tests := []struct {
@@ -19,7 +23,7 @@ func TestNewConfig(t *testing.T) {
 		want  Config
 	}{
 		{
-			input: input{false, []string{}, []string{}, false},
+			input: input{false, []string{}, []string{}, false, ""},
 			want: Config{
 				DryRun:         false,
 				Excludes:       map[string]bool{},
@@ -27,7 +31,7 @@ func TestNewConfig(t *testing.T) {
 			},
 		},
 		{
-			input: input{false, []string{"hoge", "fuga"}, []string{"piyo"}, false},
+			input: input{false, []string{"hoge", "fuga"}, []string{"piyo"}, false, "/tmp/osv"},
 			want: Config{
 				DryRun: false,
 				Excludes: map[string]bool{
@@ -37,12 +41,93 @@ func TestNewConfig(t *testing.T) {
 				EnableFeatures: map[string]bool{
 					"piyo": true,
 				},
+				OSVDatabase: "/tmp/osv",
 			},
 		},
 	}
 
 	for _, tt := range tests {
-		got := NewConfig(tt.input.dryRun, tt.input.excludes, tt.input.enables, tt.input.demo)
+		got := NewConfig(tt.input.dryRun, tt.input.excludes, tt.input.enables, tt.input.demo, tt.input.osvDB)
 		assert.Equal(t, tt.want, got)
 	}
 }
+
+func TestConfigLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	err := os.WriteFile(path, []byte(`docker:
+  include:
+    - postgres
+  exclude:
+    - "*:*-rc*"
+  registries:
+    - host: ghcr.io
+      username: octocat
+      password_env: GHCR_TOKEN
+`), 0o600)
+	assert.Nil(t, err)
+
+	config := NewConfig(false, nil, nil, false, "")
+	assert.Nil(t, config.LoadFile(path))
+	assert.Equal(t, path, config.Path)
+	assert.Equal(t, executors.DockerConfig{
+		Include: []string{"postgres"},
+		Exclude: []string{"*:*-rc*"},
+		Registries: []executors.RegistryCredential{
+			{Host: "ghcr.io", Username: "octocat", PasswordEnv: "GHCR_TOKEN"},
+		},
+	}, config.File.Docker)
+
+	missing := NewConfig(false, nil, nil, false, "")
+	assert.Nil(t, missing.LoadFile(filepath.Join(dir, "missing.yaml")))
+	assert.Equal(t, FileConfig{}, missing.File)
+}
+
+func TestSaveListConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lazypkg", "config.yaml")
+	group := false
+
+	assert.Nil(t, saveListConfig(path, "apt", ListConfig{Sort: "severity"}))
+	b, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "lists:\n  apt:\n    sort: severity\n", string(b))
+
+	err = os.WriteFile(path, []byte(`# images to check
+docker:
+  include:
+    - postgres
+lists:
+  apt:
+    sort: severity
+`), 0o600)
+	assert.Nil(t, err)
+	assert.Nil(t, saveListConfig(path, "npm", ListConfig{Sort: "size", Group: &group}))
+	assert.Nil(t, saveListConfig(path, "apt", ListConfig{Sort: "name"}))
+
+	config := NewConfig(false, nil, nil, false, "")
+	assert.Nil(t, config.LoadFile(path))
+	assert.Equal(t, map[string]ListConfig{
+		"apt": {Sort: "name"},
+		"npm": {Sort: "size", Group: &group},
+	}, config.File.Lists)
+	assert.Equal(t, []string{"postgres"}, config.File.Docker.Include)
+	b, err = os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), "# images to check")
+
+	// the rest of a hand-written file is kept as it is
+	written := `# lazypkg config
+excludes:
+  gem: true # no ruby here
+docker:
+  include:
+    - "postgres"
+    - 'redis'
+  exclude: [busybox]
+`
+	assert.Nil(t, os.WriteFile(path, []byte(written), 0o600))
+	assert.Nil(t, saveListConfig(path, "apt", ListConfig{Sort: "size"}))
+	b, err = os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, written+"lists:\n  apt:\n    sort: size\n", string(b))
+}
@@ -1,17 +1,38 @@
 package components
 
 import (
+	"fmt"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/ymtdzzz/lazypkg/executors"
+)
+
+const (
+	planMaxEntries = 5
+	// planMinPartLength is the length the name and the old version of a plan entry are shortened to at most
+	planMinPartLength = 8
+	// errorOutputLines are the last lines of the error output shown in the dialog
+	errorOutputLines = 10
+
+	confirmOKButton     = "[Enter] OK"
+	confirmCancelButton = "[Esc] Cancel"
+)
+
+var (
+	planDetailStyle = lipgloss.NewStyle().Align(lipgloss.Left)
 )
 
 type ConfirmModel struct {
 	msg      string
+	detail   string
 	maxlen   int
 	show     bool
 	callback tea.Cmd
+	info     bool
+	// queue are the info dialogs waiting for the shown dialog to be closed
+	queue []showDialogMsg
 }
 
 func NewConfirmModel() ConfirmModel {
@@ -30,51 +51,241 @@ func (m ConfirmModel) Update(msg tea.Msg) (ConfirmModel, tea.Cmd) {
 		case tea.KeyMsg:
 			switch msg.String() {
 			case "esc":
-				m.show = false
-				m.callback = nil
-				cmds = append(cmds, func() tea.Msg {
-					return BlurConfirmDialogMsg{}
-				}, updateLayoutCmd())
+				cmds = append(cmds, m.cancel()...)
 			case "enter":
-				m.show = false
-				cmds = append(cmds, func() tea.Msg {
-					return BlurConfirmDialogMsg{}
-				}, m.callback, updateLayoutCmd())
-				m.callback = nil
+				cmds = append(cmds, m.ok()...)
+			}
+		case clickMsg:
+			if msg.pane != paneDialog {
+				break
+			}
+			switch m.buttonAt(msg.x, msg.y) {
+			case confirmOKButton:
+				cmds = append(cmds, m.ok()...)
+			case confirmCancelButton:
+				cmds = append(cmds, m.cancel()...)
 			}
 		}
 	}
 
 	switch msg := msg.(type) {
 	case showDialogMsg:
-		m.msg, m.maxlen = wrapText(msg.msg, DIALOG_MAX_LINE_LENGTH)
-		m.callback = msg.callback
-		m.show = true
-		cmds = append(cmds, func() tea.Msg {
-			return FocusConfirmDialogMsg{}
-		}, updateLayoutCmd())
+		switch {
+		case m.show && msg.info:
+			m.queue = append(m.queue, msg)
+		case m.show && m.info:
+			// the confirmation comes first as it is waited for, the info is shown after it
+			m.queue = append([]showDialogMsg{m.current()}, m.queue...)
+			m.set(msg)
+			cmds = append(cmds, updateLayoutCmd())
+		default:
+			m.set(msg)
+			cmds = append(cmds, func() tea.Msg {
+				return FocusConfirmDialogMsg{}
+			}, updateLayoutCmd())
+		}
 	}
 
 	return m, tea.Batch(cmds...)
 }
 
+func (m *ConfirmModel) set(msg showDialogMsg) {
+	m.msg, m.maxlen = wrapText(msg.msg, DIALOG_MAX_LINE_LENGTH)
+	m.detail = msg.detail
+	m.callback = msg.callback
+	m.info = msg.info
+	m.show = true
+}
+
+// current returns the shown dialog as the message showing it
+func (m ConfirmModel) current() showDialogMsg {
+	return showDialogMsg{msg: m.msg, detail: m.detail, callback: m.callback, info: m.info}
+}
+
+// close hides the dialog, or shows the next queued one keeping the focus on the dialogs
+func (m *ConfirmModel) close() []tea.Cmd {
+	m.callback = nil
+	if len(m.queue) > 0 {
+		m.set(m.queue[0])
+		m.queue = m.queue[1:]
+		return []tea.Cmd{updateLayoutCmd()}
+	}
+	m.show = false
+	return []tea.Cmd{func() tea.Msg {
+		return BlurConfirmDialogMsg{}
+	}, updateLayoutCmd()}
+}
+
 func (m ConfirmModel) View() string {
 	if !m.show {
 		return ""
 	}
 
-	dialog := lipgloss.JoinVertical(lipgloss.Center,
-		m.msg,
-		"\n[Enter] OK  [Esc] Cancel",
-	)
+	rows := []string{m.msg}
+	if m.detail != "" {
+		rows = append(rows, "", planDetailStyle.Render(m.detail))
+	}
+	buttons := confirmOKButton + "  " + confirmCancelButton
+	if m.info {
+		buttons = confirmOKButton
+	}
+	rows = append(rows, "\n"+buttons)
+	dialog := lipgloss.JoinVertical(lipgloss.Center, rows...)
 
 	return dialogStyle.Render(dialog)
 }
 
+func (m *ConfirmModel) ok() []tea.Cmd {
+	callback := m.callback
+	return append(m.close(), callback)
+}
+
+func (m *ConfirmModel) cancel() []tea.Cmd {
+	return m.close()
+}
+
+// buttonAt returns the button at the position in the dialog, empty when there is none
+func (m ConfirmModel) buttonAt(x, y int) string {
+	lines := strings.Split(m.View(), "\n")
+	if y < 0 || y >= len(lines) {
+		return ""
+	}
+	// the buttons are centered, so they are found in the rendered row
+	line := lines[y]
+	for _, b := range []string{confirmOKButton, confirmCancelButton} {
+		i := strings.Index(line, b)
+		if i < 0 {
+			continue
+		}
+		start := lipgloss.Width(line[:i])
+		if x >= start && x < start+lipgloss.Width(b) {
+			return b
+		}
+	}
+	return ""
+}
+
 func (m ConfirmModel) GetSize() (x, y int) {
 	if !m.show {
 		return 0, 0
 	}
 	fw, fh := dialogStyle.GetFrameSize()
-	return DIALOG_WIDTH + fw, strings.Count(m.msg, "\n") + 3 + fh // 2 = line count + new line + button row
+	h := strings.Count(m.msg, "\n") + 3 + fh // 2 = line count + new line + button row
+	if m.detail != "" {
+		h += strings.Count(m.detail, "\n") + 2 // empty line + line count
+	}
+	return DIALOG_WIDTH + fw, h
+}
+
+// renderPlan describes the changes of the update plan in lines fitting in the dialog
+func renderPlan(plan *executors.UpdatePlan) string {
+	var rows []string
+	section := func(title string, entries []executors.PlanEntry, style lipgloss.Style) {
+		if len(entries) == 0 {
+			return
+		}
+		rows = append(rows, style.Render(fmt.Sprintf("%s (%d):", title, len(entries))))
+		for i, e := range entries {
+			if i == planMaxEntries {
+				rows = append(rows, style.Render(fmt.Sprintf("  ... and %d more", len(entries)-planMaxEntries)))
+				break
+			}
+			rows = append(rows, style.Render(planLine(e, DIALOG_MAX_LINE_LENGTH)))
+		}
+	}
+	section("Upgrade", plan.Upgrades, lipgloss.NewStyle())
+	section("Newly installed", plan.Installs, lipgloss.NewStyle())
+	section("REMOVED", plan.Removals, planRemovalStyle)
+	if plan.DownloadSize > 0 {
+		rows = append(rows, "Download size: "+formatBytes(plan.DownloadSize))
+	}
+	if len(rows) == 0 {
+		return "The simulation reported no changes"
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+// planLine describes a plan entry in a line of at most maxLen characters.
+// The old version and then the name are shortened first, so that the version the package ends up with stays readable.
+func planLine(e executors.PlanEntry, maxLen int) string {
+	name, oldVersion, version := e.Name, e.OldVersion, e.NewVersion
+	if version == "" {
+		version, oldVersion = oldVersion, ""
+	}
+	format := func() string {
+		line := "  " + name
+		if oldVersion != "" {
+			line += fmt.Sprintf(" %s ->", oldVersion)
+		}
+		if version != "" {
+			line += " " + version
+		}
+		return line
+	}
+
+	// shorten cuts the part down by what the line is too long, but not below planMinPartLength
+	shorten := func(part string, cut func(string, int) string) string {
+		over := len([]rune(format())) - maxLen
+		length := len([]rune(part))
+		if over <= 0 || length <= planMinPartLength {
+			return part
+		}
+		return cut(part, max(length-over, planMinPartLength))
+	}
+	// versions differ at their end, so the old version keeps it
+	oldVersion = shorten(oldVersion, truncateLeft)
+	name = shorten(name, truncate)
+
+	return truncate(format(), maxLen)
+}
+
+// renderContainers lists the containers to recreate in lines fitting in the dialog
+func renderContainers(containers []executors.Container) string {
+	var rows []string
+	for i, c := range containers {
+		if i == planMaxEntries {
+			rows = append(rows, fmt.Sprintf("... and %d more", len(containers)-planMaxEntries))
+			break
+		}
+		line := c.Name
+		if c.ComposeProject != "" {
+			line += fmt.Sprintf(" (compose: %s/%s)", c.ComposeProject, c.ComposeService)
+		}
+		rows = append(rows, truncate(line, DIALOG_MAX_LINE_LENGTH))
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+// renderErrorOutput shows the last lines of the error in lines fitting in the dialog
+func renderErrorOutput(err error) string {
+	lines := strings.Split(strings.TrimSpace(err.Error()), "\n")
+	var rows []string
+	if len(lines) > errorOutputLines {
+		rows = append(rows, fmt.Sprintf("... %d earlier lines are in the logs", len(lines)-errorOutputLines))
+		lines = lines[len(lines)-errorOutputLines:]
+	}
+	for _, line := range lines {
+		rows = append(rows, truncate(line, DIALOG_MAX_LINE_LENGTH))
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+func truncate(s string, maxLen int) string {
+	r := []rune(s)
+	if len(r) <= maxLen {
+		return s
+	}
+	return string(r[:maxLen-1]) + "…"
+}
+
+// truncateLeft is truncate keeping the end of s
+func truncateLeft(s string, maxLen int) string {
+	r := []rune(s)
+	if len(r) <= maxLen {
+		return s
+	}
+	return "…" + string(r[len(r)-maxLen+1:])
 }
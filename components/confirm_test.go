@@ -5,6 +5,8 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/x/exp/teatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/ymtdzzz/lazypkg/executors"
 )
 
 func TestConfirmOutput(t *testing.T) {
@@ -78,3 +80,125 @@ func TestConfirmOutput(t *testing.T) {
 		tm.WaitFinished(t)
 	})
 }
+
+func TestRenderPlan(t *testing.T) {
+	tests := []struct {
+		name string
+		plan *executors.UpdatePlan
+		want string
+	}{
+		{
+			name: "no changes",
+			plan: &executors.UpdatePlan{},
+			want: "The simulation reported no changes",
+		},
+		{
+			name: "upgrades, installs and removals",
+			plan: &executors.UpdatePlan{
+				Upgrades: []executors.PlanEntry{
+					{Name: "libssl3t64", OldVersion: "3.0.13-0ubuntu3.4", NewVersion: "3.0.13-0ubuntu3.5"},
+				},
+				Installs: []executors.PlanEntry{
+					{Name: "libnewdep1", NewVersion: "1.0-1"},
+				},
+				Removals: []executors.PlanEntry{
+					{Name: "liboldthing0", OldVersion: "1.2-1"},
+				},
+				DownloadSize: 3460300,
+			},
+			want: `Upgrade (1):
+  libssl3t64 …0.13-0ubuntu3.4 -> 3.0.13-0ubuntu3.5
+Newly installed (1):
+  libnewdep1 1.0-1
+REMOVED (1):
+  liboldthing0 1.2-1
+Download size: 3.3 MiB`,
+		},
+		{
+			name: "long lines keep the version the package ends up with",
+			plan: &executors.UpdatePlan{
+				Upgrades: []executors.PlanEntry{
+					{Name: "linux-modules-extra-6.8.0-45-generic", OldVersion: "6.8.0-45.45~22.04.1", NewVersion: "6.8.0-49.49~22.04.1"},
+				},
+				Installs: []executors.PlanEntry{
+					{Name: "a-very-long-package-name-that-does-not-fit-in-dialog", NewVersion: "1.2.3-1"},
+				},
+				Removals: []executors.PlanEntry{
+					{Name: "pkg", OldVersion: "0.0.0+git20240101.0123456789abcdef0123456789abcdef"},
+				},
+			},
+			want: `Upgrade (1):
+  linux-modules-e… …22.04.1 -> 6.8.0-49.49~22.04.1
+Newly installed (1):
+  a-very-long-package-name-that-does-not-… 1.2.3-1
+REMOVED (1):
+  pkg 0.0.0+git20240101.0123456789abcdef012345678…`,
+		},
+		{
+			name: "long sections are truncated",
+			plan: &executors.UpdatePlan{
+				Upgrades: []executors.PlanEntry{
+					{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}, {Name: "e"}, {Name: "f"}, {Name: "g"},
+				},
+			},
+			want: `Upgrade (7):
+  a
+  b
+  c
+  d
+  e
+  ... and 2 more`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, renderPlan(tt.plan))
+		})
+	}
+}
+
+func TestRenderContainers(t *testing.T) {
+	got := renderContainers([]executors.Container{
+		{Name: "cache"},
+		{Name: "app-redis-1", ComposeProject: "app", ComposeService: "redis"},
+	})
+	assert.Equal(t, "cache\napp-redis-1 (compose: app/redis)", got)
+}
+
+func TestConfirmQueue(t *testing.T) {
+	m := NewConfirmModel()
+	show := func(msg tea.Msg) {
+		m, _ = m.Update(msg)
+	}
+	press := func(k tea.KeyType) tea.Cmd {
+		var cmd tea.Cmd
+		m, cmd = m.Update(tea.KeyMsg{Type: k})
+		return cmd
+	}
+
+	show(showInfoCmd("first summary", "apt: 2 updated")())
+	assert.True(t, m.info)
+	assert.Contains(t, m.View(), confirmOKButton)
+	assert.NotContains(t, m.View(), confirmCancelButton)
+
+	// a confirmation is shown before the info, which is shown again after it
+	show(showDialogCmd("Package curl will be updated", func() tea.Msg {
+		return callbackMsg{}
+	})())
+	show(showInfoCmd("second summary", "")())
+	assert.False(t, m.info)
+	assert.Contains(t, m.View(), confirmCancelButton)
+	assert.Len(t, m.queue, 2)
+
+	press(tea.KeyEnter)
+	assert.True(t, m.show)
+	assert.Contains(t, m.View(), "first summary")
+
+	press(tea.KeyEsc)
+	assert.True(t, m.show)
+	assert.Contains(t, m.View(), "second summary")
+
+	press(tea.KeyEnter)
+	assert.False(t, m.show)
+	assert.Empty(t, m.queue)
+}
@@ -0,0 +1,157 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ymtdzzz/lazypkg/executors"
+)
+
+const detailFetchDelay = 300 * time.Millisecond
+
+var (
+	detailStyle = lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder(), false, false, false, true).
+		PaddingLeft(1)
+)
+
+type detailRequestMsg struct {
+	name string
+	pkg  string
+	seq  int
+}
+
+type detailFetchedMsg struct {
+	name   string
+	pkg    string
+	detail *executors.PackageDetail
+	err    error
+}
+
+// detailCache holds package details fetched so far.
+// It is shared between copies of PackagesModel.
+type detailCache struct {
+	seq     int
+	details map[string]*executors.PackageDetail
+	errors  map[string]error
+	pending map[string]bool
+}
+
+func newDetailCache() *detailCache {
+	return &detailCache{
+		details: map[string]*executors.PackageDetail{},
+		errors:  map[string]error{},
+		pending: map[string]bool{},
+	}
+}
+
+// request schedules a fetch of the detail after a short delay so that moving
+// the cursor quickly does not spawn a process for every row passed through
+func (c *detailCache) request(name, pkg string) tea.Cmd {
+	c.seq++
+	if _, ok := c.details[pkg]; ok {
+		return nil
+	}
+	seq := c.seq
+	return tea.Tick(detailFetchDelay, func(time.Time) tea.Msg {
+		return detailRequestMsg{name: name, pkg: pkg, seq: seq}
+	})
+}
+
+func (c *detailCache) fetch(msg detailRequestMsg, fetcher executors.DetailFetcher) tea.Cmd {
+	if msg.seq != c.seq || c.pending[msg.pkg] {
+		return nil
+	}
+	if _, ok := c.details[msg.pkg]; ok {
+		return nil
+	}
+	c.pending[msg.pkg] = true
+	delete(c.errors, msg.pkg)
+	return func() tea.Msg {
+		detail, err := fetcher.GetDetail(msg.pkg)
+		return detailFetchedMsg{name: msg.name, pkg: msg.pkg, detail: detail, err: err}
+	}
+}
+
+func (c *detailCache) store(msg detailFetchedMsg) {
+	delete(c.pending, msg.pkg)
+	if msg.err != nil {
+		c.errors[msg.pkg] = msg.err
+		return
+	}
+	c.details[msg.pkg] = msg.detail
+}
+
+func (c *detailCache) clear() {
+	for k := range c.details {
+		delete(c.details, k)
+	}
+	for k := range c.errors {
+		delete(c.errors, k)
+	}
+}
+
+func (c *detailCache) view(pkg string, w, h int) string {
+	var content string
+	switch {
+	case pkg == "":
+		content = "No package selected"
+	case c.pending[pkg]:
+		content = "Loading details..."
+	case c.errors[pkg] != nil:
+		content = fmt.Sprintf("Failed to load details: %v", c.errors[pkg])
+	case c.details[pkg] != nil:
+		content = renderDetail(c.details[pkg])
+	default:
+		content = "Loading details..."
+	}
+
+	fw, _ := detailStyle.GetFrameSize()
+	content = lipgloss.NewStyle().Width(max(w-fw, 0)).Render(pkg + "\n\n" + content)
+
+	return detailStyle.Height(h).MaxHeight(h).Render(content)
+}
+
+func renderDetail(d *executors.PackageDetail) string {
+	rows := []string{}
+	if d.Description != "" {
+		rows = append(rows, d.Description, "")
+	}
+	for _, f := range []struct {
+		label string
+		value string
+	}{
+		{"Homepage", d.Homepage},
+		{"Repository", d.Repository},
+		{"License", d.License},
+		{"Download", formatBytes(d.DownloadSize)},
+		{"Installed", formatBytes(d.InstalledSize)},
+	} {
+		if f.value == "" {
+			continue
+		}
+		rows = append(rows, detailLabelStyle.Render(fmt.Sprintf("%-11s", f.label))+f.value)
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+func formatBytes(size int64) string {
+	if size <= 0 {
+		return ""
+	}
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
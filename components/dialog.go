@@ -51,6 +51,16 @@ func updateLayoutCmd() tea.Cmd {
 	}
 }
 
+func showDialogWithDetailCmd(msg, detail string, callback tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		return showDialogMsg{
+			msg:      "Are you sure?\n" + msg,
+			detail:   detail,
+			callback: callback,
+		}
+	}
+}
+
 func showDialogCmd(msg string, callback tea.Cmd) tea.Cmd {
 	return func() tea.Msg {
 		return showDialogMsg{
@@ -59,3 +69,14 @@ func showDialogCmd(msg string, callback tea.Cmd) tea.Cmd {
 		}
 	}
 }
+
+// showInfoCmd shows a dialog telling the message, which is queued while another dialog is shown
+func showInfoCmd(msg, detail string) tea.Cmd {
+	return func() tea.Msg {
+		return showDialogMsg{
+			msg:    msg,
+			detail: detail,
+			info:   true,
+		}
+	}
+}
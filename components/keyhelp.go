@@ -0,0 +1,134 @@
+package components
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type keyHelpKeyMap struct {
+	Close key.Binding
+	Up    key.Binding
+	Down  key.Binding
+}
+
+func newKeyHelpKeyMap(help key.Binding) keyHelpKeyMap {
+	return keyHelpKeyMap{
+		Close: key.NewBinding(
+			key.WithKeys(append([]string{"esc"}, help.Keys()...)...),
+			key.WithHelp("esc | "+help.Help().Key, "close help"),
+		),
+		Up: key.NewBinding(
+			key.WithKeys("up", "k", "pgup"),
+			key.WithHelp("↑ | k | pgup", "scroll up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j", "pgdown"),
+			key.WithHelp("↓ | j | pgdown", "scroll down"),
+		),
+	}
+}
+
+// KeyHelpModel shows every key binding grouped by pane
+type KeyHelpModel struct {
+	keyMap   keyHelpKeyMap
+	viewport viewport.Model
+	panes    map[string]map[string]*key.Binding
+	show     bool
+}
+
+func NewKeyHelpModel(keys KeyConfig, help key.Binding) KeyHelpModel {
+	vp := viewport.New(0, 0)
+	vp.KeyMap = viewport.KeyMap{}
+
+	return KeyHelpModel{
+		keyMap:   newKeyHelpKeyMap(help),
+		viewport: vp,
+		panes:    paneBindings(keys),
+	}
+}
+
+func (m KeyHelpModel) Update(msg tea.Msg) (KeyHelpModel, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		if m.show {
+			m.viewport, cmd = m.viewport.Update(msg)
+		}
+	case tea.KeyMsg:
+		if !m.show {
+			break
+		}
+		switch {
+		case key.Matches(msg, m.keyMap.Close):
+			m.show = false
+		case msg.String() == "pgup":
+			m.viewport.HalfPageUp()
+		case msg.String() == "pgdown":
+			m.viewport.HalfPageDown()
+		case key.Matches(msg, m.keyMap.Up):
+			m.viewport.ScrollUp(1)
+		case key.Matches(msg, m.keyMap.Down):
+			m.viewport.ScrollDown(1)
+		}
+	}
+
+	return m, cmd
+}
+
+func (m KeyHelpModel) View() string {
+	return lipgloss.JoinVertical(lipgloss.Left, titleStyle.Render("Key bindings"), "", m.viewport.View())
+}
+
+// render lists the enabled bindings of every pane, sorted by key
+func (m KeyHelpModel) render() string {
+	var rows []string
+	for _, p := range keyPanes {
+		bindings := m.panes[p.name]
+		var lines []string
+		for _, action := range slices.Sorted(maps.Keys(bindings)) {
+			b := bindings[action]
+			if !b.Enabled() {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("  %-20s %s", b.Help().Key, itemDescStyle.Render(b.Help().Desc)))
+		}
+		slices.Sort(lines)
+		rows = append(rows, titleStyle.Render(p.title))
+		rows = append(rows, lines...)
+		rows = append(rows, "")
+	}
+	return strings.TrimSuffix(strings.Join(rows, "\n"), "\n")
+}
+
+func (m KeyHelpModel) IsShown() bool {
+	return m.show
+}
+
+func (m KeyHelpModel) ShortHelp() []key.Binding {
+	return []key.Binding{m.keyMap.Up, m.keyMap.Down, m.keyMap.Close}
+}
+
+func (m KeyHelpModel) FullHelp() [][]key.Binding {
+	return [][]key.Binding{m.ShortHelp()}
+}
+
+func (m *KeyHelpModel) SetSize(w, h int) {
+	// title and an empty line
+	m.viewport.Width = w
+	m.viewport.Height = max(h-2, 0)
+}
+
+// Open shows the key bindings from the top
+func (m *KeyHelpModel) Open() {
+	m.show = true
+	m.viewport.SetContent(m.render())
+	m.viewport.GotoTop()
+}
@@ -0,0 +1,217 @@
+package components
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// KeyConfig remaps the key bindings by pane and action, e.g. {"packages": {"update": ["U"]}}
+type KeyConfig map[string]map[string][]string
+
+const (
+	keyPaneGlobal    = "global"
+	keyPaneManagers  = "managers"
+	keyPanePackages  = "packages"
+	keyPaneLogs      = "logs"
+	keyPanePrune     = "prune"
+	keyPanePlans     = "plans"
+	keyPaneScan      = "scan"
+	keyPaneChangelog = "changelog"
+)
+
+// keyPanes are the panes of the bindings in the order of the help
+var keyPanes = []struct{ name, title string }{
+	{keyPaneGlobal, "Global"},
+	{keyPaneLogs, "Logs"},
+	{keyPaneManagers, "Package Managers"},
+	{keyPanePackages, "Packages"},
+	{keyPanePrune, "Dangling images"},
+	{keyPanePlans, "Update plans"},
+	{keyPaneScan, "Vulnerability scan"},
+	{keyPaneChangelog, "Changelog"},
+}
+
+// keyLabels are shown in the help instead of the key names
+var keyLabels = map[string]string{
+	" ":          "space",
+	"up":         "↑",
+	"down":       "↓",
+	"left":       "←",
+	"right":      "→",
+	"shift+up":   "shift+↑",
+	"shift+down": "shift+↓",
+}
+
+// keyName returns the name of the key matched by bubbletea, e.g. " " for space
+func keyName(k string) string {
+	if k == "space" {
+		return " "
+	}
+	return k
+}
+
+func keyLabel(k string) string {
+	if l, ok := keyLabels[k]; ok {
+		return l
+	}
+	return k
+}
+
+// remapKeys replaces the keys of the actions of the pane with the configured ones. No keys disable the action.
+// Unknown actions are reported by validateKeys.
+func remapKeys(keys KeyConfig, pane string, bindings map[string]*key.Binding) {
+	for action, ks := range keys[pane] {
+		b, ok := bindings[action]
+		if !ok {
+			continue
+		}
+		if len(ks) == 0 {
+			// bindings without keys stay disabled
+			*b = key.Binding{}
+			continue
+		}
+		names := make([]string, 0, len(ks))
+		labels := make([]string, 0, len(ks))
+		for _, k := range ks {
+			names = append(names, keyName(k))
+			labels = append(labels, keyLabel(keyName(k)))
+		}
+		*b = key.NewBinding(
+			key.WithKeys(names...),
+			key.WithHelp(strings.Join(labels, " | "), b.Help().Desc),
+		)
+	}
+}
+
+// newListKeyMap returns the keys of the lists which do not clash with the keys of the panes
+func newListKeyMap() list.KeyMap {
+	km := list.DefaultKeyMap()
+	km.GoToStart = key.NewBinding(
+		key.WithKeys("home"),
+		key.WithHelp("home", "go to start"),
+	)
+	km.NextPage = key.NewBinding(
+		key.WithKeys("pgdown"),
+		key.WithHelp("pgdown", "next page"),
+	)
+	km.PrevPage = key.NewBinding(
+		key.WithKeys("pgup"),
+		key.WithHelp("pgup", "prev page"),
+	)
+	// the help of every pane is shown with the global key, and bindings without keys stay disabled
+	km.ShowFullHelp = key.Binding{}
+	km.CloseFullHelp = key.Binding{}
+	return km
+}
+
+// listBindings are the remappable keys of a list
+func listBindings(km *list.KeyMap) map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":        &km.CursorUp,
+		"down":      &km.CursorDown,
+		"top":       &km.GoToStart,
+		"bottom":    &km.GoToEnd,
+		"next_page": &km.NextPage,
+		"prev_page": &km.PrevPage,
+		"filter":    &km.Filter,
+	}
+}
+
+func newManagersListKeyMap(keys KeyConfig) list.KeyMap {
+	km := newListKeyMap()
+	remapKeys(keys, keyPaneManagers, listBindings(&km))
+	return km
+}
+
+func newPackagesListKeyMap(keys KeyConfig) list.KeyMap {
+	km := newListKeyMap()
+	// Disable next/prev page in paginator
+	km.NextPage = key.Binding{}
+	km.PrevPage = key.Binding{}
+	remapKeys(keys, keyPanePackages, listBindings(&km))
+	return km
+}
+
+// paneBindings returns the bindings of every pane after remapping
+func paneBindings(keys KeyConfig) map[string]map[string]*key.Binding {
+	mainKm := newMainKeyMap(keys)
+	outputKm := newOutputKeyMap(keys)
+	managersKm := newManagersKeyMap(keys)
+	managersListKm := newManagersListKeyMap(keys)
+	packagesKm := newPackagesKeyMap(keys)
+	packagesListKm := newPackagesListKeyMap(keys)
+	pruneKm := newPruneKeyMap(keys)
+	plansKm := newPlansKeyMap(keys)
+	scanKm := newScanKeyMap(keys)
+	changelogKm := newChangelogKeyMap(keys)
+
+	managers := managersKm.bindings()
+	maps.Copy(managers, listBindings(&managersListKm))
+	packages := packagesKm.bindings()
+	maps.Copy(packages, listBindings(&packagesListKm))
+
+	return map[string]map[string]*key.Binding{
+		keyPaneGlobal:    mainKm.bindings(),
+		keyPaneLogs:      outputKm.bindings(),
+		keyPaneManagers:  managers,
+		keyPanePackages:  packages,
+		keyPanePrune:     pruneKm.bindings(),
+		keyPanePlans:     plansKm.bindings(),
+		keyPaneScan:      scanKm.bindings(),
+		keyPaneChangelog: changelogKm.bindings(),
+	}
+}
+
+// validateKeys checks that the config names known panes and actions,
+// and that no key is bound to two actions active at the same time
+func validateKeys(keys KeyConfig) error {
+	panes := paneBindings(keys)
+	for _, pane := range slices.Sorted(maps.Keys(keys)) {
+		bindings, ok := panes[pane]
+		if !ok {
+			return fmt.Errorf("unknown pane %s, valid panes: %s", pane, strings.Join(slices.Sorted(maps.Keys(panes)), ", "))
+		}
+		for _, action := range slices.Sorted(maps.Keys(keys[pane])) {
+			if _, ok := bindings[action]; !ok {
+				return fmt.Errorf("unknown action %s.%s, valid actions: %s", pane, action, strings.Join(slices.Sorted(maps.Keys(bindings)), ", "))
+			}
+		}
+	}
+
+	// the global and log keys are active in every pane
+	for _, p := range keyPanes {
+		if p.name == keyPaneGlobal || p.name == keyPaneLogs {
+			continue
+		}
+		if err := checkConflicts(panes, keyPaneGlobal, keyPaneLogs, p.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkConflicts(panes map[string]map[string]*key.Binding, names ...string) error {
+	bound := map[string]string{}
+	for _, pane := range names {
+		bindings := panes[pane]
+		for _, action := range slices.Sorted(maps.Keys(bindings)) {
+			b := bindings[action]
+			if !b.Enabled() {
+				continue
+			}
+			for _, k := range b.Keys() {
+				id := pane + "." + action
+				if other, ok := bound[k]; ok && other != id {
+					return fmt.Errorf("key %q is bound to both %s and %s", keyLabel(k), other, id)
+				}
+				bound[k] = id
+			}
+		}
+	}
+	return nil
+}
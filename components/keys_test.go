@@ -0,0 +1,89 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    KeyConfig
+		wantErr string
+	}{
+		{
+			name: "default",
+		},
+		{
+			name: "remapped",
+			keys: KeyConfig{
+				keyPaneGlobal:   {"quit": {"ctrl+q"}},
+				keyPanePackages: {"update": {"U"}, "update_all": {"ctrl+u"}},
+			},
+		},
+		{
+			name: "swapped keys",
+			keys: KeyConfig{
+				keyPanePackages: {"sort": {"g"}, "group": {"o"}},
+			},
+		},
+		{
+			name:    "unknown pane",
+			keys:    KeyConfig{"images": {"prune": {"p"}}},
+			wantErr: "unknown pane images",
+		},
+		{
+			name:    "unknown action",
+			keys:    KeyConfig{keyPaneManagers: {"remove": {"d"}}},
+			wantErr: "unknown action managers.remove",
+		},
+		{
+			name:    "conflict in a pane",
+			keys:    KeyConfig{keyPanePackages: {"sort": {"u"}}},
+			wantErr: `key "u" is bound to both packages.sort and packages.update`,
+		},
+		{
+			name:    "conflict with a list key",
+			keys:    KeyConfig{keyPaneManagers: {"check": {"k"}}},
+			wantErr: `key "k" is bound to both managers.check and managers.up`,
+		},
+		{
+			name:    "conflict with a global key",
+			keys:    KeyConfig{keyPanePrune: {"toggle_all": {"q"}}},
+			wantErr: `key "q" is bound to both global.quit and prune.toggle_all`,
+		},
+		{
+			name:    "conflict with a log key",
+			keys:    KeyConfig{keyPaneScan: {"down": {"ctrl+j"}}},
+			wantErr: `key "ctrl+j" is bound to both logs.down and scan.down`,
+		},
+		{
+			name: "same key in overlays",
+			keys: KeyConfig{keyPanePlans: {"delete": {"x"}}, keyPanePrune: {"remove": {"x"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateKeys(tt.keys)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRemapKeys(t *testing.T) {
+	km := newManagersKeyMap(KeyConfig{
+		keyPaneManagers: {"toggle": {"space", "x"}, "scan": {}},
+	})
+
+	assert.Equal(t, []string{" ", "x"}, km.Toggle.Keys())
+	assert.Equal(t, "space | x", km.Toggle.Help().Key)
+	assert.Equal(t, "toggle check", km.Toggle.Help().Desc)
+	assert.False(t, km.Scan.Enabled())
+	assert.Equal(t, []string{"r"}, km.Check.Keys())
+}
@@ -0,0 +1,263 @@
+package components
+
+import (
+	"log"
+	"math"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// LayoutConfig is the arrangement of the panes, changed with the keys and saved in the config file
+type LayoutConfig struct {
+	// Managers is the share of the managers pane, in the width side by side and in the height stacked
+	Managers float64 `yaml:"managers,omitempty"`
+	// Logs is the share of the log pane in the height of the packages and the logs
+	Logs float64 `yaml:"logs,omitempty"`
+	// Mode is auto, horizontal or vertical. auto stacks the panes in narrow terminals.
+	Mode string `yaml:"mode,omitempty"`
+}
+
+const (
+	layoutAuto       = "auto"
+	layoutHorizontal = "horizontal"
+	layoutVertical   = "vertical"
+
+	zoomPackages = "packages"
+	zoomLogs     = "logs"
+
+	// narrowWidth is the width below which the auto layout stacks the panes
+	narrowWidth = 100
+
+	// minPackagesHeight are the rows of the title and two packages, kept while the dialog is shown
+	minPackagesHeight = 4
+
+	layoutStep     = 0.05
+	layoutMinShare = 0.15
+	layoutMaxShare = 0.85
+)
+
+var defaultLayout = LayoutConfig{Managers: 0.4, Logs: 0.5, Mode: layoutAuto}
+
+var docStyleBottomBorder = docStyle.Border(lipgloss.NormalBorder(), false, false, true, false)
+
+// withDefaults fills the unset or invalid settings with the default ones
+func (l LayoutConfig) withDefaults() LayoutConfig {
+	if l.Managers <= 0 {
+		l.Managers = defaultLayout.Managers
+	}
+	if l.Logs <= 0 {
+		l.Logs = defaultLayout.Logs
+	}
+	l.Managers = clampShare(l.Managers)
+	l.Logs = clampShare(l.Logs)
+	switch l.Mode {
+	case layoutAuto, layoutHorizontal, layoutVertical:
+	default:
+		l.Mode = defaultLayout.Mode
+	}
+	return l
+}
+
+// clampShare keeps the share between the bounds, rounded so that the steps do not add up errors in the config file
+func clampShare(f float64) float64 {
+	return min(max(math.Round(f*100)/100, layoutMinShare), layoutMaxShare)
+}
+
+// nextMode cycles the layout modes
+func (l LayoutConfig) nextMode() string {
+	switch l.Mode {
+	case layoutAuto:
+		return layoutHorizontal
+	case layoutHorizontal:
+		return layoutVertical
+	}
+	return layoutAuto
+}
+
+// vertical reports whether the panes are stacked in a terminal of the width
+func (l LayoutConfig) vertical(w int) bool {
+	return l.Mode == layoutVertical || (l.Mode == layoutAuto && w < narrowWidth)
+}
+
+// paneRects are the outer areas of the panes, which are empty when hidden.
+// The packages pane includes the dialog shown below the list.
+type paneRects struct {
+	managers, packages, logs zone
+}
+
+// computePanes divides the screen without the help line and the status bar between the panes
+func computePanes(l LayoutConfig, zoom string, w, h int) paneRects {
+	body := max(h-1, 0)
+	share := func(n int, f float64) int {
+		return int(float64(n)*f + 0.5)
+	}
+
+	switch zoom {
+	case zoomPackages:
+		return paneRects{packages: zone{w: w, h: body}}
+	case zoomLogs:
+		return paneRects{logs: zone{w: w, h: body}}
+	}
+
+	if l.vertical(w) {
+		mh := share(body, l.Managers)
+		rest := body - mh
+		lh := share(rest, l.Logs)
+		return paneRects{
+			managers: zone{w: w, h: mh},
+			packages: zone{y: mh, w: w, h: rest - lh},
+			logs:     zone{y: mh + rest - lh, w: w, h: lh},
+		}
+	}
+
+	mw := share(w, l.Managers)
+	lh := share(body, l.Logs)
+	return paneRects{
+		managers: zone{w: mw, h: body},
+		packages: zone{x: mw, w: w - mw, h: body - lh},
+		logs:     zone{x: mw, y: body - lh, w: w - mw, h: lh},
+	}
+}
+
+// managersStyle is the style of the managers pane, with a border toward the other panes
+func (m AppModel) managersStyle() lipgloss.Style {
+	if m.layout.vertical(m.w) {
+		return docStyleBottomBorder
+	}
+	return docStyleRightBorder
+}
+
+func (m *AppModel) updateLayout(w, h int) {
+	// the help replaces the panes and the status bar, leaving the help line
+	dfw, dfh := docStyle.GetFrameSize()
+	m.keyhelp.SetSize(w-dfw, h-dfh-1)
+
+	m.status.SetWidth(w)
+	h = max(h-m.status.Height(), 0)
+	rects := computePanes(m.layout, m.zoom, w, h)
+
+	bfw, bfh := borderStyle.GetFrameSize()
+	dx, dy := docStyle.GetMarginLeft(), docStyle.GetMarginTop()
+	pdw, pdh := m.pdialog.GetSize()
+	cdw, cdh := m.cdialog.GetSize()
+	// the password dialog is shown over the confirmation dialog
+	dialogWidth, dialogHeight := cdw, cdh
+	if pdw > 0 {
+		dialogWidth, dialogHeight = pdw, pdh
+	}
+
+	ms := m.managersStyle()
+	mw := max(rects.managers.w-ms.GetHorizontalFrameSize(), 0)
+	mh := max(rects.managers.h-ms.GetVerticalFrameSize(), 0)
+	m.mgrlist.SetSize(mw, mh)
+	m.zones.managers = zone{x: rects.managers.x + dx, y: rects.managers.y + dy, w: mw, h: mh}
+	if rects.managers.w == 0 {
+		m.zones.managers = zone{}
+	}
+
+	// the packages keep their size while zoomed into the logs, as the focus may stay on them
+	pr := rects.packages
+	if pr.w == 0 {
+		pr = zone{w: w, h: max(h-1, 0)}
+	}
+	// the dialog takes the rows of the logs when the packages are too short for it
+	lr := rects.logs
+	if lr.w > 0 && pr.w > 0 {
+		lack := min(max(minPackagesHeight+dfh+dialogHeight-pr.h, 0), lr.h)
+		pr.h += lack
+		lr.y += lack
+		lr.h -= lack
+	}
+	pw := max(pr.w-dfw, 0)
+	ph := max(pr.h-dfh-pdh-cdh, 0)
+	for _, l := range m.pkglists {
+		l.SetSize(pw, ph)
+	}
+	m.all.SetSize(pw, ph)
+	m.scan.SetSize(pw, ph)
+	m.zones.packages = zone{x: pr.x + dx, y: pr.y + dy, w: pw, h: ph}
+	m.zones.dialog = zone{x: pr.x, y: pr.y + ph + dfh, w: dialogWidth, h: dialogHeight}
+	if rects.packages.w == 0 {
+		m.zones.packages = zone{}
+		// the dialog is shown above the logs
+		m.zones.dialog = zone{w: dialogWidth, h: dialogHeight}
+	}
+
+	if rects.packages.w == 0 {
+		lr.y += dialogHeight
+		lr.h = max(lr.h-dialogHeight, 0)
+	}
+	m.out.SetSize(max(lr.w-bfw, 0), max(lr.h-bfh, 0))
+	m.zones.logs = lr
+	if lr.w == 0 {
+		m.zones.logs = zone{}
+	}
+}
+
+// layoutView joins the panes as updateLayout places them
+func (m AppModel) layoutView() string {
+	var packages string
+	if pkg, ok := m.pkglists[m.selectedPkg]; ok {
+		packages = pkg.View()
+	}
+	if m.selectedPkg == PACKAGE_MANAGER_ALL {
+		packages = m.all.View()
+	}
+	if m.scan.IsShown() {
+		packages = m.scan.View()
+	}
+	packages = docStyle.Render(packages)
+
+	dialog := ""
+	if x, _ := m.cdialog.GetSize(); x > 0 {
+		dialog = m.cdialog.View()
+	}
+	if x, _ := m.pdialog.GetSize(); x > 0 {
+		dialog = m.pdialog.View()
+	}
+
+	switch m.zoom {
+	case zoomPackages:
+		return lipgloss.JoinVertical(lipgloss.Left, packages, dialog)
+	case zoomLogs:
+		return lipgloss.JoinVertical(lipgloss.Left, dialog, borderStyle.Render(m.out.View()))
+	}
+
+	logs := borderStyle.Render(m.out.View())
+	// the width is fixed so that the panes are where updateLayout places them
+	managers := m.managersStyle().Width(m.zones.managers.w).Render(m.mgrlist.View())
+	if m.layout.vertical(m.w) {
+		return lipgloss.JoinVertical(lipgloss.Left, managers, packages, dialog, logs)
+	}
+	return lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		managers,
+		lipgloss.JoinVertical(lipgloss.Left, packages, dialog, logs),
+	)
+}
+
+// resize changes the share of the managers or the logs by steps, keeping it between the bounds
+func (m *AppModel) resize(managers, logs int) {
+	m.layout.Managers = clampShare(m.layout.Managers + float64(managers)*layoutStep)
+	m.layout.Logs = clampShare(m.layout.Logs + float64(logs)*layoutStep)
+}
+
+// toggleZoom shows the pane on the whole screen, or restores the layout when it is already zoomed
+func (m *AppModel) toggleZoom(pane string) {
+	if m.zoom == pane {
+		m.zoom = ""
+	} else {
+		m.zoom = pane
+	}
+}
+
+func (m AppModel) saveLayoutCmd() tea.Cmd {
+	path, layout := m.config.Path, m.layout
+	return func() tea.Msg {
+		if err := saveConfigEntry(path, "layout", "", layout); err != nil {
+			log.Printf("Error saving the config file: %v", err)
+		}
+		return nil
+	}
+}
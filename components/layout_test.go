@@ -0,0 +1,166 @@
+package components
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayoutConfigWithDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		in   LayoutConfig
+		want LayoutConfig
+	}{
+		{
+			name: "empty",
+			want: defaultLayout,
+		},
+		{
+			name: "set",
+			in:   LayoutConfig{Managers: 0.3, Logs: 0.4, Mode: layoutVertical},
+			want: LayoutConfig{Managers: 0.3, Logs: 0.4, Mode: layoutVertical},
+		},
+		{
+			name: "out of bounds",
+			in:   LayoutConfig{Managers: 0.01, Logs: 2, Mode: "diagonal"},
+			want: LayoutConfig{Managers: layoutMinShare, Logs: layoutMaxShare, Mode: layoutAuto},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.in.withDefaults())
+		})
+	}
+}
+
+func TestLayoutConfigNextMode(t *testing.T) {
+	l := defaultLayout
+	var modes []string
+	for range 3 {
+		l.Mode = l.nextMode()
+		modes = append(modes, l.Mode)
+	}
+	assert.Equal(t, []string{layoutHorizontal, layoutVertical, layoutAuto}, modes)
+}
+
+func TestComputePanes(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout LayoutConfig
+		zoom   string
+		w, h   int
+		want   paneRects
+	}{
+		{
+			name:   "side by side",
+			layout: defaultLayout,
+			w:      150,
+			h:      41,
+			want: paneRects{
+				managers: zone{w: 60, h: 40},
+				packages: zone{x: 60, w: 90, h: 20},
+				logs:     zone{x: 60, y: 20, w: 90, h: 20},
+			},
+		},
+		{
+			name:   "stacked in a narrow terminal",
+			layout: defaultLayout,
+			w:      80,
+			h:      41,
+			want: paneRects{
+				managers: zone{w: 80, h: 16},
+				packages: zone{y: 16, w: 80, h: 12},
+				logs:     zone{y: 28, w: 80, h: 12},
+			},
+		},
+		{
+			name:   "side by side in a narrow terminal",
+			layout: LayoutConfig{Managers: 0.5, Logs: 0.25, Mode: layoutHorizontal},
+			w:      80,
+			h:      41,
+			want: paneRects{
+				managers: zone{w: 40, h: 40},
+				packages: zone{x: 40, w: 40, h: 30},
+				logs:     zone{x: 40, y: 30, w: 40, h: 10},
+			},
+		},
+		{
+			name:   "zoomed into the packages",
+			layout: defaultLayout,
+			zoom:   zoomPackages,
+			w:      150,
+			h:      41,
+			want:   paneRects{packages: zone{w: 150, h: 40}},
+		},
+		{
+			name:   "zoomed into the logs",
+			layout: defaultLayout,
+			zoom:   zoomLogs,
+			w:      150,
+			h:      41,
+			want:   paneRects{logs: zone{w: 150, h: 40}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, computePanes(tt.layout, tt.zoom, tt.w, tt.h))
+		})
+	}
+}
+
+func TestLayoutKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	m, err := NewAppModel(NewConfig(false, nil, nil, true, ""))
+	assert.NoError(t, err)
+	m.config.Path = path
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 150, Height: 40})
+
+	press := func(k string) {
+		tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		tm, _ = tm.Update(UpdateLayoutMsg{})
+	}
+
+	press(">")
+	press("-")
+	m = tm.(AppModel)
+	assert.InDelta(t, 0.45, m.layout.Managers, 1e-9)
+	assert.InDelta(t, 0.45, m.layout.Logs, 1e-9)
+	assert.Nil(t, m.saveLayoutCmd()())
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "layout:\n  managers: 0.45\n  logs: 0.45\n  mode: auto\n", string(b))
+
+	press("z")
+	assert.Equal(t, zoomPackages, tm.(AppModel).zoom)
+	assert.Equal(t, zone{}, tm.(AppModel).zones.managers)
+	press("Z")
+	assert.Equal(t, zoomLogs, tm.(AppModel).zoom)
+	press("Z")
+	assert.Equal(t, "", tm.(AppModel).zoom)
+
+	press("L")
+	assert.Equal(t, layoutHorizontal, tm.(AppModel).layout.Mode)
+}
+
+func TestUpdateLayoutDialog(t *testing.T) {
+	m, err := NewAppModel(NewConfig(false, nil, nil, true, ""))
+	assert.NoError(t, err)
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	tm, _ = tm.Update(showDialogMsg{msg: "All packages will be updated"})
+	tm, _ = tm.Update(UpdateLayoutMsg{})
+	m = tm.(AppModel)
+
+	// the dialog takes the rows of the logs in the short packages pane
+	assert.Equal(t, minPackagesHeight, m.zones.packages.h)
+	assert.Equal(t, m.zones.dialog.y+m.zones.dialog.h, m.zones.logs.y)
+	assert.Equal(t, 30, lipgloss.Height(m.View()))
+}
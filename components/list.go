@@ -3,38 +3,57 @@ package components
 import (
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/ymtdzzz/lazypkg/executors"
 )
 
+const progressBarWidth = 20
+
 var (
-	titleStyle        = lipgloss.NewStyle().MarginLeft(2)
-	blurTitleStyle    = titleStyle.Foreground(lipgloss.Color("#777777"))
-	itemStyle         = lipgloss.NewStyle().PaddingLeft(4)
-	itemDescStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#777777"))
-	selectedItemStyle = lipgloss.NewStyle().PaddingLeft(2).Foreground(lipgloss.Color("170"))
+	titleStyle       = lipgloss.NewStyle().MarginLeft(2)
+	itemStyle        = lipgloss.NewStyle().PaddingLeft(4)
+	groupHeaderStyle = lipgloss.NewStyle().Bold(true)
 )
 
 type item struct {
 	icon        rune
 	title, desc string
+	info        *executors.PackageInfo
+	// mgr is set for the rows of the combined list, which contains the packages of every manager
+	mgr string
+	// header is set for the row of a package group, which is not a package
+	header bool
 }
 
 func (i item) Title() string       { return i.title }
 func (i item) Description() string { return i.desc }
-func (i item) FilterValue() string { return i.title }
+func (i item) FilterValue() string {
+	if i.header {
+		return ""
+	}
+	if i.mgr != "" {
+		return allKey(i.mgr, i.title)
+	}
+	return i.title
+}
 
 type itemDelegate struct {
 	spinnerStr *string
-	// TODO: set via item struct
-	selection map[int]bool
-	loading   map[int]bool
+	// selection and loading are keyed by the item names so that they survive filtering
+	selection map[string]bool
+	loading   map[string]bool
 	focus     *bool
+	// progress returns how far the update of the item is, nil when the package manager does not tell it
+	progress func(name string) (float64, bool)
+	// failed reports whether the last update of the item failed
+	failed func(name string) bool
 }
 
-func newItemDelegate(spinnerStr *string, selection, loading map[int]bool, focus *bool) itemDelegate {
+func newItemDelegate(spinnerStr *string, selection, loading map[string]bool, focus *bool) itemDelegate {
 	return itemDelegate{
 		spinnerStr: spinnerStr,
 		selection:  selection,
@@ -43,6 +62,25 @@ func newItemDelegate(spinnerStr *string, selection, loading map[int]bool, focus
 	}
 }
 
+func (d itemDelegate) itemProgress(i item) (float64, bool) {
+	if d.progress == nil || i.info == nil {
+		return 0, false
+	}
+	return d.progress(i.FilterValue())
+}
+
+// renderProgressBar renders the fraction like [██░░]  50%
+func renderProgressBar(f float64, width int) string {
+	f = min(max(f, 0), 1)
+	filled := int(f * float64(width))
+	return fmt.Sprintf(
+		"[%s%s] %3d%%",
+		strings.Repeat("█", filled),
+		strings.Repeat("░", width-filled),
+		int(f*100),
+	)
+}
+
 func (d itemDelegate) Height() int                               { return 1 }
 func (d itemDelegate) Spacing() int                              { return 0 }
 func (d itemDelegate) Update(msg tea.Msg, _ *list.Model) tea.Cmd { return nil }
@@ -53,12 +91,35 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 	}
 
 	check := " "
-	if v, ok := d.selection[index]; ok && v {
+	if d.selection[i.FilterValue()] {
 		check = "*"
 	}
-	str := fmt.Sprintf("%s %c  %s %s", check, i.icon, i.title, itemDescStyle.Render(i.desc))
-	if v, ok := d.loading[index]; ok && v {
-		str = str + " " + *d.spinnerStr
+	str := fmt.Sprintf("%s %s  %s %s", check, iconString(i.icon), i.title, itemDescStyle.Render(i.desc))
+	if i.header {
+		title := i.title
+		if title == "" {
+			title = "other"
+		}
+		str = groupHeaderStyle.Render("▾ " + title)
+	}
+	if i.info != nil && i.info.UpdateType != "" {
+		str = str + " " + updateTypeStyles[i.info.UpdateType].Render("["+i.info.UpdateType+"]")
+	}
+	if i.info != nil && i.info.Security {
+		str = str + " " + securityBadgeStyle.Render("[security]")
+	}
+	if i.info != nil && i.info.CheckError != "" {
+		str = str + " " + checkErrorStyle.Render("["+i.info.CheckError+"]")
+	}
+	if i.info != nil && d.failed != nil && d.failed(i.FilterValue()) {
+		str = str + " " + failedBadgeStyle.Render("[failed]")
+	}
+	if d.loading[i.FilterValue()] {
+		if f, ok := d.itemProgress(i); ok {
+			str = str + " " + renderProgressBar(f, progressBarWidth)
+		} else {
+			str = str + " " + *d.spinnerStr
+		}
 	}
 
 	var style lipgloss.Style
@@ -70,7 +131,7 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 	}
 
 	if !(*d.focus) {
-		style = style.Foreground(lipgloss.Color("#777777"))
+		style = style.Inherit(blurItemStyle)
 	}
 
 	fmt.Fprint(w, style.Render(str))
@@ -2,6 +2,7 @@ package components
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
@@ -15,10 +16,11 @@ type managersKeyMap struct {
 	Check    key.Binding
 	CheckAll key.Binding
 	Update   key.Binding
+	Scan     key.Binding
 }
 
-func newManagersKeyMap() managersKeyMap {
-	return managersKeyMap{
+func newManagersKeyMap(keys KeyConfig) managersKeyMap {
+	km := managersKeyMap{
 		Toggle: key.NewBinding(
 			key.WithKeys(" "),
 			key.WithHelp("space", "toggle check"),
@@ -39,6 +41,23 @@ func newManagersKeyMap() managersKeyMap {
 			key.WithKeys("u"),
 			key.WithHelp("u", "update"),
 		),
+		Scan: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "vulnerability scan"),
+		),
+	}
+	remapKeys(keys, keyPaneManagers, km.bindings())
+	return km
+}
+
+func (km *managersKeyMap) bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"toggle":    &km.Toggle,
+		"select":    &km.Select,
+		"check":     &km.Check,
+		"check_all": &km.CheckAll,
+		"update":    &km.Update,
+		"scan":      &km.Scan,
 	}
 }
 
@@ -46,34 +65,36 @@ type ManagersModel struct {
 	keyMap     managersKeyMap
 	spinner    spinner.Model
 	spinnerStr *string
-	mgrToIdx   map[string]int
-	idxToMgr   map[int]string
-	list       list.Model
-	pkglists   map[string]*PackagesModel
-	focus      *bool
-	selection  map[int]bool
-	loading    map[int]bool
+	// mgrToIdx is the position of the managers in the unfiltered list
+	mgrToIdx  map[string]int
+	list      list.Model
+	pkglists  map[string]*PackagesModel
+	focus     *bool
+	selection map[string]bool
+	loading   map[string]bool
 }
 
-func NewManagersModel(mgrs []string, pkglists map[string]*PackagesModel) ManagersModel {
+func NewManagersModel(mgrs []string, pkglists map[string]*PackagesModel, keys KeyConfig) ManagersModel {
 	s := spinner.New()
 	s.Spinner = spinner.Line
 	ss := s.View()
 
-	selection := map[int]bool{}
-	loading := map[int]bool{}
+	selection := map[string]bool{}
+	loading := map[string]bool{}
 	focus := false
 
-	items := make([]list.Item, 0, len(mgrs))
+	// the virtual manager listing every package comes first
+	items := []list.Item{item{
+		icon:  ICON_ALL,
+		title: PACKAGE_MANAGER_ALL,
+	}}
 	mgrToIdx := map[string]int{}
-	idxToMgr := map[int]string{}
-	for i, mgr := range mgrs {
+	for _, mgr := range mgrs {
+		mgrToIdx[mgr] = len(items)
 		items = append(items, item{
 			icon:  pkglists[mgr].Icon(),
 			title: mgr,
 		})
-		mgrToIdx[mgr] = i
-		idxToMgr[i] = mgr
 	}
 	l := list.New(
 		items,
@@ -83,17 +104,18 @@ func NewManagersModel(mgrs []string, pkglists map[string]*PackagesModel) Manager
 	)
 	l.Title = "Package Managers"
 	l.SetShowStatusBar(false)
-	l.SetFilteringEnabled(false)
+	l.SetFilteringEnabled(true)
 	l.SetShowHelp(false)
+	l.KeyMap = newManagersListKeyMap(keys)
 	l.DisableQuitKeybindings()
 	l.Styles.Title = blurTitleStyle
 	l.Styles.HelpStyle = helpStyle
-	km := newManagersKeyMap()
+	km := newManagersKeyMap(keys)
 	l.AdditionalShortHelpKeys = func() []key.Binding {
-		return []key.Binding{km.Toggle, km.Select, km.Check, km.CheckAll, km.Update}
+		return []key.Binding{km.Toggle, km.Select, km.Check, km.CheckAll, km.Update, km.Scan}
 	}
 	l.AdditionalFullHelpKeys = func() []key.Binding {
-		return []key.Binding{km.Toggle, km.Select, km.Check, km.CheckAll, km.Update}
+		return []key.Binding{km.Toggle, km.Select, km.Check, km.CheckAll, km.Update, km.Scan}
 	}
 
 	return ManagersModel{
@@ -101,7 +123,6 @@ func NewManagersModel(mgrs []string, pkglists map[string]*PackagesModel) Manager
 		spinner:    s,
 		spinnerStr: &ss,
 		mgrToIdx:   mgrToIdx,
-		idxToMgr:   idxToMgr,
 		list:       l,
 		pkglists:   pkglists,
 		selection:  selection,
@@ -130,18 +151,43 @@ func (m ManagersModel) Update(msg tea.Msg) (ManagersModel, tea.Cmd) {
 		*m.spinnerStr = m.spinner.View()
 		return m, cmd
 	case getPackageStartMsg:
-		if idx, ok := m.mgrToIdx[msg.name]; ok {
-			m.loading[idx] = true
-		}
+		m.loading[msg.name] = true
 	case getPackageFinishMsg:
-		if idx, ok := m.mgrToIdx[msg.name]; ok {
-			m.loading[idx] = false
+		m.loading[msg.name] = false
+	case clickMsg:
+		if msg.pane != keyPaneManagers {
+			break
+		}
+		// a click selects the manager as enter does
+		if i, ok := listItemAt(m.list, msg.y); ok {
+			m.list.Select(i)
+			name := m.list.SelectedItem().FilterValue()
+			cmds = append(cmds, func() tea.Msg {
+				return ChangeManagerSelectionMsg{Name: name}
+			}, func() tea.Msg {
+				return FocusPackagesMsg{Name: name}
+			})
+		}
+	case scrollMsg:
+		if msg.pane != keyPaneManagers {
+			break
+		}
+		previous := m.list.SelectedItem()
+		scrollList(&m.list, msg.lines)
+		if now := m.list.SelectedItem(); now != nil && now != previous {
+			cmds = append(cmds, func() tea.Msg {
+				return ChangeManagerSelectionMsg{Name: now.FilterValue()}
+			})
 		}
 	}
 
 	if *m.focus {
 		switch msg := msg.(type) {
 		case tea.KeyMsg:
+			if m.IsFiltering() {
+				// the keys are typed into the filter
+				break
+			}
 			switch {
 			case key.Matches(msg, m.keyMap.Select):
 				if item := m.list.SelectedItem(); item != nil {
@@ -152,18 +198,24 @@ func (m ManagersModel) Update(msg tea.Msg) (ManagersModel, tea.Cmd) {
 					})
 				}
 			case key.Matches(msg, m.keyMap.Toggle):
-				idx := m.list.Index()
-				if v, ok := m.selection[idx]; v && ok {
-					m.selection[idx] = false
-				} else {
-					m.selection[idx] = true
+				// the managers of All are updated with "u" on it
+				if item := m.list.SelectedItem(); item != nil && item.FilterValue() != PACKAGE_MANAGER_ALL {
+					m.selection[item.FilterValue()] = !m.selection[item.FilterValue()]
 				}
 			case key.Matches(msg, m.keyMap.Check):
 				if item := m.list.SelectedItem(); item != nil {
 					if pkg, ok := m.pkglists[item.FilterValue()]; ok {
 						cmds = append(cmds, pkg.getPackagesCmd())
+					} else if item.FilterValue() == PACKAGE_MANAGER_ALL {
+						for _, pkg := range m.pkglists {
+							cmds = append(cmds, pkg.getPackagesCmd())
+						}
 					}
 				}
+			case key.Matches(msg, m.keyMap.Scan):
+				cmds = append(cmds, func() tea.Msg {
+					return showScanMsg{}
+				})
 			case key.Matches(msg, m.keyMap.CheckAll):
 				for _, pkg := range m.pkglists {
 					cmds = append(cmds, pkg.getPackagesCmd())
@@ -171,14 +223,15 @@ func (m ManagersModel) Update(msg tea.Msg) (ManagersModel, tea.Cmd) {
 			case key.Matches(msg, m.keyMap.Update):
 				// Bulk update
 				var mgrs []string
-				for i, v := range m.selection {
+				for mgr, v := range m.selection {
 					if v {
-						mgrs = append(mgrs, m.idxToMgr[i])
+						mgrs = append(mgrs, mgr)
 					}
 				}
+				sort.Strings(mgrs)
 				if len(mgrs) > 0 {
-					for i := range m.selection {
-						m.selection[i] = false
+					for mgr := range m.selection {
+						m.selection[mgr] = false
 					}
 
 					subcmds := []tea.Cmd{}
@@ -198,15 +251,10 @@ func (m ManagersModel) Update(msg tea.Msg) (ManagersModel, tea.Cmd) {
 					// Single update
 					if item := m.list.SelectedItem(); item != nil {
 						mgr := item.FilterValue()
-						cmds = append(cmds, showDialogCmd(
-							fmt.Sprintf("All %s package will be updated", mgr),
-							func() tea.Msg {
-								return updateAllPackagesMsg{
-									name:      mgr,
-									confirmed: true,
-								}
-							},
-						))
+						// the package list (or the combined list for All) asks for the confirmation with the update plan
+						cmds = append(cmds, func() tea.Msg {
+							return updateAllPackagesMsg{name: mgr}
+						})
 					}
 				}
 			}
@@ -219,7 +267,7 @@ func (m ManagersModel) Update(msg tea.Msg) (ManagersModel, tea.Cmd) {
 
 		now := m.list.SelectedItem()
 
-		if previous != now {
+		if previous != now && now != nil {
 			cmds = append(cmds, func() tea.Msg {
 				return ChangeManagerSelectionMsg{
 					Name: now.FilterValue(),
@@ -228,23 +276,46 @@ func (m ManagersModel) Update(msg tea.Msg) (ManagersModel, tea.Cmd) {
 		}
 	}
 
+	var count, securityCount int
 	for mgr, l := range m.pkglists {
+		count += l.Count()
+		securityCount += l.SecurityCount()
 		if i, ok := m.mgrToIdx[mgr]; ok {
-			desc := "✓"
-			if l.Count() > 0 {
-				desc = fmt.Sprintf("[%d]", l.Count())
-			}
-			m.list.SetItem(i, item{
-				icon:  m.pkglists[mgr].icon,
-				title: mgr,
-				desc:  desc,
-			})
+			cmds = append(cmds, m.setDesc(i, managerDesc(l.Count(), l.SecurityCount())))
 		}
 	}
+	cmds = append(cmds, m.setDesc(0, managerDesc(count, securityCount)))
 
 	return m, tea.Batch(cmds...)
 }
 
+func managerDesc(count, securityCount int) string {
+	desc := "✓"
+	if count > 0 {
+		desc = fmt.Sprintf("[%d]", count)
+	}
+	if securityCount > 0 {
+		desc = desc + " " + securityBadgeStyle.Render(fmt.Sprintf("[%d security]", securityCount))
+	}
+	return desc
+}
+
+// setDesc updates the description of the manager at the index in the unfiltered list
+func (m *ManagersModel) setDesc(i int, desc string) tea.Cmd {
+	li, ok := m.list.Items()[i].(item)
+	if !ok || li.desc == desc {
+		return nil
+	}
+	li.desc = desc
+	// refilters the list when a filter is applied
+	return m.list.SetItem(i, li)
+}
+
+// IsFiltering reports whether the filter of the list is being typed
+func (m ManagersModel) IsFiltering() bool {
+	return m.list.FilterState() == list.Filtering
+}
+
 func (m ManagersModel) View() string {
 	return m.list.View()
 }
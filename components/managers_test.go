@@ -0,0 +1,50 @@
+package components
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/ymtdzzz/lazypkg/executors"
+)
+
+// sequenceMsgs runs the commands of a tea.Sequence and returns their messages
+func sequenceMsgs(cmd tea.Cmd) []tea.Msg {
+	var msgs []tea.Msg
+	v := reflect.ValueOf(cmd())
+	for i := 0; i < v.Len(); i++ {
+		if c, ok := v.Index(i).Interface().(tea.Cmd); ok && c != nil {
+			msgs = append(msgs, c())
+		}
+	}
+	return msgs
+}
+
+func TestManagersSelectionFiltered(t *testing.T) {
+	pkglists := map[string]*PackagesModel{}
+	for _, mgr := range []string{PACKAGE_MANAGER_APT, PACKAGE_MANAGER_NPM, PACKAGE_MANAGER_GEM} {
+		pkg := NewPackageModel(Config{}, mgr, managerIcons[mgr], &executors.DemoExecutor{})
+		pkglists[mgr] = &pkg
+	}
+	m := NewManagersModel([]string{PACKAGE_MANAGER_APT, PACKAGE_MANAGER_NPM, PACKAGE_MANAGER_GEM}, pkglists, nil)
+	m.Focus(true)
+	m.SetSize(40, 20)
+
+	m.list.SetFilterText("npm")
+	assert.Equal(t, PACKAGE_MANAGER_NPM, m.list.SelectedItem().FilterValue())
+	m, _ = m.Update(spaceKey)
+	m, _ = m.Update(escKey)
+	assert.Len(t, m.list.VisibleItems(), 4)
+	assert.Equal(t, map[string]bool{PACKAGE_MANAGER_NPM: true}, m.selection)
+
+	// the selected manager is updated rather than the one under the cursor
+	assert.Equal(t, PACKAGE_MANAGER_ALL, m.list.SelectedItem().FilterValue())
+	m, cmd := m.Update(updateKey)
+	dialog := summaryMsg(cmd)
+	if assert.NotNil(t, dialog) {
+		assert.Contains(t, dialog.msg, "All packages of selected 1 managers will be updated")
+		assert.Equal(t, []tea.Msg{updateAllPackagesMsg{name: PACKAGE_MANAGER_NPM, confirmed: true}}, sequenceMsgs(dialog.callback))
+	}
+	assert.Equal(t, map[string]bool{PACKAGE_MANAGER_NPM: false}, m.selection)
+}
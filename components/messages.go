@@ -1,8 +1,8 @@
 package components
 
 import (
-	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ymtdzzz/lazypkg/executors"
 )
 
 type UpdateLayoutMsg struct{}
@@ -12,8 +12,8 @@ type ChangeManagerSelectionMsg struct {
 }
 
 type packageUpdateMsg struct {
-	name  string
-	items []list.Item
+	name string
+	pkgs []*executors.PackageInfo
 }
 
 type getPackageStartMsg struct {
@@ -27,26 +27,63 @@ type getPackageFinishMsg struct {
 type updatePackagesStartMsg struct {
 	name string
 	pkgs []string
+	// bulk is set for the updates of several packages at once, e.g. the selected ones, even when only one is given
+	bulk bool
 }
 
 type updatePackagesFinishMsg struct {
 	name string
 	pkgs []string
 	err  error
+	// failed are the errors of the packages which failed, the other packages succeeded unless the password was not entered
+	failed map[string]error
+}
+
+// allUpdateStartMsg starts an update of the combined list, counts are the number of packages per manager
+type allUpdateStartMsg struct {
+	counts map[string]int
 }
 
+type statusTickMsg struct{}
+
 type updateAllPackagesMsg struct {
 	name      string
 	confirmed bool
 }
 
+// updatesSkippedMsg reports the packages of a plan not updated as an earlier phase did not succeed,
+// counts are the number of packages per manager
+type updatesSkippedMsg struct {
+	counts map[string]int
+}
+
 type passwordInputStartMsg struct {
 	callback func(password string) tea.Cmd
+	// cancel is run when the password input is canceled
+	cancel tea.Cmd
 }
 
 type showDialogMsg struct {
 	msg      string
+	detail   string
 	callback tea.Cmd
+	// info dialogs only tell something and have no callback
+	info bool
+}
+
+type showScanMsg struct{}
+
+// clickMsg is a left click in a pane, relative to the top left of the pane.
+// name is the package manager of the packages pane.
+type clickMsg struct {
+	pane, name string
+	x, y       int
+}
+
+// scrollMsg is a turn of the mouse wheel over a pane, down when lines is positive
+type scrollMsg struct {
+	pane, name string
+	lines      int
 }
 
 type FocusManagersMsg struct{}
@@ -62,3 +99,7 @@ type BlurPasswordDialogMsg struct{}
 type FocusConfirmDialogMsg struct{}
 
 type BlurConfirmDialogMsg struct{}
+
+type FocusScanMsg struct{}
+
+type BlurScanMsg struct{}
@@ -0,0 +1,111 @@
+package components
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// paneDialog is the confirmation dialog, which is clicked instead of the panes while shown
+	paneDialog = "dialog"
+	// logWheelLines are the lines the logs scroll by with the mouse wheel
+	logWheelLines = 3
+)
+
+type zone struct {
+	x, y, w, h int
+}
+
+func (z zone) contains(x, y int) bool {
+	return x >= z.x && x < z.x+z.w && y >= z.y && y < z.y+z.h
+}
+
+// layoutZones are the areas of the panes on the screen, computed with their sizes in updateLayout
+type layoutZones struct {
+	managers, packages, dialog, logs zone
+}
+
+// listItemAt returns the index in the visible items of the row of the list view, false when the row is not an item
+func listItemAt(l list.Model, row int) (int, bool) {
+	// the title and the empty line below it
+	row -= 1 + l.Styles.TitleBar.GetVerticalFrameSize()
+	if row < 0 || row >= l.Paginator.PerPage {
+		return 0, false
+	}
+	i := l.Paginator.Page*l.Paginator.PerPage + row
+	if i >= len(l.VisibleItems()) {
+		return 0, false
+	}
+	return i, true
+}
+
+// mouseCmd sends the click or the wheel to the pane under the mouse. While a dialog or an overlay is shown, only it takes the mouse.
+func (m AppModel) mouseCmd(msg tea.MouseMsg) tea.Cmd {
+	if msg.Action != tea.MouseActionPress {
+		return nil
+	}
+	lines := 0
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		lines = -1
+	case tea.MouseButtonWheelDown:
+		lines = 1
+	}
+	if lines == 0 && msg.Button != tea.MouseButtonLeft {
+		return nil
+	}
+
+	var pane, name string
+	var z zone
+	pdw, _ := m.pdialog.GetSize()
+	cdw, _ := m.cdialog.GetSize()
+	switch {
+	case pdw > 0:
+		return nil
+	case cdw > 0:
+		pane, z = paneDialog, m.zones.dialog
+	case m.zones.managers.contains(msg.X, msg.Y):
+		pane, z = keyPaneManagers, m.zones.managers
+	case m.zones.packages.contains(msg.X, msg.Y) && !m.scan.IsShown():
+		pane, name, z = keyPanePackages, m.selectedPkg, m.zones.packages
+	case m.zones.logs.contains(msg.X, msg.Y):
+		pane, z = keyPaneLogs, m.zones.logs
+		lines *= logWheelLines
+	default:
+		return nil
+	}
+	if !z.contains(msg.X, msg.Y) {
+		return nil
+	}
+
+	if lines != 0 {
+		return func() tea.Msg { return scrollMsg{pane: pane, name: name, lines: lines} }
+	}
+	x, y := msg.X-z.x, msg.Y-z.y
+	return func() tea.Msg { return clickMsg{pane: pane, name: name, x: x, y: y} }
+}
+
+// clickListItem moves the cursor of the package list to the clicked row and toggles the check of the package.
+// It reports whether the row is an item.
+func clickListItem(l *list.Model, selection map[string]bool, row int) bool {
+	i, ok := listItemAt(*l, row)
+	if !ok {
+		return false
+	}
+	l.Select(i)
+	// group headers are not selectable
+	if k := l.SelectedItem().FilterValue(); k != "" {
+		selection[k] = !selection[k]
+	}
+	return true
+}
+
+// scrollList moves the cursor of the list by the lines
+func scrollList(l *list.Model, lines int) {
+	for ; lines < 0; lines++ {
+		l.CursorUp()
+	}
+	for ; lines > 0; lines-- {
+		l.CursorDown()
+	}
+}
@@ -0,0 +1,167 @@
+package components
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/ymtdzzz/lazypkg/executors"
+)
+
+func newMouseTestModel(t *testing.T) AppModel {
+	t.Helper()
+	m, err := NewAppModel(NewConfig(false, nil, nil, true, ""))
+	assert.NoError(t, err)
+	tm, _ := m.Update(tea.WindowSizeMsg{Width: 150, Height: 40})
+	return tm.(AppModel)
+}
+
+func TestMouseCmd(t *testing.T) {
+	m := newMouseTestModel(t)
+	mz, pz, lz := m.zones.managers, m.zones.packages, m.zones.logs
+
+	tests := []struct {
+		name string
+		msg  tea.MouseMsg
+		want tea.Msg
+	}{
+		{
+			name: "click on a manager",
+			msg:  tea.MouseMsg{X: mz.x + 3, Y: mz.y + 3, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress},
+			want: clickMsg{pane: keyPaneManagers, x: 3, y: 3},
+		},
+		{
+			name: "click on a package",
+			msg:  tea.MouseMsg{X: pz.x, Y: pz.y + 2, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress},
+			want: clickMsg{pane: keyPanePackages, name: PACKAGE_MANAGER_ALL, x: 0, y: 2},
+		},
+		{
+			name: "wheel over the packages",
+			msg:  tea.MouseMsg{X: pz.x + 1, Y: pz.y + 1, Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress},
+			want: scrollMsg{pane: keyPanePackages, name: PACKAGE_MANAGER_ALL, lines: 1},
+		},
+		{
+			name: "wheel over the logs",
+			msg:  tea.MouseMsg{X: lz.x + 1, Y: lz.y + 1, Button: tea.MouseButtonWheelUp, Action: tea.MouseActionPress},
+			want: scrollMsg{pane: keyPaneLogs, lines: -logWheelLines},
+		},
+		{
+			name: "release",
+			msg:  tea.MouseMsg{X: mz.x, Y: mz.y, Button: tea.MouseButtonLeft, Action: tea.MouseActionRelease},
+		},
+		{
+			name: "right click",
+			msg:  tea.MouseMsg{X: mz.x, Y: mz.y, Button: tea.MouseButtonRight, Action: tea.MouseActionPress},
+		},
+		{
+			name: "margin",
+			msg:  tea.MouseMsg{X: 0, Y: 0, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := m.mouseCmd(tt.msg)
+			if tt.want == nil {
+				assert.Nil(t, cmd)
+				return
+			}
+			assert.Equal(t, tt.want, cmd())
+		})
+	}
+}
+
+func TestMouseCmdDialog(t *testing.T) {
+	m := newMouseTestModel(t)
+	tm, _ := m.Update(showDialogMsg{msg: "All packages will be updated"})
+	tm, _ = tm.Update(UpdateLayoutMsg{})
+	m = tm.(AppModel)
+	dz := m.zones.dialog
+
+	// the panes do not take the clicks while the dialog is shown
+	mz := m.zones.managers
+	assert.Nil(t, m.mouseCmd(tea.MouseMsg{X: mz.x, Y: mz.y + 2, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}))
+
+	cmd := m.mouseCmd(tea.MouseMsg{X: dz.x + 1, Y: dz.y + 2, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	assert.Equal(t, clickMsg{pane: paneDialog, x: 1, y: 2}, cmd())
+}
+
+func TestListItemAt(t *testing.T) {
+	pm := NewPackageModel(Config{}, PACKAGE_MANAGER_APT, ICON_APT, &executors.AptExecutor{})
+	pm.SetSize(40, 6)
+	pm, _ = pm.Update(packageUpdateMsg{name: PACKAGE_MANAGER_APT, pkgs: []*executors.PackageInfo{
+		{Name: "curl"}, {Name: "git"}, {Name: "vim"}, {Name: "zsh"}, {Name: "tmux"},
+	}})
+
+	tests := []struct {
+		row    int
+		want   int
+		wantOk bool
+	}{
+		{row: 0},
+		{row: 1},
+		{row: 2, want: 0, wantOk: true},
+		{row: 4, want: 2, wantOk: true},
+		// below the rows of the page
+		{row: 10},
+	}
+	for _, tt := range tests {
+		got, ok := listItemAt(pm.list, tt.row)
+		assert.Equal(t, tt.wantOk, ok, "row %d", tt.row)
+		assert.Equal(t, tt.want, got, "row %d", tt.row)
+	}
+
+	pm.list.Select(4)
+	got, ok := listItemAt(pm.list, 2)
+	assert.True(t, ok)
+	assert.Equal(t, pm.list.Paginator.PerPage, got)
+}
+
+func TestPackagesClick(t *testing.T) {
+	pm := NewPackageModel(Config{}, PACKAGE_MANAGER_APT, ICON_APT, &executors.AptExecutor{})
+	pm.SetSize(40, 10)
+	pm, _ = pm.Update(packageUpdateMsg{name: PACKAGE_MANAGER_APT, pkgs: []*executors.PackageInfo{
+		{Name: "curl"}, {Name: "git"}, {Name: "vim"},
+	}})
+
+	pm, _ = pm.Update(clickMsg{pane: keyPanePackages, name: PACKAGE_MANAGER_APT, y: 3})
+	assert.Equal(t, 1, pm.list.Index())
+	assert.Equal(t, map[string]bool{"git": true}, pm.selection)
+
+	pm, _ = pm.Update(clickMsg{pane: keyPanePackages, name: PACKAGE_MANAGER_APT, y: 3})
+	assert.Equal(t, map[string]bool{"git": false}, pm.selection)
+
+	// the click on another manager's list is ignored
+	pm, _ = pm.Update(clickMsg{pane: keyPanePackages, name: PACKAGE_MANAGER_NPM, y: 2})
+	assert.Equal(t, 1, pm.list.Index())
+
+	pm, _ = pm.Update(scrollMsg{pane: keyPanePackages, name: PACKAGE_MANAGER_APT, lines: 1})
+	assert.Equal(t, 2, pm.list.Index())
+}
+
+func TestConfirmButtonAt(t *testing.T) {
+	m := NewConfirmModel()
+	m, _ = m.Update(showDialogMsg{msg: "All packages will be updated"})
+
+	lines := strings.Split(m.View(), "\n")
+	row := -1
+	for i, l := range lines {
+		if strings.Contains(l, confirmOKButton) {
+			row = i
+		}
+	}
+	assert.NotEqual(t, -1, row)
+	ok := strings.Index(lines[row], confirmOKButton)
+	okX := len([]rune(lines[row][:ok]))
+	cancelX := okX + len(confirmOKButton) + 2
+
+	assert.Equal(t, confirmOKButton, m.buttonAt(okX, row))
+	assert.Equal(t, confirmOKButton, m.buttonAt(okX+len(confirmOKButton)-1, row))
+	assert.Equal(t, "", m.buttonAt(okX+len(confirmOKButton), row))
+	assert.Equal(t, confirmCancelButton, m.buttonAt(cancelX, row))
+	assert.Equal(t, "", m.buttonAt(okX, row-1))
+
+	m, _ = m.Update(clickMsg{pane: paneDialog, x: cancelX, y: row})
+	assert.False(t, m.show)
+}
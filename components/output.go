@@ -2,17 +2,34 @@ package components
 
 import (
 	"container/ring"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// logLines are the last logs shown in the pane, while the session log keeps every log
+	logLines = 200
+	// logExportLayout is the time in the name of the exported session log
+	logExportLayout = "20060102-150405"
 )
 
 type LogWriter struct {
-	mu   sync.Mutex
-	logs *ring.Ring
+	mu      sync.Mutex
+	logs    *ring.Ring
+	session strings.Builder
+	// file is the file of --log-file, which gets every log as well
+	file *os.File
 }
 
 func (w *LogWriter) Write(b []byte) (n int, err error) {
@@ -20,11 +37,41 @@ func (w *LogWriter) Write(b []byte) (n int, err error) {
 	defer w.mu.Unlock()
 	w.logs.Value = string(b)
 	w.logs = w.logs.Next()
+	w.session.Write(b)
+	if w.file != nil {
+		return w.file.Write(b)
+	}
 
 	return len(b), nil
 }
 
+// OpenFile appends the logs written from now on to the file
+func (w *LogWriter) OpenFile(path string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) // #nosec G304: path is given by the user
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.file = f
+	return nil
+}
+
+// Close closes the file of the logs, if any
+func (w *LogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
 func (w *LogWriter) getLog() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
 	var sb strings.Builder
 	w.logs.Do(func(p any) {
 		if p != nil {
@@ -35,13 +82,30 @@ func (w *LogWriter) getLog() string {
 	return sb.String()
 }
 
+// getSessionLog returns every log written since the start
+func (w *LogWriter) getSessionLog() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.session.String()
+}
+
 type outputKeyMap struct {
-	up   key.Binding
-	down key.Binding
+	up        key.Binding
+	down      key.Binding
+	pageUp    key.Binding
+	pageDown  key.Binding
+	search    key.Binding
+	nextMatch key.Binding
+	prevMatch key.Binding
+	follow    key.Binding
+	export    key.Binding
+	// confirm and cancel end the search being typed
+	confirm key.Binding
+	cancel  key.Binding
 }
 
-func newOutputKeyMap() outputKeyMap {
-	return outputKeyMap{
+func newOutputKeyMap(keys KeyConfig) outputKeyMap {
+	km := outputKeyMap{
 		up: key.NewBinding(
 			key.WithKeys("ctrl+k"),
 			key.WithHelp("ctrl+k", "[Logs] up"),
@@ -50,7 +114,64 @@ func newOutputKeyMap() outputKeyMap {
 			key.WithKeys("ctrl+j"),
 			key.WithHelp("ctrl+j", "[Logs] down"),
 		),
+		pageUp: key.NewBinding(
+			key.WithKeys("shift+up"),
+			key.WithHelp("shift+↑", "[Logs] page up"),
+		),
+		pageDown: key.NewBinding(
+			key.WithKeys("shift+down"),
+			key.WithHelp("shift+↓", "[Logs] page down"),
+		),
+		search: key.NewBinding(
+			key.WithKeys("ctrl+f"),
+			key.WithHelp("ctrl+f", "[Logs] search"),
+		),
+		nextMatch: key.NewBinding(
+			key.WithKeys("ctrl+n"),
+			key.WithHelp("ctrl+n", "[Logs] next match"),
+		),
+		prevMatch: key.NewBinding(
+			key.WithKeys("ctrl+p"),
+			key.WithHelp("ctrl+p", "[Logs] previous match"),
+		),
+		follow: key.NewBinding(
+			key.WithKeys("ctrl+t"),
+			key.WithHelp("ctrl+t", "[Logs] follow new logs"),
+		),
+		export: key.NewBinding(
+			key.WithKeys("ctrl+e"),
+			key.WithHelp("ctrl+e", "[Logs] export the session log"),
+		),
+		confirm: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "keep the search"),
+		),
+		cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "clear the search"),
+		),
 	}
+	remapKeys(keys, keyPaneLogs, km.bindings())
+	return km
+}
+
+func (km *outputKeyMap) bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":         &km.up,
+		"down":       &km.down,
+		"page_up":    &km.pageUp,
+		"page_down":  &km.pageDown,
+		"search":     &km.search,
+		"next_match": &km.nextMatch,
+		"prev_match": &km.prevMatch,
+		"follow":     &km.follow,
+		"export":     &km.export,
+	}
+}
+
+// logMatch is a match of the search, at the bytes [start, end) of the line
+type logMatch struct {
+	line, start, end int
 }
 
 type OutputModel struct {
@@ -58,6 +179,13 @@ type OutputModel struct {
 	viewport viewport.Model
 	writer   *LogWriter
 	content  string
+	// follow keeps the newest logs in view as they are written
+	follow bool
+	// input is the search being typed, and query the search highlighted in the logs
+	input   textinput.Model
+	query   string
+	matches []logMatch
+	current int
 }
 
 func newViewPort(w, h int) viewport.Model {
@@ -66,14 +194,19 @@ func newViewPort(w, h int) viewport.Model {
 	return vp
 }
 
-func NewOutputModel() OutputModel {
-	r := ring.New(200)
+func NewOutputModel(keys KeyConfig) OutputModel {
+	r := ring.New(logLines)
+	input := textinput.New()
+	input.Prompt = "/"
+
 	return OutputModel{
-		keyMap:   newOutputKeyMap(),
+		keyMap:   newOutputKeyMap(keys),
 		viewport: newViewPort(0, 0),
 		writer: &LogWriter{
 			logs: r,
 		},
+		follow: true,
+		input:  input,
 	}
 }
 
@@ -85,14 +218,106 @@ func (m *OutputModel) setContent() {
 	prev := m.content
 	m.content = m.writer.getLog()
 	if prev != m.content {
-		m.viewport.SetContent(m.content)
+		m.matches = findMatches(m.content, m.query)
+		m.current = min(m.current, max(len(m.matches)-1, 0))
+		m.render()
+	}
+}
+
+// render shows the logs with the matches highlighted
+func (m *OutputModel) render() {
+	m.viewport.SetContent(m.highlight())
+	if m.follow {
 		m.viewport.GotoBottom()
 	}
 }
 
+func (m OutputModel) highlight() string {
+	if len(m.matches) == 0 {
+		return m.content
+	}
+	lines := strings.Split(m.content, "\n")
+	// from the last match so that the offsets of the previous ones stay valid
+	for i := len(m.matches) - 1; i >= 0; i-- {
+		match := m.matches[i]
+		style := searchMatchStyle
+		if i == m.current {
+			style = currentMatchStyle
+		}
+		l := lines[match.line]
+		lines[match.line] = l[:match.start] + style.Render(l[match.start:match.end]) + l[match.end:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// findMatches returns the matches of the query in the lines of the content, ignoring the case
+func findMatches(content, query string) []logMatch {
+	if query == "" {
+		return nil
+	}
+	var matches []logMatch
+	for i, line := range strings.Split(content, "\n") {
+		l, q := strings.ToLower(line), strings.ToLower(query)
+		if len(l) != len(line) || len(q) != len(query) {
+			// a few letters change their length in lower case, which would move the offsets
+			l, q = line, query
+		}
+		for start := 0; ; {
+			j := strings.Index(l[start:], q)
+			if j < 0 {
+				break
+			}
+			matches = append(matches, logMatch{line: i, start: start + j, end: start + j + len(q)})
+			start += j + len(q)
+		}
+	}
+	return matches
+}
+
+// jump moves to the match by the offset from the current one, wrapping around.
+// The view stops following the new logs so that the match stays in view.
+func (m *OutputModel) jump(offset int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.current = (m.current + offset + len(m.matches)) % len(m.matches)
+	m.follow = false
+	m.render()
+	line := m.matches[m.current].line
+	if line < m.viewport.YOffset || line >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(line - m.viewport.Height/2)
+	}
+}
+
+// searchFromView finds the query and jumps to its first match in or below the view
+func (m *OutputModel) searchFromView() {
+	m.query = m.input.Value()
+	m.matches = findMatches(m.content, m.query)
+	m.current = 0
+	for i, match := range m.matches {
+		if match.line >= m.viewport.YOffset {
+			m.current = i
+			break
+		}
+	}
+	if len(m.matches) == 0 {
+		m.render()
+		return
+	}
+	m.jump(0)
+}
+
 func (m *OutputModel) SetSize(w, h int) {
-	m.viewport = newViewPort(w, h)
-	m.viewport.SetContent(m.content)
+	m.viewport.Width = w
+	// the status line is below the logs
+	m.viewport.Height = max(h-1, 0)
+	m.input.Width = max(w-2, 0)
+	m.render()
+}
+
+// IsSearching reports whether the search is being typed, which takes the keys
+func (m OutputModel) IsSearching() bool {
+	return m.input.Focused()
 }
 
 func (m OutputModel) Init() tea.Cmd {
@@ -103,35 +328,161 @@ func (m OutputModel) Update(msg tea.Msg) (OutputModel, tea.Cmd) {
 	var cmd tea.Cmd
 	m.setContent()
 
+	scrolled := false
 	switch msg := msg.(type) {
+	case scrollMsg:
+		if msg.pane != keyPaneLogs {
+			break
+		}
+		if msg.lines < 0 {
+			m.viewport.ScrollUp(-msg.lines)
+		} else {
+			m.viewport.ScrollDown(msg.lines)
+		}
+		scrolled = true
 	case tea.KeyMsg:
+		if m.IsSearching() {
+			return m.updateSearch(msg)
+		}
 		switch {
 		case key.Matches(msg, m.keyMap.down):
 			m.viewport.ScrollDown(1)
+			scrolled = true
 		case key.Matches(msg, m.keyMap.up):
 			m.viewport.ScrollUp(1)
+			scrolled = true
+		case key.Matches(msg, m.keyMap.pageDown):
+			m.viewport.PageDown()
+			scrolled = true
+		case key.Matches(msg, m.keyMap.pageUp):
+			m.viewport.PageUp()
+			scrolled = true
+		case key.Matches(msg, m.keyMap.search):
+			m.input.SetValue(m.query)
+			m.input.CursorEnd()
+			return m, m.input.Focus()
+		case key.Matches(msg, m.keyMap.nextMatch):
+			m.jump(1)
+		case key.Matches(msg, m.keyMap.prevMatch):
+			m.jump(-1)
+		case key.Matches(msg, m.keyMap.follow):
+			m.follow = !m.follow
+			m.render()
+		case key.Matches(msg, m.keyMap.export):
+			return m, exportLogCmd(m.writer.getSessionLog())
 		}
 	}
 
 	m.viewport, cmd = m.viewport.Update(msg)
+	if scrolled {
+		// scrolling away from the newest logs keeps the view in place, and scrolling back to them follows them again
+		m.follow = m.viewport.AtBottom()
+	}
 
 	return m, cmd
 }
 
+// updateSearch searches the logs as the query is typed
+func (m OutputModel) updateSearch(msg tea.KeyMsg) (OutputModel, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch {
+	case key.Matches(msg, m.keyMap.confirm):
+		m.input.Blur()
+	case key.Matches(msg, m.keyMap.cancel):
+		m.input.Blur()
+		m.input.Reset()
+		m.searchFromView()
+	case key.Matches(msg, m.keyMap.nextMatch):
+		m.jump(1)
+	case key.Matches(msg, m.keyMap.prevMatch):
+		m.jump(-1)
+	default:
+		m.input, cmd = m.input.Update(msg)
+		if m.input.Value() != m.query {
+			m.searchFromView()
+		}
+	}
+
+	return m, cmd
+}
+
+// exportLogCmd writes the logs of the whole session to a file in the working directory
+func exportLogCmd(session string) tea.Cmd {
+	return func() tea.Msg {
+		name := fmt.Sprintf("lazypkg-%s.log", time.Now().Format(logExportLayout))
+		if err := os.WriteFile(name, []byte(session), 0o600); err != nil {
+			log.Printf("Error exporting the session log: %v", err)
+			return nil
+		}
+		if abs, err := filepath.Abs(name); err == nil {
+			name = abs
+		}
+		log.Printf("Exported the session log to %s", name)
+		return nil
+	}
+}
+
 func (m OutputModel) View() string {
-	return m.viewport.View()
+	// the pane is too short for the logs
+	if m.viewport.Height == 0 {
+		return m.statusView()
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, m.viewport.View(), m.statusView())
+}
+
+// statusView shows the search and whether the new logs are followed
+func (m OutputModel) statusView() string {
+	var parts []string
+	switch {
+	case m.IsSearching():
+		parts = append(parts, m.input.View())
+	case m.query != "":
+		parts = append(parts, "/"+m.query)
+	}
+	if m.query != "" {
+		if len(m.matches) == 0 {
+			parts = append(parts, itemDescStyle.Render("no matches"))
+		} else {
+			parts = append(parts, itemDescStyle.Render(fmt.Sprintf("%d/%d", m.current+1, len(m.matches))))
+		}
+	}
+	if !m.follow {
+		parts = append(parts, itemDescStyle.Render("not following new logs"))
+	}
+	return strings.Join(parts, "  ")
 }
 
 func (m OutputModel) ShortHelp() []key.Binding {
+	if m.IsSearching() {
+		return []key.Binding{
+			m.keyMap.confirm,
+			m.keyMap.cancel,
+			m.keyMap.nextMatch,
+			m.keyMap.prevMatch,
+		}
+	}
 	return []key.Binding{
 		m.keyMap.up,
 		m.keyMap.down,
+		m.keyMap.search,
 	}
 }
 
 func (m OutputModel) FullHelp() [][]key.Binding {
-	return [][]key.Binding{{
-		m.keyMap.up,
-		m.keyMap.down,
-	}}
+	return [][]key.Binding{
+		{
+			m.keyMap.up,
+			m.keyMap.down,
+			m.keyMap.pageUp,
+			m.keyMap.pageDown,
+		},
+		{
+			m.keyMap.search,
+			m.keyMap.nextMatch,
+			m.keyMap.prevMatch,
+			m.keyMap.follow,
+			m.keyMap.export,
+		},
+	}
 }
@@ -0,0 +1,192 @@
+package components
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lazypkg.log")
+	m := NewOutputModel(nil)
+	w := m.GetLogWriter()
+
+	fmt.Fprintln(w, "before the file")
+	assert.NoError(t, w.OpenFile(path))
+	for i := range logLines + 50 {
+		fmt.Fprintf(w, "log %d\n", i)
+	}
+	assert.NoError(t, w.Close())
+
+	shown := strings.Split(strings.TrimSuffix(w.getLog(), "\n"), "\n")
+	assert.Len(t, shown, logLines)
+	assert.Equal(t, "log 50", shown[0])
+
+	session := w.getSessionLog()
+	assert.True(t, strings.HasPrefix(session, "before the file\nlog 0\n"))
+	assert.Equal(t, logLines+51, strings.Count(session, "\n"))
+
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.TrimPrefix(session, "before the file\n"), string(b))
+}
+
+func TestFindMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		query   string
+		want    []logMatch
+	}{
+		{
+			name:    "no query",
+			content: "error\n",
+		},
+		{
+			name:    "ignoring the case",
+			content: "Updating apt\nError: apt failed, error 100\n",
+			query:   "error",
+			want: []logMatch{
+				{line: 1, start: 0, end: 5},
+				{line: 1, start: 19, end: 24},
+			},
+		},
+		{
+			name:    "no matches",
+			content: "Updating apt\n",
+			query:   "npm",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, findMatches(tt.content, tt.query))
+		})
+	}
+}
+
+func TestOutputSearch(t *testing.T) {
+	m := NewOutputModel(nil)
+	m.SetSize(40, 6)
+	for i := range 20 {
+		if i%5 == 0 {
+			fmt.Fprintf(m.GetLogWriter(), "error %d\n", i)
+		} else {
+			fmt.Fprintf(m.GetLogWriter(), "log %d\n", i)
+		}
+	}
+	m, _ = m.Update(nil)
+	assert.True(t, m.viewport.AtBottom())
+
+	press := func(keys ...tea.KeyMsg) {
+		for _, k := range keys {
+			m, _ = m.Update(k)
+		}
+	}
+	runes := func(s string) tea.KeyMsg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyCtrlF})
+	assert.True(t, m.IsSearching())
+	press(runes("e"), runes("r"), runes("r"))
+	assert.Equal(t, "err", m.query)
+	assert.Len(t, m.matches, 4)
+	assert.False(t, m.follow)
+	assert.Contains(t, m.View(), "/err")
+
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.False(t, m.IsSearching())
+	current := m.current
+	press(tea.KeyMsg{Type: tea.KeyCtrlN})
+	assert.Equal(t, (current+1)%4, m.current)
+	line := m.matches[m.current].line
+	assert.True(t, line >= m.viewport.YOffset && line < m.viewport.YOffset+m.viewport.Height)
+
+	// the view stays while new logs are written without following them
+	offset := m.viewport.YOffset
+	fmt.Fprintln(m.GetLogWriter(), "log 20")
+	m, _ = m.Update(nil)
+	assert.Equal(t, offset, m.viewport.YOffset)
+	assert.Contains(t, m.View(), "not following new logs")
+
+	press(tea.KeyMsg{Type: tea.KeyCtrlT})
+	assert.True(t, m.viewport.AtBottom())
+
+	press(tea.KeyMsg{Type: tea.KeyCtrlF}, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, m.IsSearching())
+	assert.Equal(t, "", m.query)
+	assert.Empty(t, m.matches)
+}
+
+func TestOutputScrollFollow(t *testing.T) {
+	m := NewOutputModel(nil)
+	m.SetSize(40, 6)
+	write := func(s string) {
+		fmt.Fprintln(m.GetLogWriter(), s)
+		m, _ = m.Update(nil)
+	}
+	for i := range 20 {
+		write(fmt.Sprintf("log %d", i))
+	}
+	assert.True(t, m.follow)
+
+	tests := []struct {
+		name   string
+		scroll tea.Msg
+		back   tea.Msg
+	}{
+		{"key", tea.KeyMsg{Type: tea.KeyCtrlK}, tea.KeyMsg{Type: tea.KeyCtrlJ}},
+		{"page", tea.KeyMsg{Type: tea.KeyShiftUp}, tea.KeyMsg{Type: tea.KeyShiftDown}},
+		{"mouse wheel", scrollMsg{pane: keyPaneLogs, lines: -3}, scrollMsg{pane: keyPaneLogs, lines: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ = m.Update(tt.scroll)
+			assert.False(t, m.follow)
+			offset := m.viewport.YOffset
+
+			// a new log does not move the view back to the bottom
+			write("new log")
+			assert.Equal(t, offset, m.viewport.YOffset)
+			assert.Contains(t, m.View(), "not following new logs")
+
+			// scrolling to the bottom follows the logs again
+			for !m.viewport.AtBottom() {
+				m, _ = m.Update(tt.back)
+			}
+			assert.True(t, m.follow)
+			write("newer log")
+			assert.True(t, m.viewport.AtBottom())
+		})
+	}
+}
+
+func TestExportLogCmd(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	assert.Nil(t, exportLogCmd("log 0\nlog 1\n")())
+	files, err := filepath.Glob("lazypkg-*.log")
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	b, err := os.ReadFile(files[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "log 0\nlog 1\n", string(b))
+}
+
+func TestAppLogSearchKeys(t *testing.T) {
+	m := newMouseTestModel(t)
+
+	tm, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	m = tm.(AppModel)
+	// the key is typed into the search instead of quitting
+	assert.True(t, m.out.IsSearching())
+	assert.Equal(t, "q", m.out.query)
+	assert.Contains(t, m.View(), "keep the search")
+}
@@ -3,11 +3,15 @@ package components
 import (
 	"fmt"
 	"log"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/ymtdzzz/lazypkg/executors"
 )
 
@@ -16,10 +20,22 @@ type packagesKeyMap struct {
 	Back      key.Binding
 	Update    key.Binding
 	UpdateAll key.Binding
+	Detail    key.Binding
+	Changelog key.Binding
+	Security  key.Binding
+	Prune     key.Binding
+	Sort      key.Binding
+	Group     key.Binding
+	// Retry updates the packages whose last update failed, and ErrorOutput shows why it failed
+	Retry       key.Binding
+	ErrorOutput key.Binding
+	// SavePlan and Plans are the keys of the combined list
+	SavePlan key.Binding
+	Plans    key.Binding
 }
 
-func newPackagesKeyMap() packagesKeyMap {
-	return packagesKeyMap{
+func newPackagesKeyMap(keys KeyConfig) packagesKeyMap {
+	km := packagesKeyMap{
 		Toggle: key.NewBinding(
 			key.WithKeys(" "),
 			key.WithHelp("space", "toggle check"),
@@ -36,6 +52,67 @@ func newPackagesKeyMap() packagesKeyMap {
 			key.WithKeys("a"),
 			key.WithHelp("a", "update all"),
 		),
+		Detail: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "details"),
+		),
+		Changelog: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "changelog"),
+		),
+		Security: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "update security only"),
+		),
+		Prune: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "prune images"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "cycle sort"),
+		),
+		Group: key.NewBinding(
+			key.WithKeys("g"),
+			key.WithHelp("g", "toggle groups"),
+		),
+		Retry: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "retry failed"),
+		),
+		ErrorOutput: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "error output"),
+		),
+		SavePlan: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "save plan"),
+		),
+		Plans: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "plans"),
+		),
+	}
+	remapKeys(keys, keyPanePackages, km.bindings())
+	return km
+}
+
+func (km *packagesKeyMap) bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"toggle":       &km.Toggle,
+		"back":         &km.Back,
+		"update":       &km.Update,
+		"update_all":   &km.UpdateAll,
+		"detail":       &km.Detail,
+		"changelog":    &km.Changelog,
+		"security":     &km.Security,
+		"prune":        &km.Prune,
+		"sort":         &km.Sort,
+		"group":        &km.Group,
+		"retry_failed": &km.Retry,
+		"error_output": &km.ErrorOutput,
+		"save_plan":    &km.SavePlan,
+		"plans":        &km.Plans,
 	}
 }
 
@@ -47,12 +124,19 @@ type PackagesModel struct {
 	name       string
 	icon       rune
 	executor   executors.Executor
-	pkgToIdx   map[string]int
-	idxToPkg   map[int]string
 	list       list.Model
 	focus      *bool
-	selection  map[int]bool
-	loading    map[int]bool
+	selection  map[string]bool
+	loading    map[string]bool
+	// failures are the errors of the packages whose last update failed
+	failures   map[string]error
+	details    *detailCache
+	showDetail bool
+	changelog  ChangelogModel
+	prune      *PruneModel
+	sortMode   sortMode
+	group      bool
+	w, h       int
 }
 
 func NewPackageModel(config Config, name string, icon rune, executor executors.Executor) PackagesModel {
@@ -60,31 +144,47 @@ func NewPackageModel(config Config, name string, icon rune, executor executors.E
 	s.Spinner = spinner.Line
 	ss := s.View()
 
-	selection := map[int]bool{}
-	loading := map[int]bool{}
+	selection := map[string]bool{}
+	loading := map[string]bool{}
+	failures := map[string]error{}
 	focus := false
+	delegate := newItemDelegate(&ss, selection, loading, &focus)
+	if reporter, ok := executor.(executors.ProgressReporter); ok {
+		delegate.progress = reporter.Progress
+	}
+	delegate.failed = func(name string) bool {
+		_, ok := failures[name]
+		return ok
+	}
 	l := list.New(
 		[]list.Item{},
-		newItemDelegate(&ss, selection, loading, &focus),
+		delegate,
 		0,
 		0,
 	)
-	l.Title = fmt.Sprintf("Packages [%s]", name)
+	lc := config.File.Lists[name]
+	group := lc.Group == nil || *lc.Group
+	l.Title = listTitle(name, parseSortMode(lc.Sort))
 	l.SetShowStatusBar(false)
-	l.SetFilteringEnabled(false)
+	l.SetFilteringEnabled(true)
 	l.SetShowHelp(false)
+	l.KeyMap = newPackagesListKeyMap(config.File.Keys)
 	l.DisableQuitKeybindings()
-	// Disable next/prev page in paginator
-	l.KeyMap.NextPage = key.Binding{}
-	l.KeyMap.PrevPage = key.Binding{}
 	l.Styles.Title = blurTitleStyle
 	l.Styles.HelpStyle = helpStyle
-	km := newPackagesKeyMap()
+	km := newPackagesKeyMap(config.File.Keys)
+	keys := []key.Binding{km.Toggle, km.Back, km.Update, km.UpdateAll, km.Security, km.Retry, km.ErrorOutput, km.Detail, km.Changelog, km.Sort, km.Group}
+	var prune *PruneModel
+	if pruner, ok := executor.(executors.ImagePruner); ok {
+		pm := NewPruneModel(name, pruner, config.DryRun, config.File.Keys)
+		prune = &pm
+		keys = append(keys, km.Prune)
+	}
 	l.AdditionalShortHelpKeys = func() []key.Binding {
-		return []key.Binding{km.Toggle, km.Back, km.Update, km.UpdateAll}
+		return keys
 	}
 	l.AdditionalFullHelpKeys = func() []key.Binding {
-		return []key.Binding{km.Toggle, km.Back, km.Update, km.UpdateAll}
+		return keys
 	}
 
 	return PackagesModel{
@@ -95,15 +195,27 @@ func NewPackageModel(config Config, name string, icon rune, executor executors.E
 		spinner:    s,
 		spinnerStr: &ss,
 		executor:   executor,
-		pkgToIdx:   map[string]int{},
-		idxToPkg:   map[int]string{},
 		list:       l,
 		selection:  selection,
 		loading:    loading,
+		failures:   failures,
 		focus:      &focus,
+		details:    newDetailCache(),
+		changelog:  NewChangelogModel(config.File.Keys),
+		prune:      prune,
+		sortMode:   parseSortMode(lc.Sort),
+		group:      group,
 	}
 }
 
+// listTitle shows the sort mode unless the packages are sorted by name
+func listTitle(name string, mode sortMode) string {
+	if mode == sortByName {
+		return fmt.Sprintf("Packages [%s]", name)
+	}
+	return fmt.Sprintf("Packages [%s] (sort: %s)", name, mode)
+}
+
 func (m PackagesModel) Valid() bool {
 	return m.executor.Valid()
 }
@@ -120,61 +232,131 @@ func (m PackagesModel) Update(msg tea.Msg) (PackagesModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case packageUpdateMsg:
 		if msg.name == m.name {
-			pkgToIdx := map[string]int{}
-			idxToPkg := map[int]string{}
 			for k := range m.selection {
 				delete(m.selection, k)
 			}
-			for i, item := range msg.items {
-				pkgToIdx[item.FilterValue()] = i
-				idxToPkg[i] = item.FilterValue()
-			}
-			m.pkgToIdx = pkgToIdx
-			m.idxToPkg = idxToPkg
-			return m, tea.Sequence(
-				m.list.SetItems(msg.items),
+			m.keepFailures(msg.pkgs)
+			m.details.clear()
+			cmd := tea.Sequence(
+				m.list.SetItems(getPackageItems(sortPackages(msg.pkgs, m.sortMode, m.group), m.group)),
 				func() tea.Msg {
 					return getPackageFinishMsg{name: m.name}
 				},
 			)
+			if m.showDetail {
+				cmd = tea.Batch(cmd, m.requestDetail())
+			}
+			return m, cmd
 		}
 	case updatePackagesStartMsg:
 		if msg.name == m.name {
 			for _, pkg := range msg.pkgs {
-				if i, ok := m.pkgToIdx[pkg]; ok {
-					m.loading[i] = true
-				}
+				m.loading[pkg] = true
 			}
 		}
 	case updatePackagesFinishMsg:
 		if msg.name == m.name {
 			for _, pkg := range msg.pkgs {
-				if i, ok := m.pkgToIdx[pkg]; ok {
-					m.loading[i] = false
+				m.loading[pkg] = false
+				if err, ok := msg.failed[pkg]; ok {
+					m.failures[pkg] = err
+					m.log(fmt.Sprintf("Failed to update %s", pkg))
+				} else if msg.err != executors.ErrPassword {
+					delete(m.failures, pkg)
 				}
 			}
-			cmds = append(cmds, m.getPackagesCmd())
+			// nothing changed when the password input was canceled
+			if msg.err != executors.ErrPassword {
+				cmds = append(cmds, m.getPackagesCmd(), m.recreateContainersCmd(msg.pkgs))
+			}
 		}
 	case updateAllPackagesMsg:
 		if msg.name == m.name {
 			cmds = m.updateAll(cmds, msg.confirmed)
 		}
+	case detailRequestMsg:
+		if msg.name == m.name {
+			if fetcher, ok := m.executor.(executors.DetailFetcher); ok {
+				cmds = append(cmds, m.details.fetch(msg, fetcher))
+			}
+		}
+	case detailFetchedMsg:
+		if msg.name == m.name {
+			m.details.store(msg)
+			if msg.err != nil {
+				m.log(fmt.Sprintf("Error fetching package details: %v", msg.err))
+			}
+		}
+	case changelogFetchedMsg:
+		if msg.name == m.name {
+			m.changelog, cmd = m.changelog.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+	case clickMsg:
+		if msg.pane != keyPanePackages || msg.name != m.name || m.overlayShown() {
+			break
+		}
+		if lw, _ := m.listSize(); msg.x >= lw {
+			break
+		}
+		previous := m.list.Index()
+		if !clickListItem(&m.list, m.selection, msg.y) {
+			break
+		}
+		if !*m.focus {
+			cmds = append(cmds, func() tea.Msg {
+				return FocusPackagesMsg{Name: m.name}
+			})
+		}
+		if m.showDetail && previous != m.list.Index() {
+			cmds = append(cmds, m.requestDetail())
+		}
+	case scrollMsg:
+		if msg.pane != keyPanePackages || msg.name != m.name || m.overlayShown() {
+			break
+		}
+		previous := m.list.Index()
+		scrollList(&m.list, msg.lines)
+		if m.showDetail && previous != m.list.Index() {
+			cmds = append(cmds, m.requestDetail())
+		}
+	case pruneListedMsg, pruneFinishedMsg:
+		if m.prune != nil {
+			*m.prune, cmd = m.prune.Update(msg)
+			cmds = append(cmds, cmd)
+		}
 	case spinner.TickMsg:
 		m.spinner, cmd = m.spinner.Update(msg)
 		*m.spinnerStr = m.spinner.View()
 		return m, cmd
 	}
 
+	if *m.focus && m.prune != nil && m.prune.IsShown() {
+		if _, ok := msg.(tea.KeyMsg); ok {
+			*m.prune, cmd = m.prune.Update(msg)
+			return m, tea.Batch(append(cmds, cmd)...)
+		}
+	}
+
+	if *m.focus && m.changelog.IsShown() {
+		if _, ok := msg.(tea.KeyMsg); ok {
+			m.changelog, cmd = m.changelog.Update(msg)
+			return m, tea.Batch(append(cmds, cmd)...)
+		}
+	}
+
 	if *m.focus {
 		switch msg := msg.(type) {
 		case tea.KeyMsg:
+			if m.IsFiltering() {
+				// the keys are typed into the filter
+				break
+			}
 			switch {
 			case key.Matches(msg, m.keyMap.Toggle):
-				idx := m.list.Index()
-				if v, ok := m.selection[idx]; v && ok {
-					m.selection[idx] = false
-				} else {
-					m.selection[idx] = true
+				// group headers are not selectable
+				if pkg := m.selectedPackage(); pkg != "" {
+					m.selection[pkg] = !m.selection[pkg]
 				}
 			case key.Matches(msg, m.keyMap.Back):
 				cmds = append(cmds, func() tea.Msg {
@@ -183,30 +365,35 @@ func (m PackagesModel) Update(msg tea.Msg) (PackagesModel, tea.Cmd) {
 			case key.Matches(msg, m.keyMap.Update):
 				// Bulk update
 				var pkgs []string
-				for i, v := range m.selection {
+				for pkg, v := range m.selection {
 					if v {
-						pkgs = append(pkgs, m.idxToPkg[i])
+						pkgs = append(pkgs, pkg)
 					}
 				}
+				sort.Strings(pkgs)
 				if len(pkgs) > 0 {
-					for i := range m.selection {
-						m.selection[i] = false
+					for pkg := range m.selection {
+						m.selection[pkg] = false
 					}
-					cmds = append(cmds, showDialogCmd(
+					cmds = append(cmds, m.confirmUpdateCmd(
 						fmt.Sprintf("Selected %d packages will be updated", len(pkgs)),
+						pkgs,
 						tea.Sequence(
 							func() tea.Msg {
-								return updatePackagesStartMsg{name: m.name, pkgs: pkgs}
+								return updatePackagesStartMsg{name: m.name, pkgs: pkgs, bulk: true}
 							},
 							m.bulkUpdatePackageCmd(pkgs),
 						),
 					))
+				} else if i, ok := m.list.SelectedItem().(item); ok && i.header {
+					cmds = m.updateGroup(cmds, i.title)
 				} else {
 					// Single update
 					if item := m.list.SelectedItem(); item != nil {
 						pkg := item.FilterValue()
-						cmds = append(cmds, showDialogCmd(
+						cmds = append(cmds, m.confirmUpdateCmd(
 							fmt.Sprintf("Package %s will be updated", pkg),
+							[]string{pkg},
 							tea.Sequence(
 								func() tea.Msg {
 									return updatePackagesStartMsg{name: m.name, pkgs: []string{pkg}}
@@ -218,18 +405,77 @@ func (m PackagesModel) Update(msg tea.Msg) (PackagesModel, tea.Cmd) {
 				}
 			case key.Matches(msg, m.keyMap.UpdateAll):
 				cmds = m.updateAll(cmds, false)
+			case key.Matches(msg, m.keyMap.Security):
+				cmds = m.updateSecurity(cmds)
+			case key.Matches(msg, m.keyMap.Retry):
+				cmds = m.retryFailed(cmds)
+			case key.Matches(msg, m.keyMap.ErrorOutput):
+				if pkg := m.selectedPackage(); pkg != "" {
+					cmds = append(cmds, m.errorOutputCmd(pkg))
+				}
+			case key.Matches(msg, m.keyMap.Detail):
+				m.showDetail = !m.showDetail
+				m.SetSize(m.w, m.h)
+				if m.showDetail {
+					cmds = append(cmds, m.requestDetail())
+				}
+			case key.Matches(msg, m.keyMap.Changelog):
+				cmds = append(cmds, m.openChangelog())
+			case key.Matches(msg, m.keyMap.Prune) && m.prune != nil:
+				cmds = append(cmds, m.prune.Open())
+			case key.Matches(msg, m.keyMap.Sort):
+				m.sortMode = m.sortMode.next()
+				m.list.Title = listTitle(m.name, m.sortMode)
+				cmds = append(cmds, m.relist(), m.saveListConfig())
+			case key.Matches(msg, m.keyMap.Group):
+				m.group = !m.group
+				cmds = append(cmds, m.relist(), m.saveListConfig())
 			}
 		}
 
+		previous := m.list.Index()
+
 		m.list, cmd = m.list.Update(msg)
 		cmds = append(cmds, cmd)
+
+		if m.showDetail && previous != m.list.Index() {
+			cmds = append(cmds, m.requestDetail())
+		}
 	}
 
 	return m, tea.Batch(cmds...)
 }
 
 func (m PackagesModel) View() string {
-	return m.list.View()
+	if m.prune != nil && m.prune.IsShown() {
+		return m.prune.View()
+	}
+	if m.changelog.IsShown() {
+		return m.changelog.View()
+	}
+	if !m.showDetail {
+		return m.list.View()
+	}
+
+	lw, _ := m.listSize()
+	var detail string
+	if _, ok := m.executor.(executors.DetailFetcher); ok {
+		detail = m.details.view(m.selectedPackage(), m.w-lw, m.h)
+	} else {
+		detail = detailStyle.Height(m.h).Render("Details are not available for this package manager")
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, m.list.View(), detail)
+}
+
+// overlayShown reports whether the dangling images or the changelog replace the list
+func (m PackagesModel) overlayShown() bool {
+	return (m.prune != nil && m.prune.IsShown()) || m.changelog.IsShown()
+}
+
+// IsFiltering reports whether the filter of the list is being typed
+func (m PackagesModel) IsFiltering() bool {
+	return m.list.FilterState() == list.Filtering
 }
 
 func (m PackagesModel) IsFocus() bool {
@@ -237,15 +483,45 @@ func (m PackagesModel) IsFocus() bool {
 }
 
 func (m PackagesModel) ShortHelp() []key.Binding {
+	if m.prune != nil && m.prune.IsShown() {
+		return m.prune.ShortHelp()
+	}
 	return m.list.ShortHelp()
 }
 
 func (m PackagesModel) FullHelp() [][]key.Binding {
+	if m.prune != nil && m.prune.IsShown() {
+		return m.prune.FullHelp()
+	}
 	return m.list.FullHelp()
 }
 
 func (m PackagesModel) Count() int {
-	return len(m.list.Items())
+	return len(m.packages())
+}
+
+func (m PackagesModel) SecurityCount() int {
+	return len(m.securityPackages())
+}
+
+func (m PackagesModel) packages() []*executors.PackageInfo {
+	var pkgs []*executors.PackageInfo
+	for _, li := range m.list.Items() {
+		if i, ok := li.(item); ok && i.info != nil {
+			pkgs = append(pkgs, i.info)
+		}
+	}
+	return pkgs
+}
+
+func (m PackagesModel) securityPackages() []string {
+	var pkgs []string
+	for _, li := range m.list.Items() {
+		if i, ok := li.(item); ok && i.info != nil && i.info.Security {
+			pkgs = append(pkgs, i.info.Name)
+		}
+	}
+	return pkgs
 }
 
 func (m PackagesModel) Icon() rune {
@@ -253,22 +529,26 @@ func (m PackagesModel) Icon() rune {
 }
 
 func (m PackagesModel) updateAll(cmds []tea.Cmd, confirmed bool) []tea.Cmd {
-	pkgs := make([]string, 0, len(m.pkgToIdx))
-	for k := range m.pkgToIdx {
-		pkgs = append(pkgs, k)
+	var pkgs []string
+	for _, pkg := range m.packages() {
+		// packages which could not be checked have nothing to update to
+		if pkg.CheckError == "" {
+			pkgs = append(pkgs, pkg.Name)
+		}
 	}
 	if len(pkgs) > 0 {
 		cmd := tea.Sequence(
 			func() tea.Msg {
-				return updatePackagesStartMsg{name: m.name, pkgs: pkgs}
+				return updatePackagesStartMsg{name: m.name, pkgs: pkgs, bulk: true}
 			},
 			m.bulkUpdatePackageCmd(pkgs),
 		)
 		if confirmed {
 			cmds = append(cmds, cmd)
 		} else {
-			cmds = append(cmds, showDialogCmd(
+			cmds = append(cmds, m.confirmUpdateCmd(
 				fmt.Sprintf("All %d packages will be updated", len(pkgs)),
+				pkgs,
 				cmd,
 			))
 		}
@@ -277,8 +557,197 @@ func (m PackagesModel) updateAll(cmds []tea.Cmd, confirmed bool) []tea.Cmd {
 	return cmds
 }
 
+// updateGroup updates every package of the group, e.g. all services of a compose project
+func (m PackagesModel) updateGroup(cmds []tea.Cmd, group string) []tea.Cmd {
+	var pkgs []string
+	for _, pkg := range m.packages() {
+		if pkg.Group == group && pkg.CheckError == "" {
+			pkgs = append(pkgs, pkg.Name)
+		}
+	}
+	if len(pkgs) == 0 {
+		return cmds
+	}
+
+	return append(cmds, m.confirmUpdateCmd(
+		fmt.Sprintf("All %d packages of %s will be updated", len(pkgs), group),
+		pkgs,
+		tea.Sequence(
+			func() tea.Msg {
+				return updatePackagesStartMsg{name: m.name, pkgs: pkgs, bulk: true}
+			},
+			m.bulkUpdatePackageCmd(pkgs),
+		),
+	))
+}
+
+func (m PackagesModel) updateSecurity(cmds []tea.Cmd) []tea.Cmd {
+	pkgs := m.securityPackages()
+	if len(pkgs) == 0 {
+		m.log("No security updates found")
+		return cmds
+	}
+
+	return append(cmds, m.confirmUpdateCmd(
+		fmt.Sprintf("%d security updates will be applied", len(pkgs)),
+		pkgs,
+		tea.Sequence(
+			func() tea.Msg {
+				return updatePackagesStartMsg{name: m.name, pkgs: pkgs, bulk: true}
+			},
+			m.bulkUpdatePackageCmd(pkgs),
+		),
+	))
+}
+
+// retryFailed updates the packages whose last update failed again
+func (m PackagesModel) retryFailed(cmds []tea.Cmd) []tea.Cmd {
+	var pkgs []string
+	for pkg := range m.failures {
+		pkgs = append(pkgs, pkg)
+	}
+	if len(pkgs) == 0 {
+		m.log("No failed updates to retry")
+		return cmds
+	}
+	sort.Strings(pkgs)
+	for _, pkg := range pkgs {
+		m.selection[pkg] = false
+	}
+
+	return append(cmds, m.confirmUpdateCmd(
+		fmt.Sprintf("%d failed packages will be updated again", len(pkgs)),
+		pkgs,
+		tea.Sequence(
+			func() tea.Msg {
+				return updatePackagesStartMsg{name: m.name, pkgs: pkgs, bulk: true}
+			},
+			m.bulkUpdatePackageCmd(pkgs),
+		),
+	))
+}
+
+// errorOutputCmd shows why the last update of the package failed
+func (m PackagesModel) errorOutputCmd(pkg string) tea.Cmd {
+	err, ok := m.failures[pkg]
+	if !ok {
+		m.log(fmt.Sprintf("The last update of %s did not fail", pkg))
+		return nil
+	}
+	return showInfoCmd(fmt.Sprintf("The update of %s failed", pkg), renderErrorOutput(err))
+}
+
+// keepFailures forgets the failures of the packages which are not listed anymore,
+// and selects the others so that they are updated again with the update key
+func (m PackagesModel) keepFailures(pkgs []*executors.PackageInfo) {
+	listed := map[string]bool{}
+	for _, pkg := range pkgs {
+		listed[pkg.Name] = true
+	}
+	for pkg := range m.failures {
+		if listed[pkg] {
+			m.selection[pkg] = true
+		} else {
+			delete(m.failures, pkg)
+		}
+	}
+}
+
+// confirmUpdateCmd shows the confirmation dialog along with the changes the update would make
+// when the package manager is able to simulate it
+func (m PackagesModel) confirmUpdateCmd(msg string, pkgs []string, callback tea.Cmd) tea.Cmd {
+	planner, ok := m.executor.(executors.Planner)
+	if !ok {
+		return showDialogCmd(msg, callback)
+	}
+
+	return func() tea.Msg {
+		plan, err := planner.Plan(pkgs)
+		if err != nil {
+			m.log(fmt.Sprintf("Error simulating the update: %v", err))
+			return showDialogCmd(msg, callback)()
+		}
+		return showDialogWithDetailCmd(msg, renderPlan(plan), callback)()
+	}
+}
+
+// recreateContainersCmd asks to recreate the containers left on the images replaced by the update
+func (m PackagesModel) recreateContainersCmd(pkgs []string) tea.Cmd {
+	recreator, ok := m.executor.(executors.ContainerRecreator)
+	if !ok {
+		return nil
+	}
+
+	return func() tea.Msg {
+		containers, err := recreator.StaleContainers(pkgs)
+		if err != nil {
+			m.log(fmt.Sprintf("Error listing containers of the replaced images: %v", err))
+			return nil
+		}
+		if len(containers) == 0 {
+			return nil
+		}
+
+		return showDialogWithDetailCmd(
+			fmt.Sprintf("%d containers still run the replaced images and will be recreated", len(containers)),
+			renderContainers(containers),
+			func() tea.Msg {
+				for _, c := range containers {
+					if err := recreator.Recreate(c, m.config.DryRun); err != nil {
+						m.log(fmt.Sprintf("Error recreating container %s: %v", c.Name, err))
+						continue
+					}
+					m.log(fmt.Sprintf("Recreated container %s with %s", c.Name, c.Image))
+				}
+				return nil
+			},
+		)()
+	}
+}
+
 func (m *PackagesModel) SetSize(w, h int) {
-	m.list.SetSize(w, h)
+	m.w, m.h = w, h
+	m.list.SetSize(m.listSize())
+	m.changelog.SetSize(w, h)
+	if m.prune != nil {
+		m.prune.SetSize(w, h)
+	}
+}
+
+func (m PackagesModel) listSize() (int, int) {
+	if m.showDetail {
+		return m.w / 2, m.h
+	}
+	return m.w, m.h
+}
+
+func (m PackagesModel) selectedPackage() string {
+	if item := m.list.SelectedItem(); item != nil {
+		return item.FilterValue()
+	}
+	return ""
+}
+
+func (m *PackagesModel) openChangelog() tea.Cmd {
+	fetcher, ok := m.executor.(executors.ChangelogFetcher)
+	if !ok {
+		m.log("Changelog is not available for this package manager")
+		return nil
+	}
+	i, ok := m.list.SelectedItem().(item)
+	if !ok || i.info == nil {
+		return nil
+	}
+
+	return m.changelog.Open(m.name, fetcher, i.info)
+}
+
+func (m *PackagesModel) requestDetail() tea.Cmd {
+	pkg := m.selectedPackage()
+	if pkg == "" {
+		return nil
+	}
+	return m.details.request(m.name, pkg)
 }
 
 func (m *PackagesModel) Focus(focus bool) {
@@ -309,7 +778,7 @@ func (m *PackagesModel) getPackagesCmd() tea.Cmd {
 							if err != nil {
 								m.log(fmt.Sprintf("Error fetching packages (after password input): %v", err))
 							}
-							return packageUpdateMsg{m.name, getPackageItems(pkgs)}
+							return packageUpdateMsg{m.name, pkgs}
 						}
 					},
 				}
@@ -318,7 +787,7 @@ func (m *PackagesModel) getPackagesCmd() tea.Cmd {
 				pkgs = []*executors.PackageInfo{}
 			}
 
-			return packageUpdateMsg{m.name, getPackageItems(pkgs)}
+			return packageUpdateMsg{m.name, pkgs}
 		},
 	)
 }
@@ -334,21 +803,16 @@ func (m *PackagesModel) updatePackageCmd(pkg string) tea.Cmd {
 						if err != nil {
 							m.log(fmt.Sprintf("Error update pacakge (after password input): %v", err))
 						}
-						return updatePackagesFinishMsg{
-							name: m.name,
-							pkgs: []string{pkg},
-						}
+						return m.finishMsg([]string{pkg}, err)
 					}
 				},
+				cancel: m.passwordCanceledCmd([]string{pkg}),
 			}
 		} else if err != nil {
 			m.log(fmt.Sprintf("Error update pacakge: %v", err))
 		}
 
-		return updatePackagesFinishMsg{
-			name: m.name,
-			pkgs: []string{pkg},
-		}
+		return m.finishMsg([]string{pkg}, err)
 	}
 }
 
@@ -363,35 +827,90 @@ func (m *PackagesModel) bulkUpdatePackageCmd(pkgs []string) tea.Cmd {
 						if err != nil {
 							m.log(fmt.Sprintf("Error update pacakge (after password input): %v", err))
 						}
-						return updatePackagesFinishMsg{
-							name: m.name,
-							pkgs: pkgs,
-							err:  err,
-						}
+						return m.finishMsg(pkgs, err)
 					}
 				},
+				cancel: m.passwordCanceledCmd(pkgs),
 			}
 		} else if err != nil {
 			m.log(fmt.Sprintf("Error update pacakge: %v", err))
 		}
 
-		return updatePackagesFinishMsg{
-			name: m.name,
-			pkgs: pkgs,
-			err:  err,
+		return m.finishMsg(pkgs, err)
+	}
+}
+
+// finishMsg ends the update of the packages, telling which of them failed with the error of the update
+func (m *PackagesModel) finishMsg(pkgs []string, err error) updatePackagesFinishMsg {
+	msg := updatePackagesFinishMsg{name: m.name, pkgs: pkgs, err: err}
+	if err == nil || err == executors.ErrPassword {
+		return msg
+	}
+	msg.failed = map[string]error{}
+	for _, pkg := range pkgs {
+		if pkgErr := executors.PackageError(err, pkg); pkgErr != nil {
+			msg.failed[pkg] = pkgErr
+		}
+	}
+	return msg
+}
+
+// passwordCanceledCmd finishes the update waiting for the password without running it
+func (m *PackagesModel) passwordCanceledCmd(pkgs []string) tea.Cmd {
+	return func() tea.Msg {
+		m.log("Update canceled as no password was entered")
+		return m.finishMsg(pkgs, executors.ErrPassword)
+	}
+}
+
+// relist sorts the packages in the list again
+func (m *PackagesModel) relist() tea.Cmd {
+	pkgs := sortPackages(m.packages(), m.sortMode, m.group)
+	return m.list.SetItems(getPackageItems(pkgs, m.group))
+}
+
+// saveListConfig stores the sort mode and the grouping of the list in the config file
+func (m PackagesModel) saveListConfig() tea.Cmd {
+	group := m.group
+	lc := ListConfig{Sort: string(m.sortMode), Group: &group}
+	return func() tea.Msg {
+		if err := saveListConfig(m.config.Path, m.name, lc); err != nil {
+			m.log(fmt.Sprintf("Error saving the config file: %v", err))
 		}
+		return nil
 	}
 }
 
-func getPackageItems(pkgs []*executors.PackageInfo) []list.Item {
+// getPackageItems returns the rows of the packages, with the headers of groups when grouped
+func getPackageItems(pkgs []*executors.PackageInfo, grouped bool) []list.Item {
+	grouped = grouped && slices.ContainsFunc(pkgs, func(pkg *executors.PackageInfo) bool {
+		return pkg.Group != ""
+	})
+
 	rows := []list.Item{}
-	for _, pkg := range pkgs {
-		desc := fmt.Sprintf("\t(%s -> %s)", pkg.OldVersion, pkg.NewVersion)
+	for i, pkg := range pkgs {
+		if grouped && (i == 0 || pkg.Group != pkgs[i-1].Group) {
+			rows = append(rows, item{title: pkg.Group, header: true})
+		}
+		desc := fmt.Sprintf("\t(%s -> %s)", displayVersion(pkg.OldVersion), displayVersion(pkg.NewVersion))
+		if pkg.CheckError != "" {
+			desc = fmt.Sprintf("\t(%s)", displayVersion(pkg.OldVersion))
+		}
 		rows = append(rows, item{
 			title: pkg.Name,
 			desc:  desc,
+			info:  pkg,
 		})
 	}
 
 	return rows
 }
+
+// displayVersion shortens digests such as sha256:bdc9d2a52e79... like the docker CLI does
+func displayVersion(v string) string {
+	const shortDigestLength = 12
+	if hex, ok := strings.CutPrefix(v, "sha256:"); ok && len(hex) > shortDigestLength {
+		return hex[:shortDigestLength]
+	}
+	return v
+}
@@ -0,0 +1,153 @@
+package components
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/ymtdzzz/lazypkg/executors"
+)
+
+// planRecorder records the packages whose update is confirmed
+type planRecorder struct {
+	executors.GemExecutor
+	planned []string
+}
+
+func (e *planRecorder) Plan(pkgs []string) (*executors.UpdatePlan, error) {
+	e.planned = pkgs
+	return &executors.UpdatePlan{}, nil
+}
+
+var (
+	spaceKey  = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+	escKey    = tea.KeyMsg{Type: tea.KeyEsc}
+	updateKey = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")}
+)
+
+func newTestPackagesModel(e executors.Executor, pkgs ...*executors.PackageInfo) PackagesModel {
+	m := NewPackageModel(Config{}, PACKAGE_MANAGER_GEM, ICON_GEM, e)
+	m.Focus(true)
+	m.SetSize(80, 20)
+	m, _ = m.Update(packageUpdateMsg{name: m.name, pkgs: pkgs})
+	return m
+}
+
+func TestPackagesSelectionFiltered(t *testing.T) {
+	e := &planRecorder{}
+	m := newTestPackagesModel(e,
+		&executors.PackageInfo{Name: "rack"},
+		&executors.PackageInfo{Name: "rails"},
+		&executors.PackageInfo{Name: "rubocop"},
+	)
+
+	m.list.SetFilterText("rubo")
+	assert.Equal(t, "rubocop", m.selectedPackage())
+	m, _ = m.Update(spaceKey)
+	m, _ = m.Update(escKey)
+	assert.Len(t, m.list.VisibleItems(), 3)
+	assert.Equal(t, map[string]bool{"rubocop": true}, m.selection)
+
+	// the selected package is updated rather than the one under the cursor
+	assert.Equal(t, "rack", m.selectedPackage())
+	m, cmd := m.Update(updateKey)
+	dialog := summaryMsg(cmd)
+	if assert.NotNil(t, dialog) {
+		assert.Contains(t, dialog.msg, "Selected 1 packages will be updated")
+	}
+	assert.Equal(t, []string{"rubocop"}, e.planned)
+	assert.Equal(t, map[string]bool{"rubocop": false}, m.selection)
+}
+
+func TestPackagesGroupHeaderToggle(t *testing.T) {
+	m := newTestPackagesModel(&planRecorder{},
+		&executors.PackageInfo{Name: "@angular/cli", Group: "@angular"},
+		&executors.PackageInfo{Name: "typescript"},
+	)
+	m.group = true
+	m.relist()
+
+	i, ok := m.list.SelectedItem().(item)
+	if assert.True(t, ok) {
+		assert.True(t, i.header)
+	}
+	m, _ = m.Update(spaceKey)
+	assert.Empty(t, m.selection)
+}
+
+func TestPackagesFailures(t *testing.T) {
+	m := NewPackageModel(Config{}, PACKAGE_MANAGER_DOCKER, ICON_DOCKER, &executors.DockerExecutor{})
+
+	// the whole update failed, but only nginx failed in it
+	err := executors.UpdateErrors{"nginx:latest": errors.New("pull access denied")}
+	msg := m.finishMsg([]string{"nginx:latest", "redis:7"}, err)
+	assert.Equal(t, map[string]error{"nginx:latest": errors.New("pull access denied")}, msg.failed)
+
+	m, _ = m.Update(msg)
+	assert.Contains(t, m.failures, "nginx:latest")
+	assert.NotContains(t, m.failures, "redis:7")
+
+	// a canceled update keeps the failures
+	m, _ = m.Update(m.finishMsg([]string{"nginx:latest"}, executors.ErrPassword))
+	assert.Contains(t, m.failures, "nginx:latest")
+
+	// the failed packages stay selected as long as they are listed
+	m, _ = m.Update(packageUpdateMsg{name: m.name, pkgs: []*executors.PackageInfo{
+		{Name: "nginx:latest"},
+		{Name: "postgres:16"},
+	}})
+	assert.Equal(t, map[string]bool{"nginx:latest": true}, m.selection)
+
+	dialog := summaryMsg(m.errorOutputCmd("nginx:latest"))
+	if assert.NotNil(t, dialog) {
+		assert.True(t, dialog.info)
+		assert.Equal(t, "The update of nginx:latest failed", dialog.msg)
+		assert.Equal(t, "pull access denied", dialog.detail)
+	}
+	assert.Nil(t, m.errorOutputCmd("postgres:16"))
+
+	m, _ = m.Update(packageUpdateMsg{name: m.name, pkgs: []*executors.PackageInfo{{Name: "postgres:16"}}})
+	assert.Empty(t, m.failures)
+	assert.Empty(t, m.selection)
+}
+
+func TestPackagesFinishMsg(t *testing.T) {
+	m := NewPackageModel(Config{}, PACKAGE_MANAGER_APT, ICON_APT, &executors.AptExecutor{})
+
+	tests := []struct {
+		name string
+		err  error
+		want map[string]error
+	}{
+		{"succeeded", nil, nil},
+		{"canceled", executors.ErrPassword, nil},
+		{"failed", errors.New("exit status 100"), map[string]error{
+			"curl": errors.New("exit status 100"),
+			"git":  errors.New("exit status 100"),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := m.finishMsg([]string{"curl", "git"}, tt.err)
+			assert.Equal(t, tt.want, msg.failed)
+			assert.Equal(t, tt.err, msg.err)
+		})
+	}
+}
+
+func TestRenderErrorOutput(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 12; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	lines = append(lines, strings.Repeat("x", DIALOG_MAX_LINE_LENGTH+5))
+
+	got := strings.Split(renderErrorOutput(errors.New(strings.Join(lines, "\n"))), "\n")
+	assert.Len(t, got, errorOutputLines+1)
+	assert.Equal(t, "... 3 earlier lines are in the logs", got[0])
+	assert.Equal(t, "line 4", got[1])
+	assert.Equal(t, strings.Repeat("x", DIALOG_MAX_LINE_LENGTH-1)+"…", got[len(got)-1])
+}
@@ -10,6 +10,8 @@ type PasswordModel struct {
 	textinput textinput.Model
 	show      bool
 	callbacks []func(password string) tea.Cmd
+	// cancels are run instead of the callbacks when the input is canceled
+	cancels []tea.Cmd
 }
 
 func NewPasswordModel() PasswordModel {
@@ -39,10 +41,10 @@ func (m PasswordModel) Update(msg tea.Msg) (PasswordModel, tea.Cmd) {
 			switch msg.String() {
 			case "esc":
 				m.show = false
-				m.FlushCallbacks()
 				cmds = append(cmds, func() tea.Msg {
 					return BlurPasswordDialogMsg{}
-				}, updateLayoutCmd())
+				}, tea.Batch(m.cancels...), updateLayoutCmd())
+				m.FlushCallbacks()
 				m.textinput.Reset()
 			case "enter":
 				m.show = false
@@ -53,7 +55,7 @@ func (m PasswordModel) Update(msg tea.Msg) (PasswordModel, tea.Cmd) {
 				m.textinput.Reset()
 			}
 		case passwordInputStartMsg:
-			m.PushCallback(msg.callback)
+			m.PushCallback(msg.callback, msg.cancel)
 		}
 
 		m.textinput, cmd = m.textinput.Update(msg)
@@ -65,7 +67,7 @@ func (m PasswordModel) Update(msg tea.Msg) (PasswordModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case passwordInputStartMsg:
 		m.show = true
-		m.PushCallback(msg.callback)
+		m.PushCallback(msg.callback, msg.cancel)
 		cmds = append(cmds, func() tea.Msg {
 			return FocusPasswordDialogMsg{}
 		}, updateLayoutCmd(), m.Focus())
@@ -113,10 +115,14 @@ func (m *PasswordModel) Blur() {
 	m.textinput.Blur()
 }
 
-func (m *PasswordModel) PushCallback(callback func(password string) tea.Cmd) {
+func (m *PasswordModel) PushCallback(callback func(password string) tea.Cmd, cancel tea.Cmd) {
 	m.callbacks = append(m.callbacks, callback)
+	if cancel != nil {
+		m.cancels = append(m.cancels, cancel)
+	}
 }
 
 func (m *PasswordModel) FlushCallbacks() {
 	m.callbacks = []func(password string) tea.Cmd{}
+	m.cancels = nil
 }
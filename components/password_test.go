@@ -85,6 +85,11 @@ func TestPasswordOutput(t *testing.T) {
 					}
 				}
 			},
+			cancel: func() tea.Msg {
+				return callbackMsg{
+					value: "canceled",
+				}
+			},
 		})
 		wm.waitForMsgs(t, []any{
 			FocusPasswordDialogMsg{},
@@ -106,6 +111,9 @@ func TestPasswordOutput(t *testing.T) {
 			})
 			wm.waitForMsgs(t, []any{
 				BlurPasswordDialogMsg{},
+				callbackMsg{
+					value: "canceled",
+				},
 				UpdateLayoutMsg{},
 			})
 		})
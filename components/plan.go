@@ -0,0 +1,390 @@
+package components
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/ymtdzzz/lazypkg/executors"
+)
+
+// PhaseConfig is a group of package managers updated together when running an update plan
+type PhaseConfig struct {
+	Name     string   `yaml:"name"`
+	Managers []string `yaml:"managers"`
+	// DependsOn are the phases which have to succeed before this phase runs, every earlier phase when unset
+	DependsOn []string `yaml:"depends_on,omitempty"`
+}
+
+// Plan is a saved selection of packages to update, by package manager
+type Plan map[string][]string
+
+// defaultPhases update the system packages first, then the global packages of the language runtimes.
+// Container images do not depend on them.
+var defaultPhases = []PhaseConfig{
+	{Name: "system", Managers: []string{PACKAGE_MANAGER_APT, PACKAGE_MANAGER_HOMEBREW}},
+	{Name: "global", Managers: []string{PACKAGE_MANAGER_NPM, PACKAGE_MANAGER_GEM}},
+	{Name: "containers", Managers: []string{PACKAGE_MANAGER_DOCKER, PACKAGE_MANAGER_PODMAN, PACKAGE_MANAGER_NERDCTL, PACKAGE_MANAGER_COMPOSE}, DependsOn: []string{}},
+}
+
+// otherPhase runs the managers which are not in any phase, after every phase
+const otherPhase = "other"
+
+type phaseStatus string
+
+const (
+	phasePending   phaseStatus = "pending"
+	phaseRunning   phaseStatus = "running"
+	phaseSucceeded phaseStatus = "succeeded"
+	phaseFailed    phaseStatus = "failed"
+	phaseSkipped   phaseStatus = "skipped"
+)
+
+// planPhase is a phase with the packages of a plan
+type planPhase struct {
+	name      string
+	dependsOn []string
+	mgrs      []string
+	pkgs      map[string][]string
+}
+
+func (c Config) phases() []PhaseConfig {
+	if len(c.File.Phases) > 0 {
+		return c.File.Phases
+	}
+	return defaultPhases
+}
+
+// validatePhases checks that the names are unique, the dependencies are earlier phases and the managers are in one phase only
+func validatePhases(phases []PhaseConfig) error {
+	names := map[string]bool{}
+	mgrs := map[string]string{}
+	for _, p := range phases {
+		if p.Name == "" {
+			return errors.New("phases must have a name")
+		}
+		if names[p.Name] || p.Name == otherPhase {
+			return fmt.Errorf("phase %s is defined twice", p.Name)
+		}
+		for _, dep := range p.DependsOn {
+			if !names[dep] {
+				return fmt.Errorf("phase %s depends on %s, which is not an earlier phase", p.Name, dep)
+			}
+		}
+		for _, mgr := range p.Managers {
+			if other, ok := mgrs[mgr]; ok {
+				return fmt.Errorf("%s is in both phases %s and %s", mgr, other, p.Name)
+			}
+			mgrs[mgr] = p.Name
+		}
+		names[p.Name] = true
+	}
+	return nil
+}
+
+// planPhases returns the phases having packages in the plan, in the order they run
+func planPhases(phases []PhaseConfig, plan Plan) ([]planPhase, error) {
+	if err := validatePhases(phases); err != nil {
+		return nil, err
+	}
+
+	var (
+		result  []planPhase
+		earlier []string
+		placed  = map[string]bool{}
+	)
+	add := func(name string, dependsOn, mgrs []string) {
+		p := planPhase{name: name, dependsOn: dependsOn, pkgs: map[string][]string{}}
+		if p.dependsOn == nil {
+			p.dependsOn = slices.Clone(earlier)
+		}
+		for _, mgr := range mgrs {
+			placed[mgr] = true
+			if pkgs := plan[mgr]; len(pkgs) > 0 {
+				p.mgrs = append(p.mgrs, mgr)
+				p.pkgs[mgr] = pkgs
+			}
+		}
+		if len(p.mgrs) > 0 {
+			result = append(result, p)
+		}
+		earlier = append(earlier, name)
+	}
+	for _, p := range phases {
+		add(p.Name, p.DependsOn, p.Managers)
+	}
+
+	var others []string
+	for mgr := range plan {
+		if !placed[mgr] {
+			others = append(others, mgr)
+		}
+	}
+	sort.Strings(others)
+	add(otherPhase, nil, others)
+
+	return result, nil
+}
+
+// planRun tracks the phases of a running plan. The phases run one by one, and the managers of a phase run at the same time.
+type planRun struct {
+	name   string
+	phases []planPhase
+	status map[string]phaseStatus
+	errs   map[string]error
+	// current is the index of the running phase
+	current   int
+	remaining map[string]bool
+}
+
+func newPlanRun(name string, phases []planPhase) *planRun {
+	status := map[string]phaseStatus{}
+	for _, p := range phases {
+		status[p.name] = phasePending
+	}
+	return &planRun{
+		name:      name,
+		phases:    phases,
+		status:    status,
+		errs:      map[string]error{},
+		current:   -1,
+		remaining: map[string]bool{},
+	}
+}
+
+// next starts the next phase, skipping the ones which depend on a phase that failed or was skipped.
+// It returns nil when the plan is over.
+func (r *planRun) next() *planPhase {
+	for r.current+1 < len(r.phases) {
+		r.current++
+		p := &r.phases[r.current]
+		if dep := r.blockedBy(p); dep != "" {
+			r.status[p.name] = phaseSkipped
+			log.Printf("[plan %s] Skipping phase %s as phase %s did not succeed", r.name, p.name, dep)
+			continue
+		}
+		r.status[p.name] = phaseRunning
+		for _, mgr := range p.mgrs {
+			r.remaining[mgr] = true
+		}
+		log.Printf("[plan %s] Running phase %s: %s", r.name, p.name, strings.Join(p.mgrs, ", "))
+		return p
+	}
+	return nil
+}
+
+func (r *planRun) blockedBy(p *planPhase) string {
+	for _, dep := range p.dependsOn {
+		// phases without packages in the plan are not run and do not block
+		if s := r.status[dep]; s == phaseFailed || s == phaseSkipped {
+			return dep
+		}
+	}
+	return ""
+}
+
+// finish records the result of a manager of the running phase and reports whether the phase is over
+func (r *planRun) finish(mgr string, err error) bool {
+	if !r.remaining[mgr] {
+		return false
+	}
+	delete(r.remaining, mgr)
+	r.errs[mgr] = err
+	if len(r.remaining) > 0 {
+		return false
+	}
+
+	p := r.phases[r.current]
+	status := phaseSucceeded
+	for _, m := range p.mgrs {
+		if r.errs[m] != nil {
+			status = phaseFailed
+		}
+	}
+	r.status[p.name] = status
+	log.Printf("[plan %s] Phase %s %s", r.name, p.name, status)
+	return true
+}
+
+// failed reports whether a phase failed or was skipped
+func (r *planRun) failed() bool {
+	for _, s := range r.status {
+		if s == phaseFailed || s == phaseSkipped {
+			return true
+		}
+	}
+	return false
+}
+
+// summary counts the phases by status, e.g. "2 succeeded, 1 failed, 1 skipped"
+func (r *planRun) summary() string {
+	var parts []string
+	for _, s := range []phaseStatus{phaseSucceeded, phaseFailed, phaseSkipped} {
+		count := 0
+		for _, p := range r.phases {
+			if r.status[p.name] == s {
+				count++
+			}
+		}
+		if count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, s))
+		}
+	}
+	if len(parts) == 0 {
+		return "nothing to update"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// executePlan runs the phases until the plan is over, updating the managers of a phase in parallel
+func executePlan(r *planRun, update func(mgr string, pkgs []string) error) {
+	var mu sync.Mutex
+	for p := r.next(); p != nil; p = r.next() {
+		var wg sync.WaitGroup
+		for _, mgr := range p.mgrs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := update(mgr, p.pkgs[mgr])
+				mu.Lock()
+				defer mu.Unlock()
+				r.finish(mgr, err)
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+// RunPlan runs the saved update plan without the TUI and writes the result of each manager to w
+func RunPlan(config Config, name, password string, w io.Writer) error {
+	return runPlan(config, name, password, w, func(mgr string) (executors.Executor, error) {
+		return newExecutor(config, mgr, nil)
+	})
+}
+
+func runPlan(config Config, name, password string, w io.Writer, newExec func(mgr string) (executors.Executor, error)) error {
+	plan, ok := config.File.Plans[name]
+	if !ok {
+		return fmt.Errorf("plan %s is not found in %s", name, config.Path)
+	}
+	phases, err := planPhases(config.phases(), plan)
+	if err != nil {
+		return err
+	}
+
+	execs := map[string]executors.Executor{}
+	for _, p := range phases {
+		for _, mgr := range p.mgrs {
+			e, err := newExec(mgr)
+			if err != nil {
+				return err
+			}
+			defer e.Close()
+			execs[mgr] = e
+		}
+	}
+
+	r := newPlanRun(name, phases)
+	executePlan(r, func(mgr string, pkgs []string) error {
+		e := execs[mgr]
+		if !e.Valid() {
+			return fmt.Errorf("%s is not available", mgr)
+		}
+		// the updates are found by checking for them, which the TUI does on start
+		if l, ok := e.(executors.UpdateLister); ok && !l.UpdatesListed(pkgs) {
+			log.Printf("[%s] Checking for updates", mgr)
+			if _, err := e.GetPackages(password); err != nil {
+				return err
+			}
+		}
+		err := e.BulkUpdate(pkgs, password, config.DryRun)
+		if errors.Is(err, executors.ErrPassword) {
+			return fmt.Errorf("%w, pass it with --password-stdin", err)
+		}
+		return err
+	})
+
+	if err := writePlanResults(w, r); err != nil {
+		return err
+	}
+	if r.failed() {
+		return fmt.Errorf("plan %s did not succeed: %s", name, r.summary())
+	}
+	return nil
+}
+
+// ListPlans writes the saved update plans with their phases to w
+func ListPlans(config Config, w io.Writer) error {
+	if len(config.File.Plans) == 0 {
+		_, err := fmt.Fprintln(w, "No plans saved")
+		return err
+	}
+
+	names := make([]string, 0, len(config.File.Plans))
+	for name := range config.File.Plans {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		phases, err := planPhases(config.phases(), config.File.Plans[name])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, name)
+		fmt.Fprintln(w, renderPlanPhases(phases, 0))
+	}
+	return nil
+}
+
+func writePlanResults(w io.Writer, r *planRun) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PHASE\tMANAGER\tPACKAGES\tRESULT")
+	for _, p := range r.phases {
+		for _, mgr := range p.mgrs {
+			result := "ok"
+			switch {
+			case r.status[p.name] == phaseSkipped:
+				result = "skipped"
+			case r.errs[mgr] != nil:
+				first, _, _ := strings.Cut(r.errs[mgr].Error(), "\n")
+				result = "failed: " + first
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", p.name, mgr, len(p.pkgs[mgr]), result)
+		}
+	}
+	return tw.Flush()
+}
+
+// renderPlanPhases lists the phases in the order they run, truncating the lines to maxLen unless it is 0
+func renderPlanPhases(phases []planPhase, maxLen int) string {
+	var rows []string
+	for i, p := range phases {
+		var mgrs []string
+		for _, mgr := range p.mgrs {
+			mgrs = append(mgrs, fmt.Sprintf("%s (%d)", mgr, len(p.pkgs[mgr])))
+		}
+		line := fmt.Sprintf("%d. %s: %s", i+1, p.name, strings.Join(mgrs, ", "))
+		if maxLen > 0 {
+			line = truncate(line, maxLen)
+		}
+		rows = append(rows, line)
+	}
+	if len(rows) == 0 {
+		return "The plan has no packages"
+	}
+	return strings.Join(rows, "\n")
+}
+
+// savePlan stores the plan in the config file, removing it when nil
+func savePlan(path, name string, plan Plan) error {
+	if plan == nil {
+		return saveConfigEntry(path, "plans", name, nil)
+	}
+	return saveConfigEntry(path, "plans", name, plan)
+}
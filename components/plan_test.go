@@ -0,0 +1,273 @@
+package components
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ymtdzzz/lazypkg/executors"
+)
+
+func TestValidatePhases(t *testing.T) {
+	tests := []struct {
+		name    string
+		phases  []PhaseConfig
+		wantErr string
+	}{
+		{
+			name:   "default",
+			phases: defaultPhases,
+		},
+		{
+			name:    "duplicated name",
+			phases:  []PhaseConfig{{Name: "system"}, {Name: "system"}},
+			wantErr: "phase system is defined twice",
+		},
+		{
+			name:    "later dependency",
+			phases:  []PhaseConfig{{Name: "system", DependsOn: []string{"global"}}, {Name: "global"}},
+			wantErr: "phase system depends on global, which is not an earlier phase",
+		},
+		{
+			name:    "manager in two phases",
+			phases:  []PhaseConfig{{Name: "system", Managers: []string{"apt"}}, {Name: "global", Managers: []string{"apt"}}},
+			wantErr: "apt is in both phases system and global",
+		},
+		{
+			name:    "missing name",
+			phases:  []PhaseConfig{{Managers: []string{"apt"}}},
+			wantErr: "phases must have a name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePhases(tt.phases)
+			if tt.wantErr == "" {
+				assert.Nil(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestPlanPhases(t *testing.T) {
+	plan := Plan{
+		"npm":    {"express"},
+		"apt":    {"curl", "git"},
+		"docker": {"redis:7"},
+		"pip":    {"requests"},
+	}
+
+	phases, err := planPhases(defaultPhases, plan)
+	require.Nil(t, err)
+	assert.Equal(t, []planPhase{
+		{
+			name: "system",
+			mgrs: []string{"apt"},
+			pkgs: map[string][]string{"apt": {"curl", "git"}},
+		},
+		{
+			name:      "global",
+			dependsOn: []string{"system"},
+			mgrs:      []string{"npm"},
+			pkgs:      map[string][]string{"npm": {"express"}},
+		},
+		{
+			name:      "containers",
+			dependsOn: []string{},
+			mgrs:      []string{"docker"},
+			pkgs:      map[string][]string{"docker": {"redis:7"}},
+		},
+		{
+			name:      otherPhase,
+			dependsOn: []string{"system", "global", "containers"},
+			mgrs:      []string{"pip"},
+			pkgs:      map[string][]string{"pip": {"requests"}},
+		},
+	}, phases)
+}
+
+func TestExecutePlan(t *testing.T) {
+	phases := []PhaseConfig{
+		{Name: "system", Managers: []string{"apt", "homebrew"}},
+		{Name: "global", Managers: []string{"npm"}},
+		{Name: "runtimes", Managers: []string{"gem"}},
+		{Name: "containers", Managers: []string{"docker"}, DependsOn: []string{}},
+	}
+	plan := Plan{
+		"apt":      {"curl"},
+		"homebrew": {"node"},
+		"npm":      {"express"},
+		"gem":      {"rails"},
+		"docker":   {"redis:7"},
+	}
+
+	tests := []struct {
+		name        string
+		fail        string
+		want        map[string]phaseStatus
+		wantUpdated []string
+	}{
+		{
+			name: "succeeded",
+			want: map[string]phaseStatus{
+				"system": phaseSucceeded, "global": phaseSucceeded, "runtimes": phaseSucceeded, "containers": phaseSucceeded,
+			},
+			wantUpdated: []string{"apt", "docker", "gem", "homebrew", "npm"},
+		},
+		{
+			name: "failure stops the dependent phases",
+			fail: "homebrew",
+			want: map[string]phaseStatus{
+				"system": phaseFailed, "global": phaseSkipped, "runtimes": phaseSkipped, "containers": phaseSucceeded,
+			},
+			wantUpdated: []string{"apt", "docker", "homebrew"},
+		},
+		{
+			name: "failure of the last phase",
+			fail: "docker",
+			want: map[string]phaseStatus{
+				"system": phaseSucceeded, "global": phaseSucceeded, "runtimes": phaseSucceeded, "containers": phaseFailed,
+			},
+			wantUpdated: []string{"apt", "docker", "gem", "homebrew", "npm"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pp, err := planPhases(phases, plan)
+			require.Nil(t, err)
+
+			var (
+				mu      sync.Mutex
+				updated []string
+			)
+			r := newPlanRun("weekly", pp)
+			executePlan(r, func(mgr string, pkgs []string) error {
+				mu.Lock()
+				updated = append(updated, mgr)
+				mu.Unlock()
+				if mgr == tt.fail {
+					return errors.New("exit status 1")
+				}
+				return nil
+			})
+
+			assert.Equal(t, tt.want, r.status)
+			assert.ElementsMatch(t, tt.wantUpdated, updated)
+			assert.Equal(t, tt.fail != "", r.failed())
+		})
+	}
+}
+
+func TestWritePlanResults(t *testing.T) {
+	pp, err := planPhases(defaultPhases, Plan{"apt": {"curl", "git"}, "npm": {"express"}})
+	require.Nil(t, err)
+	r := newPlanRun("weekly", pp)
+	executePlan(r, func(mgr string, pkgs []string) error {
+		return errors.New("E: Could not get lock\nmore details")
+	})
+
+	var sb strings.Builder
+	require.Nil(t, writePlanResults(&sb, r))
+	assert.Equal(t, `PHASE   MANAGER  PACKAGES  RESULT
+system  apt      2         failed: E: Could not get lock
+global  npm      1         skipped
+`, sb.String())
+	assert.Equal(t, "1 failed, 1 skipped", r.summary())
+}
+
+func TestSavePlan(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	require.Nil(t, savePlan(path, "weekly", Plan{"apt": {"curl"}, "npm": {"express"}}))
+	require.Nil(t, savePlan(path, "daily", Plan{"apt": {"git"}}))
+	config := NewConfig(false, nil, nil, false, "")
+	require.Nil(t, config.LoadFile(path))
+	assert.Equal(t, map[string]Plan{
+		"weekly": {"apt": {"curl"}, "npm": {"express"}},
+		"daily":  {"apt": {"git"}},
+	}, config.File.Plans)
+
+	require.Nil(t, savePlan(path, "weekly", nil))
+	config = NewConfig(false, nil, nil, false, "")
+	require.Nil(t, config.LoadFile(path))
+	assert.Equal(t, map[string]Plan{"daily": {"apt": {"git"}}}, config.File.Plans)
+
+	var sb strings.Builder
+	require.Nil(t, ListPlans(config, &sb))
+	assert.Equal(t, "daily\n1. system: apt (1)\n", sb.String())
+}
+
+// fakeComposeExecutor updates the services found by GetPackages only, as compose does
+type fakeComposeExecutor struct {
+	services []string
+	listed   map[string]bool
+	updated  []string
+}
+
+func (e *fakeComposeExecutor) GetPackages(_ string) ([]*executors.PackageInfo, error) {
+	e.listed = map[string]bool{}
+	var pkgs []*executors.PackageInfo
+	for _, s := range e.services {
+		e.listed[s] = true
+		pkgs = append(pkgs, &executors.PackageInfo{Name: s})
+	}
+	return pkgs, nil
+}
+
+func (e *fakeComposeExecutor) Update(pkg, password string, dryRun bool) error {
+	return e.BulkUpdate([]string{pkg}, password, dryRun)
+}
+
+func (e *fakeComposeExecutor) BulkUpdate(pkgs []string, _ string, _ bool) error {
+	errs := executors.UpdateErrors{}
+	for _, pkg := range pkgs {
+		if !e.listed[pkg] {
+			errs[pkg] = errors.New("no update found")
+			continue
+		}
+		e.updated = append(e.updated, pkg)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (e *fakeComposeExecutor) UpdatesListed(pkgs []string) bool {
+	for _, pkg := range pkgs {
+		if !e.listed[pkg] {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *fakeComposeExecutor) Valid() bool {
+	return true
+}
+
+func (e *fakeComposeExecutor) Close() {}
+
+func TestRunPlanCompose(t *testing.T) {
+	config := NewConfig(false, nil, nil, false, "")
+	config.File.Plans = map[string]Plan{
+		"weekly": {PACKAGE_MANAGER_COMPOSE: {"app/db", "app/web"}},
+	}
+	compose := &fakeComposeExecutor{services: []string{"app/db", "app/web", "monitoring/grafana"}}
+
+	var sb strings.Builder
+	err := runPlan(config, "weekly", "", &sb, func(mgr string) (executors.Executor, error) {
+		return compose, nil
+	})
+	require.Nil(t, err)
+	assert.Equal(t, []string{"app/db", "app/web"}, compose.updated)
+	assert.Equal(t, "PHASE       MANAGER  PACKAGES  RESULT\ncontainers  compose  2         ok\n", sb.String())
+}
@@ -0,0 +1,281 @@
+package components
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type planStartMsg struct {
+	name string
+	plan Plan
+}
+
+type planDeletedMsg struct {
+	name string
+}
+
+type plansKeyMap struct {
+	Close  key.Binding
+	Up     key.Binding
+	Down   key.Binding
+	Run    key.Binding
+	Delete key.Binding
+}
+
+func newPlansKeyMap(keys KeyConfig) plansKeyMap {
+	km := plansKeyMap{
+		Close: key.NewBinding(
+			key.WithKeys("esc", "P"),
+			key.WithHelp("esc | P", "close"),
+		),
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑ | k", "up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓ | j", "down"),
+		),
+		Run: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "run plan"),
+		),
+		Delete: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "delete plan"),
+		),
+	}
+	remapKeys(keys, keyPanePlans, km.bindings())
+	return km
+}
+
+func (km *plansKeyMap) bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"close":  &km.Close,
+		"up":     &km.Up,
+		"down":   &km.Down,
+		"run":    &km.Run,
+		"delete": &km.Delete,
+	}
+}
+
+// PlansModel lists the saved update plans, and names the selection to save as a plan
+type PlansModel struct {
+	keyMap plansKeyMap
+	config Config
+	plans  map[string]Plan
+	cursor int
+	show   bool
+	// naming is set while the name of the unsaved plan is typed
+	naming  bool
+	input   textinput.Model
+	unsaved Plan
+	w, h    int
+}
+
+func NewPlansModel(config Config) PlansModel {
+	plans := map[string]Plan{}
+	for name, plan := range config.File.Plans {
+		plans[name] = plan
+	}
+	ti := textinput.New()
+	ti.Prompt = "Plan name: "
+	ti.CharLimit = 50
+
+	return PlansModel{
+		keyMap: newPlansKeyMap(config.File.Keys),
+		config: config,
+		plans:  plans,
+		input:  ti,
+	}
+}
+
+func (m PlansModel) Update(msg tea.Msg) (PlansModel, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case planDeletedMsg:
+		delete(m.plans, msg.name)
+		m.cursor = min(m.cursor, max(len(m.plans)-1, 0))
+		return m, m.saveCmd(msg.name, nil)
+	case tea.KeyMsg:
+		if !m.show {
+			break
+		}
+		if m.naming {
+			switch msg.String() {
+			case "esc":
+				m.naming = false
+				m.show = false
+				m.input.Blur()
+				return m, nil
+			case "enter":
+				name := strings.TrimSpace(m.input.Value())
+				if name == "" {
+					return m, nil
+				}
+				m.naming = false
+				m.input.Blur()
+				m.plans[name] = m.unsaved
+				m.cursor = max(slices.Index(m.names(), name), 0)
+				return m, m.saveCmd(name, m.unsaved)
+			}
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+		switch {
+		case key.Matches(msg, m.keyMap.Close):
+			m.show = false
+		case key.Matches(msg, m.keyMap.Up):
+			m.cursor = max(m.cursor-1, 0)
+		case key.Matches(msg, m.keyMap.Down):
+			m.cursor = min(m.cursor+1, max(len(m.plans)-1, 0))
+		case key.Matches(msg, m.keyMap.Run):
+			return m, m.runCmd()
+		case key.Matches(msg, m.keyMap.Delete):
+			if name := m.selected(); name != "" {
+				return m, showDialogCmd(fmt.Sprintf("Plan %s will be deleted", name), func() tea.Msg {
+					return planDeletedMsg{name: name}
+				})
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (m PlansModel) View() string {
+	rows := []string{titleStyle.Render("Update plans"), ""}
+	if m.naming {
+		rows = append(rows, itemStyle.Render(m.input.View()), "", itemDescStyle.Render("  "+m.describe(m.unsaved)))
+		return lipgloss.JoinVertical(lipgloss.Left, rows...)
+	}
+
+	names := m.names()
+	if len(names) == 0 {
+		rows = append(rows, "  No plans saved. Select packages and press w to save them as a plan")
+		return lipgloss.JoinVertical(lipgloss.Left, rows...)
+	}
+	for i, name := range names {
+		str := fmt.Sprintf("%s %s", name, itemDescStyle.Render(m.describe(m.plans[name])))
+		if i == m.cursor {
+			rows = append(rows, selectedItemStyle.Render("> "+str))
+		} else {
+			rows = append(rows, itemStyle.Render(str))
+		}
+	}
+	if phases, err := planPhases(m.config.phases(), m.plans[names[m.cursor]]); err == nil {
+		rows = append(rows, "", itemStyle.Render(renderPlanPhases(phases, max(m.w-4, 1))))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// describe counts the packages and managers of the plan
+func (m PlansModel) describe(plan Plan) string {
+	count := 0
+	for _, pkgs := range plan {
+		count += len(pkgs)
+	}
+	return fmt.Sprintf("(%d packages of %d managers)", count, len(plan))
+}
+
+func (m PlansModel) IsShown() bool {
+	return m.show
+}
+
+// IsNaming reports whether the name of a plan is being typed, which takes the keys
+func (m PlansModel) IsNaming() bool {
+	return m.show && m.naming
+}
+
+func (m PlansModel) ShortHelp() []key.Binding {
+	return []key.Binding{m.keyMap.Run, m.keyMap.Delete, m.keyMap.Close}
+}
+
+func (m PlansModel) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{m.keyMap.Up, m.keyMap.Down}, m.ShortHelp()}
+}
+
+func (m *PlansModel) SetSize(w, h int) {
+	m.w, m.h = w, h
+	m.input.Width = max(w-len(m.input.Prompt)-4, 1)
+}
+
+// Open shows the saved plans
+func (m *PlansModel) Open() {
+	m.show = true
+	m.naming = false
+	m.cursor = min(m.cursor, max(len(m.plans)-1, 0))
+}
+
+// Save asks for the name of the plan and saves it
+func (m *PlansModel) Save(plan Plan) tea.Cmd {
+	m.show = true
+	m.naming = true
+	m.unsaved = plan
+	m.input.Reset()
+	return m.input.Focus()
+}
+
+func (m *PlansModel) Close() {
+	m.show = false
+	m.naming = false
+}
+
+func (m PlansModel) names() []string {
+	names := make([]string, 0, len(m.plans))
+	for name := range m.plans {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m PlansModel) selected() string {
+	names := m.names()
+	if m.cursor < len(names) {
+		return names[m.cursor]
+	}
+	return ""
+}
+
+func (m PlansModel) runCmd() tea.Cmd {
+	name := m.selected()
+	if name == "" {
+		return nil
+	}
+	plan := m.plans[name]
+	phases, err := planPhases(m.config.phases(), plan)
+	if err != nil {
+		logf(PACKAGE_MANAGER_ALL, "Error running plan %s: %v", name, err)
+		return nil
+	}
+
+	return showDialogWithDetailCmd(
+		fmt.Sprintf("Plan %s will be run %s", name, m.describe(plan)),
+		renderPlanPhases(phases, DIALOG_MAX_LINE_LENGTH),
+		func() tea.Msg {
+			return planStartMsg{name: name, plan: plan}
+		},
+	)
+}
+
+func (m PlansModel) saveCmd(name string, plan Plan) tea.Cmd {
+	path := m.config.Path
+	return func() tea.Msg {
+		if err := savePlan(path, name, plan); err != nil {
+			logf(PACKAGE_MANAGER_ALL, "Error saving the config file: %v", err)
+		} else if plan != nil {
+			logf(PACKAGE_MANAGER_ALL, "Saved plan %s", name)
+		}
+		return nil
+	}
+}
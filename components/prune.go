@@ -0,0 +1,276 @@
+package components
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ymtdzzz/lazypkg/executors"
+)
+
+type pruneListedMsg struct {
+	name   string
+	images []executors.DanglingImage
+	err    error
+}
+
+type pruneFinishedMsg struct {
+	name      string
+	reclaimed int64
+	err       error
+}
+
+type pruneKeyMap struct {
+	Close     key.Binding
+	Up        key.Binding
+	Down      key.Binding
+	Toggle    key.Binding
+	ToggleAll key.Binding
+	Remove    key.Binding
+}
+
+func newPruneKeyMap(keys KeyConfig) pruneKeyMap {
+	km := pruneKeyMap{
+		Close: key.NewBinding(
+			key.WithKeys("esc", "p"),
+			key.WithHelp("esc | p", "close"),
+		),
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑ | k", "up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓ | j", "down"),
+		),
+		Toggle: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "toggle check"),
+		),
+		ToggleAll: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "toggle all"),
+		),
+		Remove: key.NewBinding(
+			key.WithKeys("enter", "d"),
+			key.WithHelp("enter | d", "remove selected"),
+		),
+	}
+	remapKeys(keys, keyPanePrune, km.bindings())
+	return km
+}
+
+func (km *pruneKeyMap) bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"close":      &km.Close,
+		"up":         &km.Up,
+		"down":       &km.Down,
+		"toggle":     &km.Toggle,
+		"toggle_all": &km.ToggleAll,
+		"remove":     &km.Remove,
+	}
+}
+
+// PruneModel lists dangling images and removes the selected ones
+type PruneModel struct {
+	keyMap   pruneKeyMap
+	name     string
+	pruner   executors.ImagePruner
+	dryRun   bool
+	show     bool
+	loading  bool
+	images   []executors.DanglingImage
+	selected map[string]bool
+	cursor   int
+	w, h     int
+}
+
+func NewPruneModel(name string, pruner executors.ImagePruner, dryRun bool, keys KeyConfig) PruneModel {
+	return PruneModel{
+		keyMap:   newPruneKeyMap(keys),
+		name:     name,
+		pruner:   pruner,
+		dryRun:   dryRun,
+		selected: map[string]bool{},
+	}
+}
+
+func (m PruneModel) Update(msg tea.Msg) (PruneModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case pruneListedMsg:
+		if msg.name != m.name {
+			break
+		}
+		m.loading = false
+		if msg.err != nil {
+			logf(m.name, "Error listing dangling images: %v", msg.err)
+			break
+		}
+		m.images = msg.images
+		m.cursor = min(m.cursor, max(len(m.images)-1, 0))
+		m.selected = map[string]bool{}
+		// images replaced by lazypkg are selected by default
+		for _, img := range m.images {
+			if img.ReplacedBy != "" {
+				m.selected[img.ID] = true
+			}
+		}
+	case pruneFinishedMsg:
+		if msg.name != m.name {
+			break
+		}
+		if msg.err != nil {
+			logf(m.name, "Error removing images: %v", msg.err)
+		}
+		logf(m.name, "Reclaimed %s", orZeroBytes(msg.reclaimed))
+		if m.show {
+			return m, m.listCmd()
+		}
+	case tea.KeyMsg:
+		if !m.show {
+			break
+		}
+		switch {
+		case key.Matches(msg, m.keyMap.Close):
+			m.show = false
+		case key.Matches(msg, m.keyMap.Up):
+			m.cursor = max(m.cursor-1, 0)
+		case key.Matches(msg, m.keyMap.Down):
+			m.cursor = min(m.cursor+1, max(len(m.images)-1, 0))
+		case key.Matches(msg, m.keyMap.Toggle):
+			if m.cursor < len(m.images) {
+				id := m.images[m.cursor].ID
+				m.selected[id] = !m.selected[id]
+			}
+		case key.Matches(msg, m.keyMap.ToggleAll):
+			all := len(m.selectedImages()) < len(m.images)
+			for _, img := range m.images {
+				m.selected[img.ID] = all
+			}
+		case key.Matches(msg, m.keyMap.Remove):
+			return m, m.removeCmd()
+		}
+	}
+
+	return m, nil
+}
+
+func (m PruneModel) View() string {
+	rows := []string{titleStyle.Render(fmt.Sprintf("Dangling images [%s]", m.name)), ""}
+	switch {
+	case m.loading:
+		rows = append(rows, "  Loading dangling images...")
+	case len(m.images) == 0:
+		rows = append(rows, "  No dangling images found")
+	default:
+		// title, empty line, footer
+		height := max(m.h-4, 1)
+		start := max(m.cursor-height+1, 0)
+		for i := start; i < min(start+height, len(m.images)); i++ {
+			rows = append(rows, m.renderImage(i))
+		}
+		selected := m.selectedImages()
+		rows = append(rows, "", itemDescStyle.Render(fmt.Sprintf(
+			"  %d of %d images selected, %s to reclaim",
+			len(selected),
+			len(m.images),
+			orZeroBytes(totalSize(selected)),
+		)))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+func (m PruneModel) renderImage(i int) string {
+	img := m.images[i]
+	check := " "
+	if m.selected[img.ID] {
+		check = "*"
+	}
+	desc := img.Created.Format("2006-01-02")
+	if img.ReplacedBy != "" {
+		desc = fmt.Sprintf("%s, replaced by %s", desc, img.ReplacedBy)
+	}
+	str := fmt.Sprintf("%s %s %10s  %s", check, displayVersion(img.ID), formatBytes(img.Size), itemDescStyle.Render(desc))
+	if i == m.cursor {
+		return selectedItemStyle.Render("> " + str)
+	}
+	return itemStyle.Render(str)
+}
+
+func (m PruneModel) IsShown() bool {
+	return m.show
+}
+
+func (m PruneModel) ShortHelp() []key.Binding {
+	return []key.Binding{m.keyMap.Toggle, m.keyMap.ToggleAll, m.keyMap.Remove, m.keyMap.Close}
+}
+
+func (m PruneModel) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{m.keyMap.Up, m.keyMap.Down}, m.ShortHelp()}
+}
+
+func (m *PruneModel) SetSize(w, h int) {
+	m.w, m.h = w, h
+}
+
+// Open shows the dangling images and returns the command listing them
+func (m *PruneModel) Open() tea.Cmd {
+	m.show = true
+	m.loading = true
+	m.cursor = 0
+	return m.listCmd()
+}
+
+func (m PruneModel) listCmd() tea.Cmd {
+	return func() tea.Msg {
+		images, err := m.pruner.DanglingImages()
+		return pruneListedMsg{name: m.name, images: images, err: err}
+	}
+}
+
+func (m PruneModel) removeCmd() tea.Cmd {
+	selected := m.selectedImages()
+	if len(selected) == 0 {
+		return nil
+	}
+
+	return showDialogCmd(
+		fmt.Sprintf("%d images will be removed to reclaim %s", len(selected), orZeroBytes(totalSize(selected))),
+		func() tea.Msg {
+			reclaimed, err := m.pruner.RemoveImages(selected, m.dryRun)
+			return pruneFinishedMsg{name: m.name, reclaimed: reclaimed, err: err}
+		},
+	)
+}
+
+func (m PruneModel) selectedImages() []executors.DanglingImage {
+	var imgs []executors.DanglingImage
+	for _, img := range m.images {
+		if m.selected[img.ID] {
+			imgs = append(imgs, img)
+		}
+	}
+	return imgs
+}
+
+func totalSize(imgs []executors.DanglingImage) int64 {
+	var size int64
+	for _, img := range imgs {
+		size += img.Size
+	}
+	return size
+}
+
+func orZeroBytes(size int64) string {
+	if size <= 0 {
+		return "0 B"
+	}
+	return formatBytes(size)
+}
+
+func logf(name, format string, args ...any) {
+	log.Printf("[%s] %s", name, fmt.Sprintf(format, args...))
+}
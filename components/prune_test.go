@@ -0,0 +1,51 @@
+package components
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/ymtdzzz/lazypkg/executors"
+)
+
+type fakePruner struct {
+	images  []executors.DanglingImage
+	removed []executors.DanglingImage
+}
+
+func (p *fakePruner) DanglingImages() ([]executors.DanglingImage, error) {
+	return p.images, nil
+}
+
+func (p *fakePruner) RemoveImages(imgs []executors.DanglingImage, _ bool) (int64, error) {
+	p.removed = imgs
+	return totalSize(imgs), nil
+}
+
+func TestPruneModelSelection(t *testing.T) {
+	pruner := &fakePruner{images: []executors.DanglingImage{
+		{ID: "sha256:replaced0000000", Size: 2048, ReplacedBy: "redis:7"},
+		{ID: "sha256:other000000000", Size: 1024},
+	}}
+	m := NewPruneModel(PACKAGE_MANAGER_DOCKER, pruner, false, nil)
+	m.SetSize(80, 20)
+
+	msg := m.Open()()
+	m, _ = m.Update(msg)
+	assert.Equal(t, pruner.images[:1], m.selectedImages(), "images replaced by lazypkg are selected by default")
+	assert.Contains(t, m.View(), "1 of 2 images selected, 2.0 KiB to reclaim")
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	assert.Equal(t, pruner.images, m.selectedImages())
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	assert.Equal(t, pruner.images[:1], m.selectedImages())
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	dialog, ok := cmd().(showDialogMsg)
+	assert.True(t, ok)
+	assert.Contains(t, dialog.msg, "1 images will be removed to reclaim 2.0 KiB")
+	assert.Equal(t, pruneFinishedMsg{name: PACKAGE_MANAGER_DOCKER, reclaimed: 2048}, dialog.callback())
+	assert.Equal(t, pruner.images[:1], pruner.removed)
+}
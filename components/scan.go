@@ -0,0 +1,309 @@
+package components
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ymtdzzz/lazypkg/executors"
+	"github.com/ymtdzzz/lazypkg/osv"
+)
+
+type ScanResult struct {
+	Manager   string
+	Ecosystem string
+	// Package and Version are the installed package, which is built from the package of the finding for apt
+	Package string
+	Version string
+	Finding osv.Finding
+}
+
+// binNMUPattern matches the suffix of binary-only rebuilds, which the source package version lacks
+var binNMUPattern = regexp.MustCompile(`\+b\d+$`)
+
+type scanFinishedMsg struct {
+	results []ScanResult
+	errs    []error
+}
+
+type scanKeyMap struct {
+	Close key.Binding
+	Up    key.Binding
+	Down  key.Binding
+}
+
+func newScanKeyMap(keys KeyConfig) scanKeyMap {
+	km := scanKeyMap{
+		Close: key.NewBinding(
+			key.WithKeys("esc", "v"),
+			key.WithHelp("esc | v", "close scan"),
+		),
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑ | k", "scroll up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓ | j", "scroll down"),
+		),
+	}
+	remapKeys(keys, keyPaneScan, km.bindings())
+	return km
+}
+
+func (km *scanKeyMap) bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"close": &km.Close,
+		"up":    &km.Up,
+		"down":  &km.Down,
+	}
+}
+
+type ScanModel struct {
+	keyMap   scanKeyMap
+	viewport viewport.Model
+	show     bool
+	db       *osv.Database
+	pkglists map[string]*PackagesModel
+}
+
+func NewScanModel(db *osv.Database, pkglists map[string]*PackagesModel, keys KeyConfig) ScanModel {
+	vp := viewport.New(0, 0)
+	vp.KeyMap = viewport.KeyMap{}
+
+	return ScanModel{
+		keyMap:   newScanKeyMap(keys),
+		viewport: vp,
+		db:       db,
+		pkglists: pkglists,
+	}
+}
+
+func (m ScanModel) Update(msg tea.Msg) (ScanModel, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case showScanMsg:
+		if m.db == nil {
+			log.Print("Vulnerability scan requires an OSV database. Run lazypkg with --osv-db")
+			break
+		}
+		m.show = true
+		m.viewport.SetContent("Scanning installed packages...")
+		cmds = append(cmds, func() tea.Msg {
+			return FocusScanMsg{}
+		}, m.scanCmd())
+	case scanFinishedMsg:
+		var sb strings.Builder
+		for _, err := range msg.errs {
+			fmt.Fprintf(&sb, "Error: %v\n", err)
+		}
+		writeScanResults(&sb, msg.results)
+		m.viewport.SetContent(sb.String())
+	case tea.KeyMsg:
+		if !m.show {
+			break
+		}
+		switch {
+		case key.Matches(msg, m.keyMap.Close):
+			m.show = false
+			cmds = append(cmds, func() tea.Msg {
+				return BlurScanMsg{}
+			})
+		case key.Matches(msg, m.keyMap.Up):
+			m.viewport.ScrollUp(1)
+		case key.Matches(msg, m.keyMap.Down):
+			m.viewport.ScrollDown(1)
+		}
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+func (m ScanModel) View() string {
+	return lipgloss.JoinVertical(lipgloss.Left, titleStyle.Render("Vulnerability Scan"), "", m.viewport.View())
+}
+
+func (m ScanModel) IsShown() bool {
+	return m.show
+}
+
+func (m ScanModel) ShortHelp() []key.Binding {
+	return []key.Binding{m.keyMap.Up, m.keyMap.Down, m.keyMap.Close}
+}
+
+func (m ScanModel) FullHelp() [][]key.Binding {
+	return [][]key.Binding{m.ShortHelp()}
+}
+
+func (m *ScanModel) SetSize(w, h int) {
+	m.viewport.Width = w
+	m.viewport.Height = max(h-2, 0)
+}
+
+func (m ScanModel) scanCmd() tea.Cmd {
+	type target struct {
+		name     string
+		lister   executors.InstalledLister
+		proposed map[string]string
+	}
+	var targets []target
+	for name, pkg := range m.pkglists {
+		if lister, ok := pkg.executor.(executors.InstalledLister); ok {
+			targets = append(targets, target{name, lister, proposedVersions(pkg.packages())})
+		}
+	}
+
+	return func() tea.Msg {
+		var msg scanFinishedMsg
+		for _, t := range targets {
+			results, err := scanExecutor(t.name, t.lister, m.db, t.proposed)
+			if err != nil {
+				msg.errs = append(msg.errs, fmt.Errorf("[%s] %w", t.name, err))
+				continue
+			}
+			msg.results = append(msg.results, results...)
+		}
+		sortScanResults(msg.results)
+		return msg
+	}
+}
+
+// ScanPackages checks every installed package against the OSV database and writes the report to w
+func ScanPackages(config Config, w io.Writer) error {
+	if config.OSVDatabase == "" {
+		return fmt.Errorf("--osv-db is required to scan packages")
+	}
+	db, err := osv.Load(config.OSVDatabase)
+	if err != nil {
+		return fmt.Errorf("failed to load the OSV database: %w", err)
+	}
+
+	targets := map[string]executors.Executor{
+		PACKAGE_MANAGER_APT: &executors.AptExecutor{},
+		PACKAGE_MANAGER_NPM: &executors.NpmExecutor{},
+		PACKAGE_MANAGER_GEM: &executors.GemExecutor{},
+	}
+
+	var results []ScanResult
+	for name, e := range targets {
+		if v, ok := config.Excludes[name]; (ok && v) || !e.Valid() {
+			continue
+		}
+		pkgs, err := e.GetPackages("")
+		if err != nil {
+			log.Printf("[%s] Failed to check for updates, proposed versions are unknown: %v", name, err)
+		}
+		r, err := scanExecutor(name, e.(executors.InstalledLister), db, proposedVersions(pkgs))
+		if err != nil {
+			return fmt.Errorf("[%s] %w", name, err)
+		}
+		results = append(results, r...)
+	}
+	sortScanResults(results)
+
+	return writeScanResults(w, results)
+}
+
+func scanExecutor(name string, lister executors.InstalledLister, db *osv.Database, proposed map[string]string) ([]ScanResult, error) {
+	installed, err := lister.GetInstalled()
+	if err != nil {
+		return nil, err
+	}
+
+	ecosystem := db.Ecosystem(lister.Ecosystem())
+	var results []ScanResult
+	for _, pkg := range installed {
+		src, version, proposedVersion := pkg.Name, pkg.OldVersion, proposed[pkg.Name]
+		if pkg.Source != "" {
+			// the advisories of Debian and Ubuntu are keyed by the source package
+			src, version = pkg.Source, pkg.SourceVersion
+			proposedVersion = proposedSourceVersion(pkg, proposedVersion)
+		}
+		for _, f := range db.Scan(ecosystem, src, version, proposedVersion) {
+			results = append(results, ScanResult{
+				Manager:   name,
+				Ecosystem: ecosystem,
+				Package:   pkg.Name,
+				Version:   pkg.OldVersion,
+				Finding:   f,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// proposedSourceVersion returns the version of the source package the binary package would be updated to,
+// which is only known when the binary package is versioned like its source, apart from a binNMU suffix such as +b1
+func proposedSourceVersion(pkg *executors.PackageInfo, proposed string) string {
+	if proposed == "" || binNMUPattern.ReplaceAllString(pkg.OldVersion, "") != pkg.SourceVersion {
+		return ""
+	}
+	return binNMUPattern.ReplaceAllString(proposed, "")
+}
+
+func proposedVersions(pkgs []*executors.PackageInfo) map[string]string {
+	result := map[string]string{}
+	for _, pkg := range pkgs {
+		result[pkg.Name] = pkg.NewVersion
+	}
+	return result
+}
+
+func sortScanResults(results []ScanResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Manager != results[j].Manager {
+			return results[i].Manager < results[j].Manager
+		}
+		return results[i].Package < results[j].Package
+	})
+}
+
+func writeScanResults(w io.Writer, results []ScanResult) error {
+	if len(results) == 0 {
+		_, err := fmt.Fprintln(w, "No known vulnerabilities found")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "MANAGER\tPACKAGE\tINSTALLED\tADVISORY\tSEVERITY\tFIXED IN\tPROPOSED\tRESOLVED")
+	for _, r := range results {
+		f := r.Finding
+		resolved := "no"
+		if f.Resolved(r.Ecosystem) {
+			resolved = "yes"
+		}
+		pkg := r.Package
+		if f.Name != r.Package {
+			pkg += " (" + f.Name + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Manager,
+			pkg,
+			r.Version,
+			strings.Join(f.Vulnerability.CVEs(), ","),
+			f.Vulnerability.SeverityLabel(),
+			orDash(f.FixedVersion),
+			orDash(f.ProposedVersion),
+			resolved,
+		)
+	}
+
+	return tw.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
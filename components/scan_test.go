@@ -0,0 +1,87 @@
+package components
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ymtdzzz/lazypkg/executors"
+	"github.com/ymtdzzz/lazypkg/osv"
+)
+
+type fakeLister struct {
+	pkgs      []*executors.PackageInfo
+	ecosystem string
+}
+
+func (l fakeLister) GetInstalled() ([]*executors.PackageInfo, error) {
+	return l.pkgs, nil
+}
+
+func (l fakeLister) Ecosystem() string {
+	if l.ecosystem == "" {
+		return osv.EcosystemNPM
+	}
+	return l.ecosystem
+}
+
+func TestScanExecutor(t *testing.T) {
+	db, err := osv.Load("../osv/testdata")
+	require.Nil(t, err)
+
+	lister := fakeLister{pkgs: []*executors.PackageInfo{
+		{Name: "semver", OldVersion: "7.5.1"},
+		{Name: "corepack", OldVersion: "0.29.4"},
+	}}
+	results, err := scanExecutor("npm", lister, db, map[string]string{"semver": "7.6.0"})
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+
+	var sb strings.Builder
+	require.Nil(t, writeScanResults(&sb, results))
+	assert.Equal(t, `MANAGER  PACKAGE  INSTALLED  ADVISORY        SEVERITY  FIXED IN  PROPOSED  RESOLVED
+npm      semver   7.5.1      CVE-2022-25883  HIGH      7.5.2     7.6.0     yes
+`, sb.String())
+}
+
+func TestScanExecutorSourcePackages(t *testing.T) {
+	db, err := osv.Load("../osv/testdata")
+	require.Nil(t, err)
+
+	lister := fakeLister{ecosystem: "Ubuntu:24.04", pkgs: []*executors.PackageInfo{
+		{Name: "libssl3t64", OldVersion: "3.0.13-0ubuntu3.1", Source: "openssl", SourceVersion: "3.0.13-0ubuntu3.1"},
+		{Name: "openssl", OldVersion: "3.0.13-0ubuntu3.1", Source: "openssl", SourceVersion: "3.0.13-0ubuntu3.1"},
+		// a binary package named like an advisory is not matched on its own name
+		{Name: "openssl-provider-legacy", OldVersion: "3.0.13-0ubuntu3.4", Source: "openssl", SourceVersion: "3.0.13-0ubuntu3.4"},
+	}}
+	results, err := scanExecutor("apt", lister, db, map[string]string{"libssl3t64": "3.0.13-0ubuntu3.4"})
+	require.Nil(t, err)
+	sortScanResults(results)
+
+	var sb strings.Builder
+	require.Nil(t, writeScanResults(&sb, results))
+	assert.Equal(t, `MANAGER  PACKAGE               INSTALLED          ADVISORY       SEVERITY  FIXED IN           PROPOSED           RESOLVED
+apt      libssl3t64 (openssl)  3.0.13-0ubuntu3.1  CVE-2024-5535  LOW       3.0.13-0ubuntu3.2  3.0.13-0ubuntu3.4  yes
+apt      openssl               3.0.13-0ubuntu3.1  CVE-2024-5535  LOW       3.0.13-0ubuntu3.2  -                  no
+`, sb.String())
+}
+
+func TestProposedSourceVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		pkg      *executors.PackageInfo
+		proposed string
+		want     string
+	}{
+		{"same version", &executors.PackageInfo{OldVersion: "1.2-3", SourceVersion: "1.2-3"}, "1.2-4", "1.2-4"},
+		{"binNMU", &executors.PackageInfo{OldVersion: "1.2-3+b1", SourceVersion: "1.2-3"}, "1.2-4+b2", "1.2-4"},
+		{"own version", &executors.PackageInfo{OldVersion: "4:13.2.0-7", SourceVersion: "1.214"}, "4:13.2.0-8", ""},
+		{"up to date", &executors.PackageInfo{OldVersion: "1.2-3", SourceVersion: "1.2-3"}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, proposedSourceVersion(tt.pkg, tt.proposed))
+		})
+	}
+}
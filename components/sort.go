@@ -0,0 +1,108 @@
+package components
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/ymtdzzz/lazypkg/executors"
+)
+
+type sortMode string
+
+const (
+	sortByName     sortMode = "name"
+	sortBySeverity sortMode = "severity"
+	sortByUpdated  sortMode = "updated"
+	sortBySize     sortMode = "size"
+	sortBySecurity sortMode = "security"
+)
+
+// sortModes are cycled in this order
+var sortModes = []sortMode{sortByName, sortBySeverity, sortByUpdated, sortBySize, sortBySecurity}
+
+// severityRanks orders the update types from the most disruptive one
+var severityRanks = map[string]int{
+	executors.UpdateTypeMajor: 0,
+	executors.UpdateTypeMinor: 1,
+	executors.UpdateTypePatch: 2,
+}
+
+func parseSortMode(s string) sortMode {
+	if slices.Contains(sortModes, sortMode(s)) {
+		return sortMode(s)
+	}
+	return sortByName
+}
+
+func (s sortMode) next() sortMode {
+	i := slices.Index(sortModes, s)
+	return sortModes[(i+1)%len(sortModes)]
+}
+
+// sortPackages returns the packages sorted by the mode, then by name.
+// When grouped, the packages are sorted by group first and the ones without a group come last.
+func sortPackages(pkgs []*executors.PackageInfo, mode sortMode, group bool) []*executors.PackageInfo {
+	sorted := slices.Clone(pkgs)
+	slices.SortStableFunc(sorted, func(a, b *executors.PackageInfo) int {
+		if group {
+			if c := compareGroups(a.Group, b.Group); c != 0 {
+				return c
+			}
+		}
+		if c := comparePackages(a, b, mode); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return sorted
+}
+
+func comparePackages(a, b *executors.PackageInfo, mode sortMode) int {
+	switch mode {
+	case sortBySeverity:
+		return cmp.Compare(severityRank(a), severityRank(b))
+	case sortByUpdated:
+		// the least recently updated first
+		if a.Updated.IsZero() || b.Updated.IsZero() {
+			return compareFlags(a.Updated.IsZero(), b.Updated.IsZero())
+		}
+		return a.Updated.Compare(b.Updated)
+	case sortBySize:
+		// the largest first
+		if a.Size == 0 || b.Size == 0 {
+			return compareFlags(a.Size == 0, b.Size == 0)
+		}
+		return cmp.Compare(b.Size, a.Size)
+	case sortBySecurity:
+		return compareFlags(!a.Security, !b.Security)
+	}
+	return 0
+}
+
+func severityRank(pkg *executors.PackageInfo) int {
+	if r, ok := severityRanks[executors.ClassifyUpdate(pkg)]; ok {
+		return r
+	}
+	return len(severityRanks)
+}
+
+// compareFlags puts the flagged value after the other, e.g. unknown sizes
+func compareFlags(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
+
+// compareGroups sorts the groups by name, putting packages without a group last
+func compareGroups(a, b string) int {
+	if a == "" || b == "" {
+		return compareFlags(a == "", b == "")
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,100 @@
+package components
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ymtdzzz/lazypkg/executors"
+)
+
+func TestSortPackages(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	pkgs := []*executors.PackageInfo{
+		{Name: "ruby", OldVersion: "3.2.0", NewVersion: "3.3.0", Size: 300, Updated: day(3), Group: "homebrew/core"},
+		{Name: "curl", OldVersion: "7.68.0", NewVersion: "7.68.1", Security: true, Group: "homebrew/core"},
+		{Name: "node", OldVersion: "16.14.0", NewVersion: "18.15.0", Size: 100, Updated: day(1), Group: "homebrew/core"},
+		{Name: "lazypkg", OldVersion: "0.1.0", NewVersion: "0.1.1", Size: 200, Updated: day(2), Group: "ymtdzzz/tap"},
+		{Name: "git", OldVersion: "abc", NewVersion: "def"},
+	}
+
+	tests := []struct {
+		name  string
+		mode  sortMode
+		group bool
+		want  []string
+	}{
+		{
+			name: "name",
+			mode: sortByName,
+			want: []string{"curl", "git", "lazypkg", "node", "ruby"},
+		},
+		{
+			name: "severity",
+			mode: sortBySeverity,
+			want: []string{"node", "ruby", "curl", "lazypkg", "git"},
+		},
+		{
+			name: "least recently updated first",
+			mode: sortByUpdated,
+			want: []string{"node", "lazypkg", "ruby", "curl", "git"},
+		},
+		{
+			name: "largest first",
+			mode: sortBySize,
+			want: []string{"ruby", "lazypkg", "node", "curl", "git"},
+		},
+		{
+			name: "security first",
+			mode: sortBySecurity,
+			want: []string{"curl", "git", "lazypkg", "node", "ruby"},
+		},
+		{
+			name:  "grouped",
+			mode:  sortBySize,
+			group: true,
+			want:  []string{"ruby", "node", "curl", "lazypkg", "git"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, pkg := range sortPackages(pkgs, tt.mode, tt.group) {
+				got = append(got, pkg.Name)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSortModeNext(t *testing.T) {
+	assert.Equal(t, sortBySeverity, sortByName.next())
+	assert.Equal(t, sortByName, sortBySecurity.next())
+	assert.Equal(t, sortByName, parseSortMode("unknown"))
+	assert.Equal(t, sortBySize, parseSortMode("size"))
+}
+
+func TestGetPackageItemsGroups(t *testing.T) {
+	pkgs := []*executors.PackageInfo{
+		{Name: "@angular/cli", Group: "@angular"},
+		{Name: "@angular/core", Group: "@angular"},
+		{Name: "corepack"},
+	}
+
+	var got []string
+	for _, li := range getPackageItems(pkgs, true) {
+		i := li.(item)
+		if i.header {
+			got = append(got, "header:"+i.title)
+		} else {
+			got = append(got, i.title)
+		}
+	}
+	assert.Equal(t, []string{"header:@angular", "@angular/cli", "@angular/core", "header:", "corepack"}, got)
+
+	assert.Len(t, getPackageItems(pkgs, false), 3)
+	assert.Len(t, getPackageItems([]*executors.PackageInfo{{Name: "curl"}}, true), 1)
+}
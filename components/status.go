@@ -0,0 +1,298 @@
+package components
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ymtdzzz/lazypkg/executors"
+)
+
+var statusStyle = lipgloss.NewStyle().PaddingLeft(2)
+
+// statusOp is the update running for a manager
+type statusOp struct {
+	pkgs  int
+	start time.Time
+}
+
+// statusResult is the outcome of an update of the run
+type statusResult struct {
+	mgr  string
+	pkgs int
+	// failed is the number of the packages which failed, the others succeeded unless skipped
+	failed  int
+	elapsed time.Duration
+	err     error
+	skipped bool
+}
+
+// StatusModel is the status bar of the updates, showing the running and the queued ones and the results of the run.
+// A run lasts from an update started while none is left until every update of it is over,
+// and it is summarized in a dialog when a bulk update, an update of all managers or a plan was started in it.
+type StatusModel struct {
+	running map[string]statusOp
+	// queued is the number of packages per manager waiting to be updated, e.g. in a later phase of a plan
+	queued  map[string]int
+	start   time.Time
+	elapsed time.Duration
+	results []statusResult
+	// bulk is set when the run is to be summarized
+	bulk bool
+	// shown is set from the first run on
+	shown   bool
+	ticking bool
+	w       int
+	now     func() time.Time
+}
+
+func NewStatusModel() StatusModel {
+	return StatusModel{
+		running: map[string]statusOp{},
+		queued:  map[string]int{},
+		now:     time.Now,
+	}
+}
+
+func statusTickCmd() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return statusTickMsg{}
+	})
+}
+
+func (m StatusModel) Update(msg tea.Msg) (StatusModel, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case allUpdateStartMsg:
+		cmds = append(cmds, m.begin())
+		m.bulk = true
+		for mgr, count := range msg.counts {
+			m.queued[mgr] += count
+		}
+	case planStartMsg:
+		cmds = append(cmds, m.begin())
+		m.bulk = true
+		for mgr, pkgs := range msg.plan {
+			m.queued[mgr] += len(pkgs)
+		}
+	case updatePackagesStartMsg:
+		cmds = append(cmds, m.begin())
+		m.bulk = m.bulk || msg.bulk
+		delete(m.queued, msg.name)
+		op, ok := m.running[msg.name]
+		if !ok {
+			op.start = m.now()
+		}
+		op.pkgs += len(msg.pkgs)
+		m.running[msg.name] = op
+	case updatePackagesFinishMsg:
+		if m.finish(msg) {
+			cmds = append(cmds, m.end())
+		}
+	case updatesSkippedMsg:
+		for mgr, count := range msg.counts {
+			delete(m.queued, mgr)
+			m.results = append(m.results, statusResult{mgr: mgr, pkgs: count, skipped: true})
+		}
+		cmds = append(cmds, m.end())
+	case statusTickMsg:
+		// the elapsed time is rendered again every second
+		m.ticking = !m.idle()
+		if m.ticking {
+			cmds = append(cmds, statusTickCmd())
+		}
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+// begin starts a new run unless one is going on
+func (m *StatusModel) begin() tea.Cmd {
+	var cmds []tea.Cmd
+	if m.idle() {
+		m.start = m.now()
+		m.elapsed = 0
+		m.results = nil
+		m.bulk = false
+	}
+	if !m.ticking {
+		m.ticking = true
+		cmds = append(cmds, statusTickCmd())
+	}
+	if !m.shown {
+		// the panes leave a line to the status bar
+		m.shown = true
+		cmds = append(cmds, updateLayoutCmd())
+	}
+	return tea.Batch(cmds...)
+}
+
+// finish records the result of the update and reports whether it belongs to the run
+func (m *StatusModel) finish(msg updatePackagesFinishMsg) bool {
+	count := len(msg.pkgs)
+	var elapsed time.Duration
+	if op, ok := m.running[msg.name]; ok {
+		elapsed = m.now().Sub(op.start)
+		op.pkgs -= count
+		if op.pkgs > 0 {
+			m.running[msg.name] = op
+		} else {
+			delete(m.running, msg.name)
+		}
+	} else if queued, ok := m.queued[msg.name]; ok {
+		// the manager failed before the update started, e.g. as it is not available
+		if count == 0 {
+			count = queued
+		}
+		delete(m.queued, msg.name)
+	} else {
+		return false
+	}
+
+	r := statusResult{
+		mgr:     msg.name,
+		pkgs:    count,
+		failed:  len(msg.failed),
+		elapsed: elapsed,
+		err:     msg.err,
+		skipped: errors.Is(msg.err, executors.ErrPassword),
+	}
+	if r.err != nil && !r.skipped && r.failed == 0 {
+		// the packages are not told apart, e.g. when the manager is not available
+		r.failed = count
+	}
+	m.results = append(m.results, r)
+	return true
+}
+
+// end finishes the run when no update is left, showing the summary of a bulk run
+func (m *StatusModel) end() tea.Cmd {
+	if !m.idle() {
+		return nil
+	}
+	m.elapsed = m.now().Sub(m.start)
+	if !m.bulk {
+		return nil
+	}
+	return showInfoCmd(m.summary(), m.summaryDetail())
+}
+
+func (m StatusModel) idle() bool {
+	return len(m.running) == 0 && len(m.queued) == 0
+}
+
+// counts returns the number of packages by result in the run
+func (m StatusModel) counts() (succeeded, failed, skipped int) {
+	for _, r := range m.results {
+		if r.skipped {
+			skipped += r.pkgs
+			continue
+		}
+		failed += r.failed
+		succeeded += r.pkgs - r.failed
+	}
+	return succeeded, failed, skipped
+}
+
+func (m StatusModel) summary() string {
+	succeeded, failed, skipped := m.counts()
+	return fmt.Sprintf("Updates finished in %s: %d succeeded, %d failed, %d skipped",
+		formatElapsed(m.elapsed), succeeded, failed, skipped)
+}
+
+// summaryDetail lists the results per update in lines fitting in the dialog
+func (m StatusModel) summaryDetail() string {
+	var rows []string
+	for i, r := range m.results {
+		if i == planMaxEntries {
+			rows = append(rows, fmt.Sprintf("... and %d more", len(m.results)-planMaxEntries))
+			break
+		}
+		var line string
+		switch {
+		case r.skipped && r.err != nil:
+			line = fmt.Sprintf("- %s: %d skipped: %v", r.mgr, r.pkgs, r.err)
+		case r.skipped:
+			line = fmt.Sprintf("- %s: %d skipped", r.mgr, r.pkgs)
+		case r.failed > 0 && r.failed < r.pkgs:
+			first, _, _ := strings.Cut(r.err.Error(), "\n")
+			line = fmt.Sprintf("✗ %s: %d of %d failed: %s", r.mgr, r.failed, r.pkgs, first)
+		case r.failed > 0:
+			first, _, _ := strings.Cut(r.err.Error(), "\n")
+			line = fmt.Sprintf("✗ %s: %d failed: %s", r.mgr, r.failed, first)
+		default:
+			line = fmt.Sprintf("✓ %s: %d updated in %s", r.mgr, r.pkgs, formatElapsed(r.elapsed))
+		}
+		rows = append(rows, truncate(line, DIALOG_MAX_LINE_LENGTH))
+	}
+	return strings.Join(rows, "\n")
+}
+
+func (m StatusModel) View() string {
+	if !m.shown {
+		return ""
+	}
+
+	var parts []string
+	if m.idle() {
+		parts = append(parts, "Last run finished in "+formatElapsed(m.elapsed))
+	} else {
+		if len(m.running) > 0 {
+			counts := map[string]int{}
+			for mgr, op := range m.running {
+				counts[mgr] = op.pkgs
+			}
+			parts = append(parts, "Running "+describeCounts(counts))
+		}
+		if len(m.queued) > 0 {
+			parts = append(parts, "Queued "+describeCounts(m.queued))
+		}
+		parts = append(parts, "Elapsed "+formatElapsed(m.now().Sub(m.start)))
+	}
+
+	succeeded, failed, skipped := m.counts()
+	plain := strings.Join(append(parts, fmt.Sprintf("%d succeeded, %d failed, %d skipped", succeeded, failed, skipped)), " • ")
+	if m.w > 0 && lipgloss.Width(plain) > m.w-statusStyle.GetHorizontalFrameSize() {
+		return statusStyle.Render(truncate(plain, max(m.w-statusStyle.GetHorizontalFrameSize(), 1)))
+	}
+	failedStr := fmt.Sprintf("%d failed", failed)
+	if failed > 0 {
+		failedStr = statusFailedStyle.Render(failedStr)
+	}
+	parts = append(parts, fmt.Sprintf("%d succeeded, %s, %d skipped", succeeded, failedStr, skipped))
+	return statusStyle.Render(strings.Join(parts, " • "))
+}
+
+// Height is the number of lines of the status bar, which is hidden until the first update
+func (m StatusModel) Height() int {
+	if !m.shown {
+		return 0
+	}
+	return 1
+}
+
+func (m *StatusModel) SetWidth(w int) {
+	m.w = w
+}
+
+// describeCounts lists the managers with the number of packages, e.g. "apt (3), npm (2)"
+func describeCounts(counts map[string]int) string {
+	mgrs := make([]string, 0, len(counts))
+	for mgr := range counts {
+		mgrs = append(mgrs, mgr)
+	}
+	sort.Strings(mgrs)
+	for i, mgr := range mgrs {
+		mgrs[i] = fmt.Sprintf("%s (%d)", mgr, counts[mgr])
+	}
+	return strings.Join(mgrs, ", ")
+}
+
+func formatElapsed(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,135 @@
+package components
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/ymtdzzz/lazypkg/executors"
+)
+
+// newTestStatusModel returns the status bar with a clock moved forward by the test
+func newTestStatusModel() (StatusModel, *time.Time) {
+	m := NewStatusModel()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time {
+		return now
+	}
+	return m, &now
+}
+
+// summaryMsg returns the info dialog shown by the command, nil when there is none
+func summaryMsg(cmd tea.Cmd) *showDialogMsg {
+	if cmd == nil {
+		return nil
+	}
+	switch msg := cmd().(type) {
+	case showDialogMsg:
+		return &msg
+	case tea.BatchMsg:
+		for _, c := range msg {
+			if found := summaryMsg(c); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+func TestStatusModel(t *testing.T) {
+	m, now := newTestStatusModel()
+	assert.Equal(t, 0, m.Height())
+	assert.Equal(t, "", m.View())
+
+	m, _ = m.Update(allUpdateStartMsg{counts: map[string]int{"apt": 3, "npm": 2, "homebrew": 1}})
+	m, _ = m.Update(updatePackagesStartMsg{name: "apt", pkgs: []string{"curl", "git", "vim"}})
+	m, _ = m.Update(updatePackagesStartMsg{name: "npm", pkgs: []string{"react", "lodash"}})
+	*now = now.Add(65 * time.Second)
+	assert.Equal(t, 1, m.Height())
+	assert.Equal(t, "  Running apt (3), npm (2) • Queued homebrew (1) • Elapsed 1m5s • 0 succeeded, 0 failed, 0 skipped", m.View())
+
+	var cmd tea.Cmd
+	m, cmd = m.Update(updatePackagesFinishMsg{name: "npm", pkgs: []string{"react", "lodash"}, err: errors.New("npm exited with 1\nnpm ERR! code E404")})
+	assert.Nil(t, summaryMsg(cmd))
+	m, _ = m.Update(updatePackagesStartMsg{name: "homebrew", pkgs: []string{"wget"}})
+	m, _ = m.Update(updatePackagesFinishMsg{name: "homebrew", pkgs: []string{"wget"}, err: executors.ErrPassword})
+	*now = now.Add(5 * time.Second)
+	m, cmd = m.Update(updatePackagesFinishMsg{name: "apt", pkgs: []string{"curl", "git", "vim"}})
+
+	dialog := summaryMsg(cmd)
+	if assert.NotNil(t, dialog) {
+		assert.True(t, dialog.info)
+		assert.Equal(t, "Updates finished in 1m10s: 3 succeeded, 2 failed, 1 skipped", dialog.msg)
+		assert.Equal(t, "✗ npm: 2 failed: npm exited with 1\n- homebrew: 1 skipped: password is required\n✓ apt: 3 updated in 1m10s", dialog.detail)
+	}
+	assert.Equal(t, "  Last run finished in 1m10s • 3 succeeded, 2 failed, 1 skipped", m.View())
+
+	// a new run starts from zero, and a single update is not summarized
+	m, _ = m.Update(updatePackagesStartMsg{name: "apt", pkgs: []string{"curl"}})
+	m, cmd = m.Update(updatePackagesFinishMsg{name: "apt", pkgs: []string{"curl"}})
+	assert.Nil(t, summaryMsg(cmd))
+	assert.Equal(t, "  Last run finished in 0s • 1 succeeded, 0 failed, 0 skipped", m.View())
+
+	// a bulk update is summarized even when it updated a single package
+	m, _ = m.Update(updatePackagesStartMsg{name: "apt", pkgs: []string{"curl"}, bulk: true})
+	m, cmd = m.Update(updatePackagesFinishMsg{name: "apt", pkgs: []string{"curl"}})
+	dialog = summaryMsg(cmd)
+	if assert.NotNil(t, dialog) {
+		assert.Equal(t, "Updates finished in 0s: 1 succeeded, 0 failed, 0 skipped", dialog.msg)
+		assert.Equal(t, "✓ apt: 1 updated in 0s", dialog.detail)
+	}
+
+	// a finish of no update of the run is ignored
+	m, cmd = m.Update(updatePackagesFinishMsg{name: "npm", pkgs: []string{"react"}})
+	assert.Nil(t, cmd)
+	assert.Len(t, m.results, 1)
+}
+
+func TestStatusModelPlan(t *testing.T) {
+	m, _ := newTestStatusModel()
+	m.SetWidth(60)
+
+	m, _ = m.Update(planStartMsg{name: "weekly", plan: Plan{
+		"apt":    {"curl"},
+		"gem":    {"rails"},
+		"npm":    {"react", "lodash"},
+		"docker": {"nginx:latest"},
+	}})
+	// gem is not available, which fails its phase before it starts
+	m, _ = m.Update(updatePackagesFinishMsg{name: "gem", err: errors.New("gem is not available")})
+	m, _ = m.Update(updatePackagesStartMsg{name: "apt", pkgs: []string{"curl"}})
+	assert.Equal(t, "  Running apt (1) • Queued docker (1), npm (2) • Elapsed 0s…", m.View())
+
+	m, cmd := m.Update(updatePackagesFinishMsg{name: "apt", pkgs: []string{"curl"}})
+	assert.Nil(t, summaryMsg(cmd))
+	m, _ = m.Update(updatePackagesStartMsg{name: "docker", pkgs: []string{"nginx:latest"}})
+	m, _ = m.Update(updatePackagesFinishMsg{name: "docker", pkgs: []string{"nginx:latest"}})
+	m, cmd = m.Update(updatesSkippedMsg{counts: map[string]int{"npm": 2}})
+
+	dialog := summaryMsg(cmd)
+	if assert.NotNil(t, dialog) {
+		assert.Equal(t, "Updates finished in 0s: 2 succeeded, 1 failed, 2 skipped", dialog.msg)
+		assert.Equal(t, "✗ gem: 1 failed: gem is not available\n✓ apt: 1 updated in 0s\n✓ docker: 1 updated in 0s\n- npm: 2 skipped", dialog.detail)
+	}
+}
+
+func TestStatusModelPartialFailure(t *testing.T) {
+	m, _ := newTestStatusModel()
+
+	m, _ = m.Update(updatePackagesStartMsg{name: "docker", pkgs: []string{"nginx", "redis:7", "postgres:16"}, bulk: true})
+	err := executors.UpdateErrors{"nginx": errors.New("pull access denied")}
+	m, cmd := m.Update(updatePackagesFinishMsg{
+		name:   "docker",
+		pkgs:   []string{"nginx", "redis:7", "postgres:16"},
+		err:    err,
+		failed: map[string]error{"nginx": err["nginx"]},
+	})
+
+	dialog := summaryMsg(cmd)
+	if assert.NotNil(t, dialog) {
+		assert.Equal(t, "Updates finished in 0s: 2 succeeded, 1 failed, 0 skipped", dialog.msg)
+		assert.Equal(t, "✗ docker: 1 of 3 failed: Failed to update nginx", dialog.detail)
+	}
+}
@@ -0,0 +1,194 @@
+package components
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ymtdzzz/lazypkg/executors"
+)
+
+// Theme is the colors of the TUI, as ANSI color numbers or hex codes
+type Theme struct {
+	// Base is the built-in theme giving the colors which are not set, dark when unset
+	Base string `yaml:"base,omitempty"`
+	// Accent is the color of the selected item
+	Accent lipgloss.Color `yaml:"accent,omitempty"`
+	// Muted is the color of the descriptions and the panes without focus
+	Muted   lipgloss.Color `yaml:"muted,omitempty"`
+	Danger  lipgloss.Color `yaml:"danger,omitempty"`
+	Warning lipgloss.Color `yaml:"warning,omitempty"`
+	Major   lipgloss.Color `yaml:"major,omitempty"`
+	Minor   lipgloss.Color `yaml:"minor,omitempty"`
+	Patch   lipgloss.Color `yaml:"patch,omitempty"`
+	// Bold emphasizes the selected item and the badges
+	Bold bool `yaml:"bold,omitempty"`
+}
+
+const (
+	themeDark         = "dark"
+	themeLight        = "light"
+	themeHighContrast = "high-contrast"
+)
+
+var builtinThemes = map[string]Theme{
+	themeDark: {
+		Accent:  "170",
+		Muted:   "#777777",
+		Danger:  "#FF5F5F",
+		Warning: "#FFAF00",
+		Major:   "#FF875F",
+		Minor:   "#FFD75F",
+		Patch:   "#87D787",
+	},
+	themeLight: {
+		Accent:  "#AF00AF",
+		Muted:   "#5F5F5F",
+		Danger:  "#D70000",
+		Warning: "#AF5F00",
+		Major:   "#D75F00",
+		Minor:   "#AF8700",
+		Patch:   "#008700",
+	},
+	themeHighContrast: {
+		Accent:  "#FFFF00",
+		Muted:   "#D0D0D0",
+		Danger:  "#FF0000",
+		Warning: "#FFAF00",
+		Major:   "#FF5FFF",
+		Minor:   "#00FFFF",
+		Patch:   "#00FF00",
+		Bold:    true,
+	},
+}
+
+// The styles are set by applyTheme
+var (
+	blurTitleStyle     lipgloss.Style
+	itemDescStyle      lipgloss.Style
+	selectedItemStyle  lipgloss.Style
+	blurItemStyle      lipgloss.Style
+	securityBadgeStyle lipgloss.Style
+	failedBadgeStyle   lipgloss.Style
+	checkErrorStyle    lipgloss.Style
+	updateTypeStyles   map[string]lipgloss.Style
+	planRemovalStyle   lipgloss.Style
+	detailLabelStyle   lipgloss.Style
+	// searchMatchStyle highlights the matches of the log search, and currentMatchStyle the one jumped to
+	searchMatchStyle  lipgloss.Style
+	currentMatchStyle lipgloss.Style
+	// statusFailedStyle is the count of the failed updates in the status bar
+	statusFailedStyle lipgloss.Style
+)
+
+func init() {
+	applyTheme(builtinThemes[themeDark], false)
+}
+
+// theme returns the theme chosen with --theme or in the config file, filling the unset colors of a user theme from its base
+func (c Config) theme() (Theme, error) {
+	name := c.Theme
+	if name == "" {
+		name = c.File.Theme
+	}
+	if name == "" {
+		name = themeDark
+	}
+
+	if t, ok := c.File.Themes[name]; ok {
+		base := t.Base
+		if base == "" {
+			base = themeDark
+		}
+		b, ok := builtinThemes[base]
+		if !ok {
+			return Theme{}, fmt.Errorf("theme %s is based on unknown theme %s, valid bases: %s", name, base, strings.Join(slices.Sorted(maps.Keys(builtinThemes)), ", "))
+		}
+		return t.merge(b), nil
+	}
+	if t, ok := builtinThemes[name]; ok {
+		return t, nil
+	}
+
+	names := slices.Collect(maps.Keys(builtinThemes))
+	names = append(names, slices.Collect(maps.Keys(c.File.Themes))...)
+	slices.Sort(names)
+	return Theme{}, fmt.Errorf("unknown theme %s, valid themes: %s", name, strings.Join(names, ", "))
+}
+
+// merge fills the unset colors with the ones of the base
+func (t Theme) merge(base Theme) Theme {
+	for _, c := range []struct{ dst, src *lipgloss.Color }{
+		{&t.Accent, &base.Accent},
+		{&t.Muted, &base.Muted},
+		{&t.Danger, &base.Danger},
+		{&t.Warning, &base.Warning},
+		{&t.Major, &base.Major},
+		{&t.Minor, &base.Minor},
+		{&t.Patch, &base.Patch},
+	} {
+		if *c.dst == "" {
+			*c.dst = *c.src
+		}
+	}
+	t.Bold = t.Bold || base.Bold
+	return t
+}
+
+// noColor reports whether the colors are disabled with NO_COLOR (https://no-color.org)
+func noColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// applyTheme sets the styles of the theme. Without colors, which lipgloss drops under NO_COLOR,
+// the muted texts are faint and the accented ones bold so that the focus can still be told.
+func applyTheme(t Theme, noColor bool) {
+	fg := func(c lipgloss.Color) lipgloss.Style {
+		return lipgloss.NewStyle().Foreground(c).Bold(t.Bold)
+	}
+	muted := lipgloss.NewStyle().Foreground(t.Muted).Faint(noColor)
+
+	blurTitleStyle = titleStyle.Inherit(muted)
+	itemDescStyle = muted
+	selectedItemStyle = lipgloss.NewStyle().PaddingLeft(2).Foreground(t.Accent).Bold(t.Bold || noColor)
+	blurItemStyle = muted
+	securityBadgeStyle = fg(t.Danger).Bold(true)
+	failedBadgeStyle = fg(t.Danger).Bold(true)
+	checkErrorStyle = fg(t.Warning)
+	updateTypeStyles = map[string]lipgloss.Style{
+		executors.UpdateTypeMajor: fg(t.Major),
+		executors.UpdateTypeMinor: fg(t.Minor),
+		executors.UpdateTypePatch: fg(t.Patch),
+	}
+	planRemovalStyle = fg(t.Danger)
+	detailLabelStyle = muted
+	searchMatchStyle = lipgloss.NewStyle().Reverse(true)
+	currentMatchStyle = searchMatchStyle.Foreground(t.Accent).Bold(true)
+	statusFailedStyle = fg(t.Danger)
+}
+
+// iconLabels replace the Nerd Font icons with --no-icons
+var iconLabels = map[rune]string{
+	ICON_APT:      "apt",
+	ICON_HOMEBREW: "brw",
+	ICON_DOCKER:   "dkr",
+	ICON_PODMAN:   "pod",
+	ICON_NERDCTL:  "ctr",
+	ICON_COMPOSE:  "cmp",
+	ICON_NPM:      "npm",
+	ICON_GEM:      "gem",
+	ICON_ALL:      "all",
+}
+
+// showIcons is unset with --no-icons for the terminals without a Nerd Font
+var showIcons = true
+
+func iconString(icon rune) string {
+	if l, ok := iconLabels[icon]; ok && !showIcons {
+		return "[" + l + "]"
+	}
+	return string(icon)
+}
@@ -0,0 +1,111 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfigTheme(t *testing.T) {
+	var file FileConfig
+	err := yaml.Unmarshal([]byte(`theme: light
+themes:
+  solarized:
+    base: light
+    accent: "#268BD2"
+    muted: "245"
+  broken:
+    base: sepia
+`), &file)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		flag    string
+		file    FileConfig
+		want    Theme
+		wantErr string
+	}{
+		{
+			name: "default",
+			want: builtinThemes[themeDark],
+		},
+		{
+			name: "config file",
+			file: file,
+			want: builtinThemes[themeLight],
+		},
+		{
+			name: "flag over config file",
+			flag: themeHighContrast,
+			file: file,
+			want: builtinThemes[themeHighContrast],
+		},
+		{
+			name: "user theme",
+			flag: "solarized",
+			file: file,
+			want: Theme{
+				Base:    themeLight,
+				Accent:  "#268BD2",
+				Muted:   "245",
+				Danger:  builtinThemes[themeLight].Danger,
+				Warning: builtinThemes[themeLight].Warning,
+				Major:   builtinThemes[themeLight].Major,
+				Minor:   builtinThemes[themeLight].Minor,
+				Patch:   builtinThemes[themeLight].Patch,
+			},
+		},
+		{
+			name:    "unknown base",
+			flag:    "broken",
+			file:    file,
+			wantErr: "theme broken is based on unknown theme sepia",
+		},
+		{
+			name:    "unknown theme",
+			flag:    "sepia",
+			file:    file,
+			wantErr: "unknown theme sepia, valid themes: broken, dark, high-contrast, light, solarized",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Config{Theme: tt.flag, File: tt.file}
+			got, err := c.theme()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestApplyTheme(t *testing.T) {
+	defer applyTheme(builtinThemes[themeDark], false)
+
+	applyTheme(builtinThemes[themeLight], false)
+	assert.Equal(t, lipgloss.Color("#AF00AF"), selectedItemStyle.GetForeground())
+	assert.False(t, selectedItemStyle.GetBold())
+	assert.False(t, itemDescStyle.GetFaint())
+
+	applyTheme(builtinThemes[themeDark], true)
+	assert.True(t, selectedItemStyle.GetBold())
+	assert.True(t, itemDescStyle.GetFaint())
+	assert.True(t, blurTitleStyle.GetFaint())
+}
+
+func TestIconString(t *testing.T) {
+	defer func() { showIcons = true }()
+
+	assert.Equal(t, string(ICON_APT), iconString(ICON_APT))
+	showIcons = false
+	assert.Equal(t, "[apt]", iconString(ICON_APT))
+	assert.Equal(t, "[all]", iconString(ICON_ALL))
+	assert.Equal(t, "*", iconString('*'))
+}
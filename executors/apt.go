@@ -1,18 +1,29 @@
 package executors
 
 import (
-	"bufio"
 	"fmt"
-	"io"
 	"log"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/ymtdzzz/lazypkg/osv"
 )
 
-var aptPattern = regexp.MustCompile(`^([^\/]+)\/([^\s]+)\s+([^\s]+)\s+([^\s]+)\s\[([^\s]+)`)
+var (
+	aptPattern          = regexp.MustCompile(`^([^\/]+)\/([^\s]+)\s+([^\s]+)\s+([^\s]+)\s\[([^\s]+)`)
+	aptChangelogPattern = regexp.MustCompile(`^\S+ \(([^)]+)\) [^;]+;`)
+	aptPlanPattern      = regexp.MustCompile(`^(Inst|Remv) (\S+)(?: \[([^\]]+)\])?(?: \((\S+))?`)
+	aptURIPattern       = regexp.MustCompile(`^'[^']+' \S+ (\d+)`)
+)
 
-type AptExecutor struct{}
+type AptExecutor struct {
+	progress progressTracker
+}
 
 func (ae *AptExecutor) Valid() bool {
 	return cmdExists("apt")
@@ -26,31 +37,14 @@ func (ae *AptExecutor) GetPackages(password string) ([]*PackageInfo, error) {
 	cmd := exec.Command(cmds[0], cmds[1:]...)
 	cmd.Stdin = strings.NewReader(password + "\n")
 
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return packages, err
-	}
-
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return packages, err
-	}
-
-	if err := cmd.Start(); err != nil {
-		return packages, err
-	}
-
 	var passworderr bool
-	scanner := bufio.NewScanner(io.MultiReader(stdout, stderr))
-	for scanner.Scan() {
-		line := scanner.Text()
+	err := scanOutput(cmd, func(line string) {
 		log.Print(line)
 		if strings.Contains(line, "no password was provided") {
 			passworderr = true
 		}
-	}
-
-	if err := cmd.Wait(); err != nil {
+	})
+	if err != nil {
 		if passworderr {
 			return packages, ErrPassword
 		}
@@ -70,115 +64,364 @@ func (ae *AptExecutor) GetPackages(password string) ([]*PackageInfo, error) {
 		// NOTE: invalid row will be skipped
 		// TODO: log for verbose
 		if pkg, err := aptPackageFromString(line); err == nil {
+			pkg.Updated = aptInstalledTime(pkg.Name)
 			packages = append(packages, pkg)
 		}
 	}
+	aptSetInstalledSizes(packages)
 
 	return packages, nil
 }
 
 func (ae *AptExecutor) Update(pkg, password string, dryRun bool) error {
+	return ae.BulkUpdate([]string{pkg}, password, dryRun)
+}
+
+func (ae *AptExecutor) BulkUpdate(pkgs []string, password string, dryRun bool) error {
 	cmds := []string{"sudo", "-S", "apt", "install", "--only-upgrade"}
 	if dryRun {
 		cmds = append(cmds, "--dry-run")
 	}
-	cmds = append(cmds, pkg)
+	cmds = append(cmds, pkgs...)
+
+	progress := newStageProgress(aptProgressPatterns, pkgs)
+	ae.progress.start(progress)
+	defer ae.progress.stop()
 
 	log.Printf("Running %s", strings.Join(cmds, " "))
 	// #nosec G204: commands are not input values
 	cmd := exec.Command(cmds[0], cmds[1:]...)
 	cmd.Stdin = strings.NewReader(password + "\n")
 
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return err
-	}
-
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return err
-	}
-
-	if err := cmd.Start(); err != nil {
-		return err
-	}
-
-	var passworderr bool
-	scanner := bufio.NewScanner(io.MultiReader(stdout, stderr))
-	for scanner.Scan() {
-		line := scanner.Text()
+	var (
+		passworderr bool
+		tail        outputTail
+	)
+	err := scanOutput(cmd, func(line string) {
 		log.Print(line)
+		progress.read(line)
+		tail.add(line)
 		if strings.Contains(line, "no password was provided") {
 			passworderr = true
 		}
-	}
-
-	if err := cmd.Wait(); err != nil {
+	})
+	if err != nil {
 		if passworderr {
 			return ErrPassword
 		}
-		return err
+		return tail.wrap(err)
 	}
 
 	return nil
 }
 
-func (ae *AptExecutor) BulkUpdate(pkgs []string, password string, dryRun bool) error {
-	cmds := []string{"sudo", "-S", "apt", "install", "--only-upgrade"}
-	if dryRun {
-		cmds = append(cmds, "--dry-run")
+// Progress returns how far the running update of the package is, from the stages apt prints for it
+func (ae *AptExecutor) Progress(pkg string) (float64, bool) {
+	return ae.progress.progress(pkg)
+}
+
+func (ae *AptExecutor) GetDetail(pkg string) (*PackageDetail, error) {
+	log.Printf("Running apt-cache show --no-all-versions %s", pkg)
+	// #nosec G204: package name is taken from apt list output
+	cmd := exec.Command("apt-cache", "show", "--no-all-versions", pkg)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
 	}
-	cmds = append(cmds, pkgs...)
 
+	return aptDetailFromString(string(output))
+}
+
+func (ae *AptExecutor) GetChangelog(pkg *PackageInfo) (string, error) {
+	log.Printf("Running apt-get changelog %s", pkg.Name)
+	// #nosec G204: package name is taken from apt list output
+	cmd := exec.Command("apt-get", "changelog", pkg.Name)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	return aptChangelogSince(string(output), pkg.OldVersion), nil
+}
+
+func (ae *AptExecutor) Plan(pkgs []string) (*UpdatePlan, error) {
+	cmds := append([]string{"apt-get", "-s", "install", "--only-upgrade"}, pkgs...)
 	log.Printf("Running %s", strings.Join(cmds, " "))
 	// #nosec G204: commands are not input values
 	cmd := exec.Command(cmds[0], cmds[1:]...)
-	cmd.Stdin = strings.NewReader(password + "\n")
+	// the output is parsed so it must not be localized
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := aptPlanFromString(string(output))
+
+	// apt-get -s does not tell the download size, --print-uris lists the archives not downloaded yet with their sizes
+	cmds = append([]string{"apt-get", "--print-uris", "-qq", "install", "--only-upgrade"}, pkgs...)
+	log.Printf("Running %s", strings.Join(cmds, " "))
+	// #nosec G204: commands are not input values
+	cmd = exec.Command(cmds[0], cmds[1:]...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	if output, err := cmd.Output(); err == nil {
+		plan.DownloadSize = aptDownloadSizeFromString(string(output))
+	} else {
+		log.Printf("Failed to get the download size: %v", err)
+	}
 
-	stdout, err := cmd.StdoutPipe()
+	return plan, nil
+}
+
+func (ae *AptExecutor) GetInstalled() ([]*PackageInfo, error) {
+	log.Print("Running dpkg-query -W")
+	// advisories are keyed by the source packages, e.g. openssl for libssl3
+	cmd := exec.Command("dpkg-query", "-W", "-f", "${Package}\t${Version}\t${source:Package}\t${source:Version}\n")
+	output, err := cmd.Output()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	stderr, err := cmd.StderrPipe()
+	return installedFromTabSeparated(string(output)), nil
+}
+
+func (ae *AptExecutor) Ecosystem() string {
+	b, err := os.ReadFile("/etc/os-release")
 	if err != nil {
-		return err
+		return osv.EcosystemDebian
+	}
+	return aptEcosystemFromOSRelease(string(b))
+}
+
+func (ae *AptExecutor) Close() {}
+
+// aptSetInstalledSizes sets the sizes of the installed versions of the packages
+func aptSetInstalledSizes(packages []*PackageInfo) {
+	if len(packages) == 0 {
+		return
 	}
+	args := []string{"-W", "-f", "${Package}\t${Installed-Size}\n"}
+	for _, pkg := range packages {
+		args = append(args, pkg.Name)
+	}
+	log.Print("Running dpkg-query -W for the installed sizes")
+	// #nosec G204: package names are taken from apt list output
+	cmd := exec.Command("dpkg-query", args...)
+	// NOTE: dpkg-query exits with 1 when a package is not installed, the others are listed
+	output, _ := cmd.Output()
 
-	if err := cmd.Start(); err != nil {
-		return err
+	sizes := aptInstalledSizesFromString(string(output))
+	for _, pkg := range packages {
+		pkg.Size = sizes[pkg.Name]
 	}
+}
 
-	var passworderr bool
-	scanner := bufio.NewScanner(io.MultiReader(stdout, stderr))
-	for scanner.Scan() {
-		line := scanner.Text()
-		log.Print(line)
-		if strings.Contains(line, "no password was provided") {
-			passworderr = true
+// aptInstalledSizesFromString parses the sizes listed by dpkg-query in KiB, summing the architectures of a package
+func aptInstalledSizesFromString(input string) map[string]int64 {
+	sizes := map[string]int64{}
+	for _, line := range strings.Split(input, "\n") {
+		name, size, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		if kib, err := strconv.ParseInt(strings.TrimSpace(size), 10, 64); err == nil {
+			sizes[name] += kib * 1024
 		}
 	}
+	return sizes
+}
 
-	if err := cmd.Wait(); err != nil {
-		if passworderr {
-			return ErrPassword
+// aptInstalledTime returns when the package was installed from the file list dpkg writes on installs
+func aptInstalledTime(name string) time.Time {
+	matches, _ := filepath.Glob(filepath.Join("/var/lib/dpkg/info", name+"*.list"))
+	for _, m := range matches {
+		// skip other packages with the name as a prefix, e.g. curl and curlftpfs
+		base := strings.TrimSuffix(filepath.Base(m), ".list")
+		if base != name && !strings.HasPrefix(base, name+":") {
+			continue
+		}
+		if info, err := os.Stat(m); err == nil {
+			return info.ModTime()
 		}
-		return err
 	}
-
-	return nil
+	return time.Time{}
 }
 
-func (ae *AptExecutor) Close() {}
-
 func aptPackageFromString(input string) (*PackageInfo, error) {
 	matches := aptPattern.FindStringSubmatch(input)
 	if len(matches) < 6 {
 		return nil, fmt.Errorf("invalid input provided: %s", input)
 	}
+	security := false
+	for _, suite := range strings.Split(matches[2], ",") {
+		if strings.HasSuffix(suite, "-security") {
+			security = true
+		}
+	}
 	return &PackageInfo{
 		Name:       matches[1],
 		OldVersion: matches[5],
 		NewVersion: matches[3],
+		Origin:     matches[2],
+		Security:   security,
+		Group:      matches[2],
 	}, nil
 }
+
+func aptDetailFromString(input string) (*PackageDetail, error) {
+	fields := map[string]string{}
+	var (
+		current string
+		desc    []string
+	)
+	for _, line := range strings.Split(input, "\n") {
+		if line == "" {
+			// only the first stanza is used
+			if len(fields) > 0 {
+				break
+			}
+			continue
+		}
+		if strings.HasPrefix(line, " ") {
+			if strings.HasPrefix(current, "Description") {
+				l := strings.TrimSpace(line)
+				if l == "." {
+					l = ""
+				}
+				desc = append(desc, l)
+			}
+			continue
+		}
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		current = k
+		fields[k] = strings.TrimSpace(v)
+		if strings.HasPrefix(k, "Description") {
+			desc = append(desc, fields[k])
+		}
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("invalid input provided: %s", input)
+	}
+
+	detail := &PackageDetail{
+		Description: strings.Join(desc, "\n"),
+		Homepage:    fields["Homepage"],
+		Repository:  fields["Vcs-Browser"],
+	}
+	if detail.Repository == "" {
+		detail.Repository = repositoryFromURL(fields["Vcs-Git"], fields["Homepage"])
+	}
+	if size, err := strconv.ParseInt(fields["Size"], 10, 64); err == nil {
+		detail.DownloadSize = size
+	}
+	// Installed-Size is in KiB
+	if size, err := strconv.ParseInt(fields["Installed-Size"], 10, 64); err == nil {
+		detail.InstalledSize = size * 1024
+	}
+
+	return detail, nil
+}
+
+// aptChangelogSince extracts the changelog entries newer than the given version.
+// Debian changelogs list entries from the newest one.
+func aptChangelogSince(input, version string) string {
+	var result []string
+	for _, line := range strings.Split(input, "\n") {
+		if matches := aptChangelogPattern.FindStringSubmatch(line); len(matches) > 1 && matches[1] == version {
+			break
+		}
+		result = append(result, line)
+	}
+
+	return strings.TrimSpace(strings.Join(result, "\n"))
+}
+
+// aptEcosystemFromOSRelease returns Ubuntu for Ubuntu and its derivatives and Debian otherwise
+func aptEcosystemFromOSRelease(input string) string {
+	fields := map[string]string{}
+	for _, line := range strings.Split(input, "\n") {
+		if k, v, ok := strings.Cut(line, "="); ok {
+			fields[k] = strings.Trim(v, `"`)
+		}
+	}
+
+	ecosystem := osv.EcosystemDebian
+	for _, id := range strings.Fields(fields["ID"] + " " + fields["ID_LIKE"]) {
+		if id == "ubuntu" {
+			ecosystem = osv.EcosystemUbuntu
+		}
+	}
+	// the release is only known for the distribution itself, e.g. not for Linux Mint
+	if (fields["ID"] == "ubuntu" || fields["ID"] == "debian") && fields["VERSION_ID"] != "" {
+		ecosystem += ":" + fields["VERSION_ID"]
+	}
+
+	return ecosystem
+}
+
+// installedFromTabSeparated parses the packages and the versions, followed by the source packages for apt
+func installedFromTabSeparated(input string) []*PackageInfo {
+	var packages []*PackageInfo
+	for _, line := range strings.Split(input, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 || fields[0] == "" || fields[1] == "" {
+			continue
+		}
+		pkg := &PackageInfo{
+			Name:       fields[0],
+			OldVersion: fields[1],
+		}
+		if len(fields) == 4 && fields[2] != "" && fields[3] != "" {
+			pkg.Source = fields[2]
+			pkg.SourceVersion = fields[3]
+		}
+		packages = append(packages, pkg)
+	}
+
+	return packages
+}
+
+// aptPlanFromString parses the output of apt-get -s
+func aptPlanFromString(input string) *UpdatePlan {
+	plan := &UpdatePlan{}
+	for _, line := range strings.Split(input, "\n") {
+		matches := aptPlanPattern.FindStringSubmatch(line)
+		if len(matches) < 5 {
+			continue
+		}
+		entry := PlanEntry{
+			Name:       matches[2],
+			OldVersion: matches[3],
+			NewVersion: matches[4],
+		}
+		switch {
+		case matches[1] == "Remv":
+			plan.Removals = append(plan.Removals, entry)
+		case entry.OldVersion == "":
+			plan.Installs = append(plan.Installs, entry)
+		default:
+			plan.Upgrades = append(plan.Upgrades, entry)
+		}
+	}
+
+	return plan
+}
+
+// aptDownloadSizeFromString adds up the sizes of the archives listed by apt-get --print-uris
+func aptDownloadSizeFromString(input string) int64 {
+	var size int64
+	for _, line := range strings.Split(input, "\n") {
+		matches := aptURIPattern.FindStringSubmatch(line)
+		if len(matches) < 2 {
+			continue
+		}
+		if n, err := strconv.ParseInt(matches[1], 10, 64); err == nil {
+			size += n
+		}
+	}
+
+	return size
+}
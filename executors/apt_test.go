@@ -16,6 +16,8 @@ func TestAptPackageFromString(t *testing.T) {
 				Name:       "base-files",
 				OldVersion: "13ubuntu10.1",
 				NewVersion: "13ubuntu10.2",
+				Origin:     "noble-updates",
+				Group:      "noble-updates",
 			},
 		},
 		{
@@ -24,6 +26,8 @@ func TestAptPackageFromString(t *testing.T) {
 				Name:       "bpftrace",
 				OldVersion: "0.20.2-1ubuntu4.2",
 				NewVersion: "0.20.2-1ubuntu4.3",
+				Origin:     "noble-updates",
+				Group:      "noble-updates",
 			},
 		},
 		{
@@ -32,6 +36,8 @@ func TestAptPackageFromString(t *testing.T) {
 				Name:       "linux-firmware",
 				OldVersion: "20240318.git3b128b60-0ubuntu2.7",
 				NewVersion: "20240318.git3b128b60-0ubuntu2.9",
+				Origin:     "noble-updates",
+				Group:      "noble-updates",
 			},
 		},
 		{
@@ -40,6 +46,8 @@ func TestAptPackageFromString(t *testing.T) {
 				Name:       "linux-generic-hwe-24.04",
 				OldVersion: "6.8.0-52.53",
 				NewVersion: "6.11.0-17.17~24.04.2+2",
+				Origin:     "noble-updates",
+				Group:      "noble-updates",
 			},
 		},
 		{
@@ -48,6 +56,8 @@ func TestAptPackageFromString(t *testing.T) {
 				Name:       "linux-headers-generic-hwe-24.04",
 				OldVersion: "6.8.0-52.53",
 				NewVersion: "6.11.0-17.17~24.04.2+2",
+				Origin:     "noble-updates",
+				Group:      "noble-updates",
 			},
 		},
 		{
@@ -56,6 +66,8 @@ func TestAptPackageFromString(t *testing.T) {
 				Name:       "linux-image-generic-hwe-24.04",
 				OldVersion: "6.8.0-52.53",
 				NewVersion: "6.11.0-17.17~24.04.2+2",
+				Origin:     "noble-updates",
+				Group:      "noble-updates",
 			},
 		},
 		{
@@ -64,6 +76,8 @@ func TestAptPackageFromString(t *testing.T) {
 				Name:       "linux-libc-dev",
 				OldVersion: "6.8.0-52.53",
 				NewVersion: "6.8.0-53.55",
+				Origin:     "noble-updates",
+				Group:      "noble-updates",
 			},
 		},
 		{
@@ -72,6 +86,8 @@ func TestAptPackageFromString(t *testing.T) {
 				Name:       "linux-modules-nvidia-550-generic-hwe-24.04",
 				OldVersion: "6.8.0-52.53",
 				NewVersion: "6.11.0-17.17~24.04.2+1",
+				Origin:     "noble-updates",
+				Group:      "noble-updates",
 			},
 		},
 		{
@@ -80,6 +96,8 @@ func TestAptPackageFromString(t *testing.T) {
 				Name:       "linux-modules-nvidia-550-open-generic-hwe-24.04",
 				OldVersion: "6.8.0-52.53",
 				NewVersion: "6.11.0-17.17~24.04.2+1",
+				Origin:     "noble-updates",
+				Group:      "noble-updates",
 			},
 		},
 		{
@@ -88,6 +106,19 @@ func TestAptPackageFromString(t *testing.T) {
 				Name:       "linux-tools-common",
 				OldVersion: "6.8.0-52.53",
 				NewVersion: "6.8.0-53.55",
+				Origin:     "noble-updates",
+				Group:      "noble-updates",
+			},
+		},
+		{
+			input: "libssl3t64/noble-updates,noble-security 3.0.13-0ubuntu3.5 amd64 [3.0.13-0ubuntu3.4 からアップグレード可]",
+			want: &PackageInfo{
+				Name:       "libssl3t64",
+				OldVersion: "3.0.13-0ubuntu3.4",
+				NewVersion: "3.0.13-0ubuntu3.5",
+				Origin:     "noble-updates,noble-security",
+				Group:      "noble-updates,noble-security",
+				Security:   true,
 			},
 		},
 	}
@@ -106,3 +137,188 @@ func TestAptPackageFromStringErr(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, got)
 }
+
+func TestAptDetailFromString(t *testing.T) {
+	input := `Package: curl
+Version: 8.5.0-2ubuntu10.6
+Priority: optional
+Section: web
+Installed-Size: 534
+Homepage: https://curl.se/
+Description-en: command line tool for transferring data with URL syntax
+ curl is a command line tool for transferring data with URL syntax.
+ .
+ It supports many protocols.
+Size: 226980
+
+Package: curl
+Version: 8.5.0-2ubuntu10
+`
+
+	got, err := aptDetailFromString(input)
+	assert.Nil(t, err)
+	assert.Equal(t, &PackageDetail{
+		Description:   "command line tool for transferring data with URL syntax\ncurl is a command line tool for transferring data with URL syntax.\n\nIt supports many protocols.",
+		DownloadSize:  226980,
+		InstalledSize: 534 * 1024,
+		Homepage:      "https://curl.se/",
+	}, got)
+}
+
+func TestAptDetailFromStringErr(t *testing.T) {
+	got, err := aptDetailFromString("")
+	assert.Error(t, err)
+	assert.Nil(t, got)
+}
+
+func TestAptChangelogSince(t *testing.T) {
+	input := `curl (8.5.0-2ubuntu10.6) noble-security; urgency=medium
+
+  * SECURITY UPDATE: Cookie exposure
+
+ -- Ubuntu Developer <ubuntu@example.com>  Wed, 08 Jan 2025 10:00:00 -0500
+
+curl (8.5.0-2ubuntu10.5) noble-security; urgency=medium
+
+  * SECURITY UPDATE: Credential leak
+
+ -- Ubuntu Developer <ubuntu@example.com>  Tue, 05 Nov 2024 10:00:00 -0500
+
+curl (8.5.0-2ubuntu10.4) noble; urgency=medium
+
+  * Initial release
+`
+
+	got := aptChangelogSince(input, "8.5.0-2ubuntu10.5")
+	assert.Equal(t, `curl (8.5.0-2ubuntu10.6) noble-security; urgency=medium
+
+  * SECURITY UPDATE: Cookie exposure
+
+ -- Ubuntu Developer <ubuntu@example.com>  Wed, 08 Jan 2025 10:00:00 -0500`, got)
+}
+
+func TestAptEcosystemFromOSRelease(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "NAME=\"Ubuntu\"\nVERSION_ID=\"24.04\"\nID=ubuntu\nID_LIKE=debian\n", want: "Ubuntu:24.04"},
+		{input: "NAME=\"Linux Mint\"\nVERSION_ID=\"21.3\"\nID=linuxmint\nID_LIKE=\"ubuntu debian\"\n", want: "Ubuntu"},
+		{input: "PRETTY_NAME=\"Debian GNU/Linux 12 (bookworm)\"\nVERSION_ID=\"12\"\nID=debian\n", want: "Debian:12"},
+		{input: "PRETTY_NAME=\"Debian GNU/Linux trixie/sid\"\nID=debian\n", want: "Debian"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, aptEcosystemFromOSRelease(tt.input))
+		})
+	}
+}
+
+func TestInstalledFromTabSeparated(t *testing.T) {
+	got := installedFromTabSeparated("adduser\t3.137ubuntu1\tadduser\t3.137ubuntu1\n" +
+		"libssl3t64\t3.0.13-0ubuntu3.1\topenssl\t3.0.13-0ubuntu3.1\n" +
+		"curl\t8.5.0-2ubuntu10.6\n\nbroken-line\n")
+	assert.Equal(t, []*PackageInfo{
+		{Name: "adduser", OldVersion: "3.137ubuntu1", Source: "adduser", SourceVersion: "3.137ubuntu1"},
+		{Name: "libssl3t64", OldVersion: "3.0.13-0ubuntu3.1", Source: "openssl", SourceVersion: "3.0.13-0ubuntu3.1"},
+		{Name: "curl", OldVersion: "8.5.0-2ubuntu10.6"},
+	}, got)
+}
+
+func TestAptPlanFromString(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  *UpdatePlan
+	}{
+		{
+			name: "upgrades",
+			input: `NOTE: This is only a simulation!
+      apt-get needs root privileges for real execution.
+      Keep also in mind that locking is deactivated,
+      so don't depend on the relevance to the real current situation!
+Reading package lists...
+Building dependency tree...
+Reading state information...
+The following packages will be upgraded:
+  jq libjq1
+2 upgraded, 0 newly installed, 0 to remove and 0 not upgraded.
+Inst jq [1.6-2.1] (1.6-2.1+deb12u1 Debian:12.12/oldstable [amd64]) []
+Inst libjq1 [1.6-2.1] (1.6-2.1+deb12u1 Debian:12.12/oldstable [amd64])
+Conf jq (1.6-2.1+deb12u1 Debian:12.12/oldstable [amd64])
+Conf libjq1 (1.6-2.1+deb12u1 Debian:12.12/oldstable [amd64])
+`,
+			want: &UpdatePlan{
+				Upgrades: []PlanEntry{
+					{Name: "jq", OldVersion: "1.6-2.1", NewVersion: "1.6-2.1+deb12u1"},
+					{Name: "libjq1", OldVersion: "1.6-2.1", NewVersion: "1.6-2.1+deb12u1"},
+				},
+			},
+		},
+		{
+			name: "installs and removals",
+			input: `NOTE: This is only a simulation!
+      apt-get needs root privileges for real execution.
+      Keep also in mind that locking is deactivated,
+      so don't depend on the relevance to the real current situation!
+Reading package lists...
+Building dependency tree...
+Reading state information...
+The following packages were automatically installed and are no longer required:
+  libjq1 libonig5 python3-argcomplete python3-toml python3-xmltodict
+Use 'apt autoremove' to remove them.
+The following additional packages will be installed:
+  libtext-charwidth-perl
+Suggested packages:
+  filters cowsay-off
+The following packages will be REMOVED:
+  jq yq
+The following NEW packages will be installed:
+  cowsay libtext-charwidth-perl tree
+0 upgraded, 3 newly installed, 2 to remove and 0 not upgraded.
+Remv yq [3.1.0-3]
+Remv jq [1.6-2.1+deb12u1]
+Inst libtext-charwidth-perl (0.04-11 Debian:12.12/oldstable [amd64])
+Inst cowsay (3.03+dfsg2-8 Debian:12.12/oldstable [all])
+Inst tree (2.1.0-1 Debian:12.12/oldstable [amd64])
+Conf libtext-charwidth-perl (0.04-11 Debian:12.12/oldstable [amd64])
+Conf cowsay (3.03+dfsg2-8 Debian:12.12/oldstable [all])
+Conf tree (2.1.0-1 Debian:12.12/oldstable [amd64])
+`,
+			want: &UpdatePlan{
+				Installs: []PlanEntry{
+					{Name: "libtext-charwidth-perl", NewVersion: "0.04-11"},
+					{Name: "cowsay", NewVersion: "3.03+dfsg2-8"},
+					{Name: "tree", NewVersion: "2.1.0-1"},
+				},
+				Removals: []PlanEntry{
+					{Name: "yq", OldVersion: "3.1.0-3"},
+					{Name: "jq", OldVersion: "1.6-2.1+deb12u1"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, aptPlanFromString(tt.input))
+		})
+	}
+}
+
+func TestAptDownloadSizeFromString(t *testing.T) {
+	input := `'http://deb.debian.org/debian/pool/main/j/jq/jq_1.6-2.1%2bdeb12u1_amd64.deb' jq_1.6-2.1+deb12u1_amd64.deb 63680 MD5Sum:57bef1be63bab532332f681a2a80078d
+'http://deb.debian.org/debian/pool/main/j/jq/libjq1_1.6-2.1%2bdeb12u1_amd64.deb' libjq1_1.6-2.1+deb12u1_amd64.deb 133848 MD5Sum:6ca4841e0c3671a3720290e5bbe421cb
+`
+	assert.Equal(t, int64(63680+133848), aptDownloadSizeFromString(input))
+	assert.Equal(t, int64(0), aptDownloadSizeFromString(""))
+}
+
+func TestAptInstalledSizesFromString(t *testing.T) {
+	got := aptInstalledSizesFromString("curl\t534\nlibc6\t12990\nlibc6\t12300\nbroken-line\nvim\t\n")
+	assert.Equal(t, map[string]int64{
+		"curl":  534 * 1024,
+		"libc6": (12990 + 12300) * 1024,
+	}, got)
+}
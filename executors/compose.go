@@ -0,0 +1,578 @@
+package executors
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/regclient/regclient/types/errs"
+	"github.com/regclient/regclient/types/ref"
+	"gopkg.in/yaml.v3"
+)
+
+// composeFileNames are the files looked up in the current directory, in the order of docker compose
+var composeFileNames = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// characters docker compose does not allow in project names
+var composeProjectNamePattern = regexp.MustCompile(`[^a-z0-9_-]`)
+
+// ComposeConfig lists the compose files whose services are checked for updates
+type ComposeConfig struct {
+	// Files are the compose files. The compose file in the current directory is used when it is empty.
+	Files []string `yaml:"files"`
+}
+
+// composeService is a service of a compose project running a registry image
+type composeService struct {
+	Project string
+	Name    string
+	// Dir is the project directory and Files are the compose files of the project
+	Dir   string
+	Files []string
+	// File is the compose file defining the image of the service
+	File string
+	// Image is the image reference with the variables expanded
+	Image string
+	// RawImage is the image as written at Line of File
+	RawImage string
+	Line     int
+}
+
+func (s composeService) key() string {
+	return s.Project + "/" + s.Name
+}
+
+// composeUpdate is the image a service is updated to
+type composeUpdate struct {
+	service composeService
+	image   string
+	// old is the local tag of the image in use
+	old string
+	// newTag is set when the compose file is changed to a newer tag
+	newTag string
+}
+
+// ComposeExecutor checks the images of the services of compose projects and updates them service by service
+type ComposeExecutor struct {
+	docker *DockerExecutor
+	config ComposeConfig
+
+	mu sync.Mutex
+	// updates maps the packages listed by GetPackages to their updates
+	updates map[string]composeUpdate
+}
+
+// NewComposeExecutor manages the compose projects of the Docker daemon configured by DOCKER_HOST etc.
+func NewComposeExecutor(docker DockerConfig, config ComposeConfig) (*ComposeExecutor, error) {
+	de, err := NewDockerExecutor(docker)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ComposeExecutor{
+		docker:  de,
+		config:  config,
+		updates: map[string]composeUpdate{},
+	}, nil
+}
+
+// files returns the configured compose files, or the one in the current directory
+func (ce *ComposeExecutor) files() []string {
+	if len(ce.config.Files) > 0 {
+		files := make([]string, 0, len(ce.config.Files))
+		for _, f := range ce.config.Files {
+			files = append(files, expandHome(f))
+		}
+		return files
+	}
+	for _, name := range composeFileNames {
+		if _, err := os.Stat(name); err == nil {
+			abs, err := filepath.Abs(name)
+			if err != nil {
+				return []string{name}
+			}
+			return []string{abs}
+		}
+	}
+	return nil
+}
+
+// expandHome replaces the leading ~ of the path with the home directory
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
+
+func (ce *ComposeExecutor) GetPackages(_ string) ([]*PackageInfo, error) {
+	ctx := context.Background()
+
+	var packages []*PackageInfo
+
+	services, err := loadComposeServices(ce.files())
+	if err != nil {
+		return packages, err
+	}
+
+	images, err := ce.docker.source.Images(ctx, false)
+	if err != nil {
+		return packages, err
+	}
+	// the local images are matched by the full reference as compose files may omit docker.io/library
+	local := map[string]LocalImage{}
+	localTags := map[string]string{}
+	for _, img := range images {
+		for _, tag := range img.RepoTags {
+			if r, err := ref.New(tag); err == nil {
+				local[r.CommonName()] = img
+				localTags[r.CommonName()] = tag
+			}
+		}
+	}
+
+	plat, err := ce.docker.source.Platform(ctx)
+	if err != nil {
+		return packages, err
+	}
+
+	results := make([]*PackageInfo, len(services))
+	updates := make([]composeUpdate, len(services))
+	runPool(ce.docker.config.parallelism(), len(services), func(i int) {
+		s := services[i]
+		r, err := ref.New(s.Image)
+		if err != nil {
+			log.Printf("Error parsing the image of service %s: %v", s.key(), err)
+			return
+		}
+		if r.Digest != "" {
+			// pinned by digest
+			return
+		}
+		img, ok := local[r.CommonName()]
+		if !ok {
+			log.Printf("Image %s of service %s is not pulled yet", s.Image, s.key())
+			return
+		}
+		tag := localTags[r.CommonName()]
+		digests, ok := dockerTagsToCheck([]string{tag}, img.RepoDigests, ce.docker.config)[tag]
+		if !ok {
+			return
+		}
+
+		l := dockerLocalImage{ID: img.ID, RepoDigests: digests}
+		var pkg *PackageInfo
+		err = withRetry(ctx, s.Image, func() (err error) {
+			pkg, err = ce.docker.checkTag(ctx, tag, l, plat)
+			return err
+		})
+		if errors.Is(err, errs.ErrHTTPUnauthorized) {
+			log.Printf("Authentication failed for image: %s. Run `docker login` or add the registry to the config file", s.Image)
+			pkg = dockerAuthFailed(tag, l)
+		} else if err != nil {
+			log.Printf("Error checking image: %s, error: %v", s.Image, err)
+			return
+		}
+		if pkg != nil {
+			results[i] = composePackage(s, pkg, img)
+			updates[i] = composeUpdate{service: s, image: tag, old: tag}
+			return
+		}
+
+		var tags []*PackageInfo
+		err = withRetry(ctx, s.Image, func() (err error) {
+			tags, err = ce.docker.checkNewerTags(ctx, tag)
+			return err
+		})
+		if err != nil {
+			log.Printf("Error listing tags of image: %s, error: %v", s.Image, err)
+			return
+		}
+		if u := composeTagSuggestion(tags, ce.docker.config); u != nil {
+			results[i] = composePackage(s, u, img)
+			updates[i] = composeUpdate{service: s, image: u.Name, old: tag, newTag: u.NewVersion}
+		}
+	})
+
+	ce.mu.Lock()
+	ce.updates = map[string]composeUpdate{}
+	for i, pkg := range results {
+		if pkg != nil {
+			packages = append(packages, pkg)
+			ce.updates[pkg.Name] = updates[i]
+		}
+	}
+	ce.mu.Unlock()
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+
+	return packages, nil
+}
+
+// composePackage is the row of the service with the update of its image
+func composePackage(s composeService, pkg *PackageInfo, img LocalImage) *PackageInfo {
+	return &PackageInfo{
+		Name:       s.key(),
+		OldVersion: pkg.OldVersion,
+		NewVersion: pkg.NewVersion,
+		UpdateType: pkg.UpdateType,
+		CheckError: pkg.CheckError,
+		Group:      s.Project,
+		Size:       img.Size,
+		Updated:    img.Created,
+	}
+}
+
+// composeTagSuggestion picks the newer tag a service is updated to.
+// A minor update is preferred as patch updates are included and major ones may break the service.
+func composeTagSuggestion(tags []*PackageInfo, config DockerConfig) *PackageInfo {
+	for _, t := range []string{UpdateTypeMinor, UpdateTypePatch, UpdateTypeMajor} {
+		for _, u := range tags {
+			if u.UpdateType == t && config.Allowed(u.Name) {
+				return u
+			}
+		}
+	}
+	return nil
+}
+
+func (ce *ComposeExecutor) Update(pkg, password string, dryRun bool) error {
+	return ce.BulkUpdate([]string{pkg}, password, dryRun)
+}
+
+// BulkUpdate pulls the images of the services, changes the tags in the compose files
+// and recreates the services project by project
+func (ce *ComposeExecutor) BulkUpdate(pkgs []string, _ string, dryRun bool) error {
+	var (
+		updates []composeUpdate
+		pulls   []imagePull
+		errs    = UpdateErrors{}
+	)
+	ce.mu.Lock()
+	for _, pkg := range pkgs {
+		u, ok := ce.updates[pkg]
+		if !ok {
+			errs[pkg] = errors.New("no update found")
+			continue
+		}
+		updates = append(updates, u)
+		// services may share an image
+		i := slices.IndexFunc(pulls, func(p imagePull) bool { return p.image == u.image })
+		if i < 0 {
+			// the old tag is passed along as the docker executor only knows the newer tags it found itself
+			pulls = append(pulls, imagePull{image: u.image, old: cmp.Or(u.old, u.image)})
+			i = len(pulls) - 1
+		}
+		if u.newTag != "" {
+			pulls[i].services = append(pulls[i].services, u.service.key())
+		}
+	}
+	ce.mu.Unlock()
+
+	cs, ok := ce.docker.source.(ContainerSource)
+	if !ok {
+		return fmt.Errorf("containers are not supported by this image source")
+	}
+
+	// the services of the images which failed to pull are left as they are
+	if err := ce.docker.pullImages(pulls, dryRun); err != nil {
+		var pullErrs UpdateErrors
+		if !errors.As(err, &pullErrs) {
+			return err
+		}
+		for _, u := range updates {
+			if err := pullErrs[u.image]; err != nil {
+				errs[u.service.key()] = err
+			}
+		}
+	}
+
+	projects := map[string][]composeService{}
+	for _, u := range updates {
+		if _, failed := errs[u.service.key()]; failed {
+			continue
+		}
+		if u.newTag != "" {
+			if err := rewriteComposeImage(u.service, u.newTag, dryRun); err != nil {
+				errs[u.service.key()] = err
+				continue
+			}
+		}
+		projects[u.service.Project] = append(projects[u.service.Project], u.service)
+	}
+
+	names := make([]string, 0, len(projects))
+	for name := range projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		services := projects[name]
+		args := composeProjectArgs(name, services[0].Dir, services[0].Files)
+		args = append(args, "up", "--detach")
+		for _, s := range services {
+			args = append(args, s.Name)
+		}
+		if err := runCompose(cs.ComposeCommand(), args, dryRun); err != nil {
+			for _, s := range services {
+				errs[s.key()] = fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// Progress returns the progress of the running pull of the image of the service
+func (ce *ComposeExecutor) Progress(pkg string) (float64, bool) {
+	ce.mu.Lock()
+	u, ok := ce.updates[pkg]
+	ce.mu.Unlock()
+	if !ok {
+		return 0, false
+	}
+	return ce.docker.Progress(u.image)
+}
+
+// UpdatesListed reports whether the services were checked for updates, which tells the tags they are updated to
+func (ce *ComposeExecutor) UpdatesListed(pkgs []string) bool {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+	for _, pkg := range pkgs {
+		if _, ok := ce.updates[pkg]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (ce *ComposeExecutor) Valid() bool {
+	return len(ce.files()) > 0 && ce.docker.Valid()
+}
+
+func (ce *ComposeExecutor) Close() {
+	ce.docker.Close()
+}
+
+// loadComposeServices reads the services of the compose files.
+// Files of the same project are merged like docker compose does with override files.
+func loadComposeServices(files []string) ([]composeService, error) {
+	type project struct {
+		dir      string
+		files    []string
+		services map[string]composeService
+	}
+	projects := map[string]*project{}
+	for _, file := range files {
+		name, services, err := parseComposeFile(file)
+		if err != nil {
+			return nil, err
+		}
+		p, ok := projects[name]
+		if !ok {
+			p = &project{dir: filepath.Dir(file), services: map[string]composeService{}}
+			projects[name] = p
+		}
+		p.files = append(p.files, file)
+		for _, s := range services {
+			p.services[s.Name] = s
+		}
+	}
+
+	var result []composeService
+	for name, p := range projects {
+		for _, s := range p.services {
+			s.Project = name
+			s.Dir = p.dir
+			s.Files = p.files
+			result = append(result, s)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].key() < result[j].key()
+	})
+
+	return result, nil
+}
+
+// parseComposeFile returns the project name and the services with an image of the compose file
+func parseComposeFile(file string) (string, []composeService, error) {
+	b, err := os.ReadFile(file) // #nosec G304: path is given by the user
+	if err != nil {
+		return "", nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return "", nil, fmt.Errorf("failed to parse the compose file %s: %w", file, err)
+	}
+
+	dir := filepath.Dir(file)
+	lookup := composeEnv(dir)
+	name := composeProjectName(filepath.Base(dir))
+	var services []composeService
+	if len(doc.Content) == 0 {
+		return name, nil, nil
+	}
+	root := doc.Content[0]
+	if n := yamlMapValue(root, "name"); n != nil && n.Value != "" {
+		name = composeProjectName(composeInterpolate(n.Value, lookup))
+	}
+	if svcs := yamlMapValue(root, "services"); svcs != nil && svcs.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(svcs.Content); i += 2 {
+			svc := svcs.Content[i].Value
+			img := yamlMapValue(svcs.Content[i+1], "image")
+			if img == nil || img.Value == "" {
+				// built from a Dockerfile
+				continue
+			}
+			services = append(services, composeService{
+				Name:     svc,
+				File:     file,
+				Image:    composeInterpolate(img.Value, lookup),
+				RawImage: img.Value,
+				Line:     img.Line,
+			})
+		}
+	}
+
+	return name, services, nil
+}
+
+// yamlMapValue returns the value of the key of the mapping node, nil when it does not exist
+func yamlMapValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// composeProjectName normalizes the name like docker compose, e.g. My App -> myapp
+func composeProjectName(name string) string {
+	return composeProjectNamePattern.ReplaceAllString(strings.ToLower(name), "")
+}
+
+// composeEnv looks up the variables of the environment, then of the .env file of the project directory
+func composeEnv(dir string) func(string) (string, bool) {
+	env := map[string]string{}
+	if f, err := os.Open(filepath.Join(dir, ".env")); err == nil { // #nosec G304
+		defer f.Close()
+		env = parseDotEnv(f)
+	}
+	return func(name string) (string, bool) {
+		if v, ok := os.LookupEnv(name); ok {
+			return v, true
+		}
+		v, ok := env[name]
+		return v, ok
+	}
+}
+
+// parseDotEnv reads KEY=VALUE lines, ignoring comments and quotes around values
+func parseDotEnv(r io.Reader) map[string]string {
+	env := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		k, v, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			continue
+		}
+		v = strings.TrimSpace(v)
+		if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+			v = v[1 : len(v)-1]
+		}
+		env[strings.TrimSpace(k)] = v
+	}
+	return env
+}
+
+// composeInterpolate expands $VAR, ${VAR}, ${VAR:-default} and ${VAR-default}
+func composeInterpolate(s string, lookup func(string) (string, bool)) string {
+	return os.Expand(s, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		if n, def, ok := strings.Cut(name, ":-"); ok {
+			if v, ok := lookup(n); ok && v != "" {
+				return v
+			}
+			return def
+		}
+		if n, def, ok := strings.Cut(name, "-"); ok {
+			if v, ok := lookup(n); ok {
+				return v
+			}
+			return def
+		}
+		v, _ := lookup(name)
+		return v
+	})
+}
+
+// rewriteComposeImage changes the tag of the image of the service in its compose file
+func rewriteComposeImage(s composeService, tag string, dryRun bool) error {
+	if strings.Contains(s.RawImage, "$") {
+		return fmt.Errorf("the image %s is set with variables. Change the tag to %s in %s", s.RawImage, tag, s.File)
+	}
+	repo, _ := dockerSplitTag(s.RawImage)
+	image := repo + ":" + tag
+	if dryRun {
+		log.Printf("[dry-run] Changing the image of service %s to %s in %s", s.key(), image, s.File)
+		return nil
+	}
+
+	b, err := os.ReadFile(s.File)
+	if err != nil {
+		return err
+	}
+	content, err := replaceOnLine(b, s.Line, s.RawImage, image)
+	if err != nil {
+		return fmt.Errorf("%s: %w", s.File, err)
+	}
+	info, err := os.Stat(s.File)
+	if err != nil {
+		return err
+	}
+	log.Printf("Changing the image of service %s to %s in %s", s.key(), image, s.File)
+	return os.WriteFile(s.File, content, info.Mode().Perm())
+}
+
+// replaceOnLine replaces old with new on the line (1-based) so that the rest of the file keeps its formatting
+func replaceOnLine(content []byte, line int, old, new string) ([]byte, error) {
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	if line < 1 || line > len(lines) || !bytes.Contains(lines[line-1], []byte(old)) {
+		return nil, fmt.Errorf("%s is not found on line %d", old, line)
+	}
+	lines[line-1] = bytes.Replace(lines[line-1], []byte(old), []byte(new), 1)
+	return slices.Concat(lines...), nil
+}
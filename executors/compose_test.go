@@ -0,0 +1,255 @@
+package executors
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/regclient/regclient/types/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadComposeServices(t *testing.T) {
+	t.Setenv("LAZYPKG_TEST_PG_TAG", "")
+	dir := filepath.Join(t.TempDir(), "My App")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	file := filepath.Join(dir, "compose.yaml")
+	override := filepath.Join(dir, "compose.override.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`services:
+  web:
+    build: .
+  db:
+    image: postgres:${LAZYPKG_TEST_PG_TAG:-16.2}
+  cache:
+    image: "redis:${REDIS_TAG}"
+`), 0o600))
+	require.NoError(t, os.WriteFile(override, []byte(`services:
+  cache:
+    image: redis:7.2.3
+`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("# tags\nREDIS_TAG=7.0\n"), 0o600))
+
+	other := filepath.Join(t.TempDir(), "docker-compose.yml")
+	require.NoError(t, os.WriteFile(other, []byte(`name: monitoring
+services:
+  grafana:
+    image: grafana/grafana:10.4.1
+`), 0o600))
+
+	services, err := loadComposeServices([]string{file, override, other})
+	require.NoError(t, err)
+
+	files := []string{file, override}
+	assert.Equal(t, []composeService{
+		{
+			Project:  "monitoring",
+			Name:     "grafana",
+			Dir:      filepath.Dir(other),
+			Files:    []string{other},
+			File:     other,
+			Image:    "grafana/grafana:10.4.1",
+			RawImage: "grafana/grafana:10.4.1",
+			Line:     4,
+		},
+		{
+			Project:  "myapp",
+			Name:     "cache",
+			Dir:      dir,
+			Files:    files,
+			File:     override,
+			Image:    "redis:7.2.3",
+			RawImage: "redis:7.2.3",
+			Line:     3,
+		},
+		{
+			Project:  "myapp",
+			Name:     "db",
+			Dir:      dir,
+			Files:    files,
+			File:     file,
+			Image:    "postgres:16.2",
+			RawImage: "postgres:${LAZYPKG_TEST_PG_TAG:-16.2}",
+			Line:     5,
+		},
+	}, services)
+}
+
+func TestComposeInterpolate(t *testing.T) {
+	env := map[string]string{"TAG": "7", "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain", in: "redis:7", want: "redis:7"},
+		{name: "braces", in: "redis:${TAG}", want: "redis:7"},
+		{name: "without braces", in: "redis:$TAG", want: "redis:7"},
+		{name: "default when unset or empty", in: "redis:${EMPTY:-6}", want: "redis:6"},
+		{name: "default when unset", in: "redis:${EMPTY-6}", want: "redis:"},
+		{name: "unset", in: "redis:${MISSING-6}", want: "redis:6"},
+		{name: "escaped", in: "$$TAG", want: "$TAG"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, composeInterpolate(tt.in, lookup))
+		})
+	}
+}
+
+func TestRewriteComposeImage(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "compose.yaml")
+	content := `services:
+  # keep this comment
+  cache:
+    image: redis:7.2.3 # pinned
+  replica:
+    image: redis:7.2.3
+`
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+
+	s := composeService{Project: "app", Name: "cache", File: file, RawImage: "redis:7.2.3", Line: 4}
+	require.NoError(t, rewriteComposeImage(s, "7.4.0", false))
+
+	b, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, `services:
+  # keep this comment
+  cache:
+    image: redis:7.4.0 # pinned
+  replica:
+    image: redis:7.2.3
+`, string(b))
+
+	s.RawImage = "redis:${TAG}"
+	assert.Error(t, rewriteComposeImage(s, "7.4.0", false))
+
+	s.RawImage = "redis:7.2.3"
+	s.Line = 2
+	assert.Error(t, rewriteComposeImage(s, "7.4.0", false))
+}
+
+// fakeImageSource is an engine whose pulls replace the image of the tag
+type fakeImageSource struct {
+	images     map[string]LocalImage
+	containers []Container
+}
+
+func (s *fakeImageSource) Platform(context.Context) (platform.Platform, error) {
+	return platform.Platform{OS: "linux", Architecture: "amd64"}, nil
+}
+
+func (s *fakeImageSource) Images(context.Context, bool) ([]LocalImage, error) {
+	return nil, nil
+}
+
+func (s *fakeImageSource) InspectImage(_ context.Context, ref string) (LocalImage, error) {
+	img, ok := s.images[ref]
+	if !ok {
+		return LocalImage{}, fmt.Errorf("no such image: %s", ref)
+	}
+	return img, nil
+}
+
+func (s *fakeImageSource) Pull(_ context.Context, ref, _ string, _ *PullProgress) error {
+	s.images[ref] = LocalImage{ID: "sha256:pulled-" + ref}
+	return nil
+}
+
+func (s *fakeImageSource) RemoveImage(context.Context, string) error { return nil }
+func (s *fakeImageSource) Valid(context.Context) bool                { return true }
+func (s *fakeImageSource) Close() error                              { return nil }
+
+func (s *fakeImageSource) Containers(context.Context) ([]Container, error) {
+	return s.containers, nil
+}
+
+func (s *fakeImageSource) RecreateContainer(context.Context, Container) error { return nil }
+func (s *fakeImageSource) ComposeCommand() []string                           { return []string{"true"} }
+
+func TestComposeBulkUpdateNewTag(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "compose.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("name: app\nservices:\n  web:\n    image: nginx:1.25\n"), 0o600))
+	services, err := loadComposeServices([]string{file})
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+
+	source := &fakeImageSource{images: map[string]LocalImage{"nginx:1.25": {ID: "sha256:old"}}}
+	ce := &ComposeExecutor{
+		docker: newImageExecutor(source, DockerConfig{}),
+		updates: map[string]composeUpdate{
+			"app/web": {service: services[0], image: "nginx:1.27", old: "nginx:1.25", newTag: "1.27"},
+		},
+	}
+
+	require.NoError(t, ce.BulkUpdate([]string{"app/web"}, "", false))
+
+	// the image of the old tag is recorded although the docker executor did not find the newer tag itself
+	assert.Equal(t, map[string]string{"nginx:1.27": "sha256:old"}, ce.docker.replaced)
+	b, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "name: app\nservices:\n  web:\n    image: nginx:1.27\n", string(b))
+}
+
+func TestOldTagReferences(t *testing.T) {
+	containers := []Container{
+		{Name: "app-web-1", ImageID: "sha256:old", ComposeProject: "app", ComposeService: "web"},
+		{Name: "other-web-1", ImageID: "sha256:old", ComposeProject: "other", ComposeService: "web"},
+		{Name: "legacy", ImageID: "sha256:old"},
+		{Name: "cache", ImageID: "sha256:redis"},
+	}
+	p := imagePull{image: "nginx:1.27", old: "nginx:1.25", services: []string{"app/web"}}
+
+	got := oldTagReferences(containers, p, "sha256:old")
+	assert.Equal(t, []Container{
+		{Name: "other-web-1", ImageID: "sha256:old", ComposeProject: "other", ComposeService: "web"},
+		{Name: "legacy", ImageID: "sha256:old"},
+	}, got)
+}
+
+func TestComposeTagSuggestion(t *testing.T) {
+	patch := &PackageInfo{Name: "redis:7.2.5", UpdateType: UpdateTypePatch}
+	minor := &PackageInfo{Name: "redis:7.4.0", UpdateType: UpdateTypeMinor}
+	major := &PackageInfo{Name: "redis:8.0.0", UpdateType: UpdateTypeMajor}
+
+	tests := []struct {
+		name   string
+		tags   []*PackageInfo
+		config DockerConfig
+		want   *PackageInfo
+	}{
+		{
+			name: "minor first",
+			tags: []*PackageInfo{patch, minor, major},
+			want: minor,
+		},
+		{
+			name: "major when it is the only one",
+			tags: []*PackageInfo{major},
+			want: major,
+		},
+		{
+			name:   "excluded tags are skipped",
+			tags:   []*PackageInfo{patch, minor},
+			config: DockerConfig{Exclude: []string{"redis:7.4.*"}},
+			want:   patch,
+		},
+		{
+			name: "none",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, composeTagSuggestion(tt.tags, tt.config))
+		})
+	}
+}
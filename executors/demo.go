@@ -1,13 +1,23 @@
 package executors
 
 import (
+	"fmt"
 	"log"
+	"regexp"
 	"time"
 )
 
+// demoProgressPatterns match the stages of the simulated updates
+var demoProgressPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^\[Demo\] Start to update (\S+)$`),
+	regexp.MustCompile(`^Updating (\S+) \.\.\.$`),
+	regexp.MustCompile(`^Updated (\S+)$`),
+}
+
 type DemoExecutor struct {
-	cmd  string
-	pkgs []*PackageInfo
+	cmd      string
+	pkgs     []*PackageInfo
+	progress progressTracker
 }
 
 func NewDemoExecutor(cmd string, pkgs []*PackageInfo) *DemoExecutor {
@@ -25,13 +35,17 @@ func (de *DemoExecutor) GetPackages(_ string) ([]*PackageInfo, error) {
 	return de.pkgs, nil
 }
 
-func (de *DemoExecutor) Update(pkg, _ string, _ bool) error {
-	return de.update(pkg)
+func (de *DemoExecutor) Update(pkg, password string, dryRun bool) error {
+	return de.BulkUpdate([]string{pkg}, password, dryRun)
 }
 
 func (de *DemoExecutor) BulkUpdate(pkgs []string, _ string, _ bool) error {
+	progress := newStageProgress(demoProgressPatterns, pkgs)
+	de.progress.start(progress)
+	defer de.progress.stop()
+
 	for _, pkg := range pkgs {
-		if err := de.update(pkg); err != nil {
+		if err := de.update(pkg, progress); err != nil {
 			return err
 		}
 	}
@@ -39,22 +53,67 @@ func (de *DemoExecutor) BulkUpdate(pkgs []string, _ string, _ bool) error {
 	return nil
 }
 
+// Progress returns how far the simulated update of the package is
+func (de *DemoExecutor) Progress(pkg string) (float64, bool) {
+	return de.progress.progress(pkg)
+}
+
+func (de *DemoExecutor) GetDetail(pkg string) (*PackageDetail, error) {
+	time.Sleep(200 * time.Millisecond)
+	return &PackageDetail{
+		Description:   "Demo package " + pkg,
+		DownloadSize:  1234567,
+		InstalledSize: 4567890,
+		Homepage:      "https://example.com/" + pkg,
+		License:       "MIT",
+		Repository:    "https://github.com/example/" + pkg,
+	}, nil
+}
+
+func (de *DemoExecutor) GetChangelog(pkg *PackageInfo) (string, error) {
+	time.Sleep(200 * time.Millisecond)
+	return "## " + pkg.NewVersion + "\n\n- Demo changes since " + pkg.OldVersion, nil
+}
+
+func (de *DemoExecutor) Plan(pkgs []string) (*UpdatePlan, error) {
+	plan := &UpdatePlan{DownloadSize: int64(len(pkgs)) * 1234567}
+	for _, name := range pkgs {
+		for _, p := range de.pkgs {
+			if p.Name == name {
+				plan.Upgrades = append(plan.Upgrades, PlanEntry{
+					Name:       p.Name,
+					OldVersion: p.OldVersion,
+					NewVersion: p.NewVersion,
+				})
+			}
+		}
+	}
+
+	return plan, nil
+}
+
 func (de *DemoExecutor) Close() {}
 
-func (de *DemoExecutor) update(pkg string) error {
+func (de *DemoExecutor) update(pkg string, progress *stageProgress) error {
+	logf := func(format string, args ...any) {
+		line := fmt.Sprintf(format, args...)
+		log.Print(line)
+		progress.read(line)
+	}
+
 	// simulate updating a package
 	for i, p := range de.pkgs {
 		if p.Name == pkg {
-			log.Printf("[Demo] Start to update %s", pkg)
-			log.Printf("[Demo] Running %s command to update %s package ...", de.cmd, pkg)
+			logf("[Demo] Start to update %s", pkg)
+			logf("[Demo] Running %s command to update %s package ...", de.cmd, pkg)
 
 			time.Sleep(500 * time.Millisecond)
 
-			log.Print("Updating ...")
+			logf("Updating %s ...", pkg)
 
 			time.Sleep(500 * time.Millisecond)
 
-			log.Print("Update completed")
+			logf("Updated %s", pkg)
 
 			// update complete and delete pkg from outdated package list
 			de.pkgs = append(de.pkgs[:i], de.pkgs[i+1:]...)
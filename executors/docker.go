@@ -2,40 +2,138 @@ package executors
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"log"
+	"os"
+	"path"
+	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
-	"github.com/docker/docker/api/types/image"
-	"github.com/docker/docker/client"
 	"github.com/regclient/regclient"
+	"github.com/regclient/regclient/types/errs"
+	"github.com/regclient/regclient/types/manifest"
+	"github.com/regclient/regclient/types/platform"
 	"github.com/regclient/regclient/types/ref"
 )
 
 var (
-	localHashPattern  = regexp.MustCompile(`^([^\@]+)@sha256:([a-z0-9]{7})`)
-	remoteHashPattern = regexp.MustCompile(`^sha256:([a-z0-9]{7})`)
+	// e.g. 7.2.3, v1.2, 20-alpine
+	semverTagPattern = regexp.MustCompile(`^(v?)(\d+)(?:\.(\d+))?(?:\.(\d+))?(-.+)?$`)
 )
 
+// DockerConfig selects the images checked for updates.
+// Patterns are matched against image references such as postgres:16 with path.Match,
+// and a pattern without a tag matches every tag of the repository.
+type DockerConfig struct {
+	// Include lists the patterns of images to check. Every image is checked when it is empty.
+	Include []string `yaml:"include"`
+	// Exclude lists the patterns of images never to check
+	Exclude []string `yaml:"exclude"`
+	// Registries are logins used for manifest lookups and pulls
+	Registries []RegistryCredential `yaml:"registries"`
+	// Parallelism is the number of images checked or pulled at the same time
+	Parallelism int `yaml:"parallelism"`
+}
+
+func (c DockerConfig) parallelism() int {
+	if c.Parallelism > 0 {
+		return c.Parallelism
+	}
+	return defaultParallelism
+}
+
+// Allowed reports whether the image is checked for updates
+func (c DockerConfig) Allowed(img string) bool {
+	if dockerMatchesAny(c.Exclude, img) {
+		return false
+	}
+	return len(c.Include) == 0 || dockerMatchesAny(c.Include, img)
+}
+
+// dockerLocalImage identifies an image pulled to the daemon
+type dockerLocalImage struct {
+	// ID is the digest of the image config
+	ID string
+	// RepoDigests are the manifest digests the image was pulled with
+	RepoDigests []string
+}
+
+// dockerRemoteImage identifies the image a tag points to in the registry
+type dockerRemoteImage struct {
+	// Digest is the digest of the tag, which is the index digest for multi-arch images
+	Digest string
+	// ManifestDigest is the digest of the manifest for the platform
+	ManifestDigest string
+	// ConfigDigest is the digest of the image config for the platform
+	ConfigDigest string
+}
+
+// DockerExecutor checks the images of a container engine against their registries and pulls updates.
+// Docker, Podman and containerd share it with their own ImageSource.
 type DockerExecutor struct {
-	dc *client.Client
-	rc *regclient.RegClient
+	source ImageSource
+	rc     *regclient.RegClient
+	auth   *registryAuth
+	config DockerConfig
+
+	mu sync.Mutex
+	// tagUpdates maps the newer tags found by GetPackages to the tags in use
+	tagUpdates map[string]string
+	// replaced maps the pulled images to the IDs of the images they replaced
+	replaced map[string]string
+	// pulls are the progress of the running pulls
+	pulls map[string]*PullProgress
+}
+
+// NewDockerExecutor manages the images of the Docker daemon configured by DOCKER_HOST etc.
+func NewDockerExecutor(config DockerConfig) (*DockerExecutor, error) {
+	source, err := newDockerAPISource("", []string{"docker", "compose"})
+	if err != nil {
+		return nil, err
+	}
+
+	return newImageExecutor(source, config), nil
 }
 
-func NewDockerExecutor() (*DockerExecutor, error) {
-	dc, err := client.NewClientWithOpts(client.FromEnv)
+// NewPodmanExecutor manages the images of Podman through its Docker compatible socket
+func NewPodmanExecutor(config DockerConfig) (*DockerExecutor, error) {
+	source, err := newDockerAPISource(podmanSocket(), []string{"podman", "compose"})
 	if err != nil {
 		return nil, err
 	}
-	rc := regclient.New()
 
+	return newImageExecutor(source, config), nil
+}
+
+// NewNerdctlExecutor manages the images of containerd through nerdctl
+func NewNerdctlExecutor(config DockerConfig) *DockerExecutor {
+	return newImageExecutor(&nerdctlSource{}, config)
+}
+
+func newImageExecutor(source ImageSource, config DockerConfig) *DockerExecutor {
 	return &DockerExecutor{
-		dc: dc,
-		rc: rc,
-	}, nil
+		source:     source,
+		rc:         newRegClient(config.Registries),
+		auth:       newRegistryAuth(config.Registries),
+		config:     config,
+		tagUpdates: map[string]string{},
+		replaced:   map[string]string{},
+		pulls:      map[string]*PullProgress{},
+	}
+}
+
+// podmanSocket returns the socket of rootless Podman, or the rootful one when XDG_RUNTIME_DIR is not set
+func podmanSocket() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return "unix://" + filepath.Join(dir, "podman", "podman.sock")
+	}
+	return "unix:///run/podman/podman.sock"
 }
 
 func (de *DockerExecutor) GetPackages(_ string) ([]*PackageInfo, error) {
@@ -43,166 +141,621 @@ func (de *DockerExecutor) GetPackages(_ string) ([]*PackageInfo, error) {
 
 	var packages []*PackageInfo
 
-	images, err := de.dc.ImageList(ctx, image.ListOptions{})
+	images, err := de.source.Images(ctx, false)
 	if err != nil {
 		return packages, err
 	}
 
-	for _, image := range images {
-		if len(image.RepoTags) == 0 {
-			continue
+	localTags := map[string]bool{}
+	for _, img := range images {
+		for _, tag := range img.RepoTags {
+			localTags[tag] = true
+		}
+	}
+
+	plat, err := de.source.Platform(ctx)
+	if err != nil {
+		return packages, err
+	}
+
+	type check struct {
+		tag   string
+		img   LocalImage
+		local dockerLocalImage
+		pkg   *PackageInfo
+		// updates are the newer tags, nil when the tag could not be checked
+		updates []*PackageInfo
+	}
+	var checks []*check
+	for _, img := range images {
+		for tag, digests := range dockerTagsToCheck(img.RepoTags, img.RepoDigests, de.config) {
+			checks = append(checks, &check{tag: tag, img: img, local: dockerLocalImage{ID: img.ID, RepoDigests: digests}})
+		}
+	}
+	// the results are merged in this order so that the same tag suggestion always wins
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].tag < checks[j].tag
+	})
+
+	runPool(de.config.parallelism(), len(checks), func(i int) {
+		c := checks[i]
+		err := withRetry(ctx, c.tag, func() (err error) {
+			c.pkg, err = de.checkTag(ctx, c.tag, c.local, plat)
+			return err
+		})
+		if errors.Is(err, errs.ErrHTTPUnauthorized) {
+			log.Printf("Authentication failed for image: %s. Run `docker login` or add the registry to the config file", c.tag)
+			c.pkg = dockerAuthFailed(c.tag, c.local)
+			return
+		}
+		if err != nil {
+			log.Printf("Error checking image: %s, error: %v", c.tag, err)
+			return
 		}
 
-		// latest images only
-		if !strings.Contains(image.RepoTags[0], "latest") {
-			continue
+		err = withRetry(ctx, c.tag, func() (err error) {
+			c.updates, err = de.checkNewerTags(ctx, c.tag)
+			return err
+		})
+		if err != nil {
+			log.Printf("Error listing tags of image: %s, error: %v", c.tag, err)
 		}
+	})
 
-		// pulled images only
-		if len(image.RepoDigests) == 0 {
-			continue
+	tagUpdates := map[string]string{}
+	for _, c := range checks {
+		if c.pkg != nil {
+			c.pkg.Size, c.pkg.Updated = c.img.Size, c.img.Created
+			packages = append(packages, c.pkg)
 		}
+		for _, u := range c.updates {
+			if _, ok := tagUpdates[u.Name]; ok || localTags[u.Name] || !de.config.Allowed(u.Name) {
+				continue
+			}
+			tagUpdates[u.Name] = c.tag
+			u.Size, u.Updated = c.img.Size, c.img.Created
+			packages = append(packages, u)
+		}
+	}
+	de.mu.Lock()
+	de.tagUpdates = tagUpdates
+	de.mu.Unlock()
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+
+	return packages, nil
+}
+
+// dockerAuthFailed is the row of an image whose registry refused the login,
+// which must not look up to date
+func dockerAuthFailed(tag string, local dockerLocalImage) *PackageInfo {
+	return &PackageInfo{
+		Name:       tag,
+		OldVersion: local.ID,
+		CheckError: CheckErrorAuth,
+	}
+}
+
+// checkTag compares the local image with the image the tag points to in the registry for the platform
+func (de *DockerExecutor) checkTag(ctx context.Context, tag string, local dockerLocalImage, plat platform.Platform) (*PackageInfo, error) {
+	r, err := ref.New(tag)
+	if err != nil {
+		return nil, err
+	}
+	defer de.rc.Close(ctx, r)
+
+	remote, err := de.resolveRemote(ctx, r, plat, local.RepoDigests)
+	if err != nil {
+		return nil, err
+	}
 
-		imageName := image.RepoTags[0]
-		localDigest := image.RepoDigests[0]
+	return dockerDigestUpdate(tag, local, remote), nil
+}
+
+// resolveRemote looks up the digests of the reference for the platform.
+// The platform manifest is not fetched when the index is one of the known digests.
+func (de *DockerExecutor) resolveRemote(ctx context.Context, r ref.Ref, plat platform.Platform, known []string) (dockerRemoteImage, error) {
+	m, err := de.rc.ManifestGet(ctx, r)
+	if err != nil {
+		return dockerRemoteImage{}, err
+	}
+	remote := dockerRemoteImage{Digest: m.GetDescriptor().Digest.String()}
+	if slices.Contains(known, remote.Digest) {
+		return remote, nil
+	}
 
-		r, err := ref.New(imageName)
+	if m.IsList() {
+		d, err := manifest.GetPlatformDesc(m, &plat)
 		if err != nil {
-			log.Printf("Error creating a reference for image: %s, error: %v", imageName, err)
-			continue
+			return dockerRemoteImage{}, fmt.Errorf("no image for %s: %w", plat.String(), err)
 		}
-		defer de.rc.Close(ctx, r)
-
-		m, err := de.rc.ManifestGet(ctx, r)
+		remote.ManifestDigest = d.Digest.String()
+		m, err = de.rc.ManifestGet(ctx, r, regclient.WithManifestDesc(*d))
 		if err != nil {
-			log.Printf("Error getting manifest for image: %s, error: %v", imageName, err)
-			continue
+			return dockerRemoteImage{}, err
 		}
+	} else {
+		remote.ManifestDigest = remote.Digest
+	}
 
-		if pkg, err := dockerDiffPackageFromHash(imageName, localDigest, m.GetDescriptor().Digest.String()); err == nil && pkg != nil {
-			packages = append(packages, pkg)
+	if mi, ok := m.(manifest.Imager); ok {
+		cfg, err := mi.GetConfig()
+		if err != nil {
+			return dockerRemoteImage{}, err
 		}
+		remote.ConfigDigest = cfg.Digest.String()
 	}
 
-	return packages, nil
+	return remote, nil
+}
+
+// checkNewerTags looks for tags in the registry with a higher version than the tag
+func (de *DockerExecutor) checkNewerTags(ctx context.Context, tag string) ([]*PackageInfo, error) {
+	repo, current := dockerSplitTag(tag)
+	if !semverTagPattern.MatchString(current) {
+		return nil, nil
+	}
+
+	r, err := ref.New(tag)
+	if err != nil {
+		return nil, err
+	}
+	defer de.rc.Close(ctx, r)
+
+	tl, err := de.rc.TagList(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := tl.GetTags()
+	if err != nil {
+		return nil, err
+	}
+
+	return dockerTagUpdates(repo, current, tags), nil
+}
+
+func (de *DockerExecutor) Update(img, password string, dryRun bool) error {
+	return de.BulkUpdate([]string{img}, password, dryRun)
 }
 
-func (de *DockerExecutor) Update(img, _ string, dryRun bool) error {
-	var wg sync.WaitGroup
-	mu := &sync.Mutex{}
-	errors := map[string]error{}
+func (de *DockerExecutor) BulkUpdate(imgs []string, _ string, dryRun bool) error {
+	pulls := make([]imagePull, len(imgs))
+	for i, img := range imgs {
+		pulls[i] = imagePull{image: img, old: de.localTag(img)}
+	}
+
+	return de.pullImages(pulls, dryRun)
+}
 
-	wg.Add(1)
-	go de.pullImage(&wg, mu, errors, img, dryRun)
+// imagePull is an image to pull and the tag in use it replaces
+type imagePull struct {
+	image string
+	// old is the tag in use, which is the image itself unless the image is a newer tag
+	old string
+	// services are the compose services changed to the image after the pull, which do not keep using the old tag
+	services []string
+}
 
-	wg.Wait()
+// pullImages pulls the images at the same time and returns the errors by image
+func (de *DockerExecutor) pullImages(pulls []imagePull, dryRun bool) error {
+	results := make([]error, len(pulls))
+	runPool(de.config.parallelism(), len(pulls), func(i int) {
+		results[i] = de.pullImage(pulls[i], dryRun)
+	})
 
-	if len(errors) > 0 {
-		msg := "Some images failed to pull:"
-		for img, err := range errors {
-			msg = fmt.Sprintf("%s\n - %s: %v", msg, img, err)
+	errs := UpdateErrors{}
+	for i, err := range results {
+		if err != nil {
+			errs[pulls[i].image] = err
 		}
-		return fmt.Errorf("%v", msg)
+	}
+	if len(errs) > 0 {
+		return errs
 	}
 
 	return nil
 }
 
-func (de *DockerExecutor) BulkUpdate(imgs []string, _ string, dryRun bool) error {
-	var wg sync.WaitGroup
-	mu := &sync.Mutex{}
-	errors := map[string]error{}
+// Progress returns the progress of the running pull of the image
+func (de *DockerExecutor) Progress(img string) (float64, bool) {
+	de.mu.Lock()
+	p, ok := de.pulls[img]
+	de.mu.Unlock()
+	if !ok {
+		return 0, false
+	}
+	return p.Fraction()
+}
 
-	for _, img := range imgs {
-		wg.Add(1)
-		go de.pullImage(&wg, mu, errors, img, dryRun)
+func (de *DockerExecutor) GetDetail(img string) (*PackageDetail, error) {
+	ctx := context.Background()
+
+	r, err := ref.New(img)
+	if err != nil {
+		return nil, err
 	}
+	defer de.rc.Close(ctx, r)
 
-	wg.Wait()
+	m, err := de.rc.ManifestGet(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	if m.IsList() {
+		plat, err := de.source.Platform(ctx)
+		if err != nil {
+			return nil, err
+		}
+		d, err := manifest.GetPlatformDesc(m, &plat)
+		if err != nil {
+			return nil, err
+		}
+		m, err = de.rc.ManifestGet(ctx, r, regclient.WithManifestDesc(*d))
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	if len(errors) > 0 {
-		msg := "Some images failed to pull:"
-		for img, err := range errors {
-			msg = fmt.Sprintf("%s\n - %s: %v", msg, img, err)
+	var size int64
+	if mi, ok := m.(manifest.Imager); ok {
+		layers, err := mi.GetLayers()
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range layers {
+			size += l.Size
 		}
-		return fmt.Errorf("%v", msg)
 	}
 
-	return nil
+	cfg, err := de.rc.ImageConfig(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := dockerDetailFromLabels(cfg.GetConfig().Config.Labels)
+	detail.DownloadSize = size
+
+	return detail, nil
+}
+
+func (de *DockerExecutor) GetChangelog(pkg *PackageInfo) (string, error) {
+	ctx := context.Background()
+
+	r, err := ref.New(pkg.Name)
+	if err != nil {
+		return "", err
+	}
+	defer de.rc.Close(ctx, r)
+
+	local, err := de.source.InspectImage(ctx, de.localTag(pkg.Name))
+	if err != nil {
+		return "", err
+	}
+
+	remote, err := de.rc.ImageConfig(ctx, r)
+	if err != nil {
+		return "", err
+	}
+
+	return dockerLabelChanges(local.Labels, remote.GetConfig().Config.Labels), nil
+}
+
+// localTag returns the tag in use which the image updates
+func (de *DockerExecutor) localTag(img string) string {
+	de.mu.Lock()
+	defer de.mu.Unlock()
+	if old, ok := de.tagUpdates[img]; ok {
+		return old
+	}
+	return img
 }
 
 func (de *DockerExecutor) Valid() bool {
-	_, err := de.dc.Info(context.Background())
-	return err == nil
+	return de.source.Valid(context.Background())
 }
 
 func (de *DockerExecutor) Close() {
-	if err := de.dc.Close(); err != nil {
-		log.Printf("Failed to close the docker client: %v", err)
+	if err := de.source.Close(); err != nil {
+		log.Printf("Failed to close the image source: %v", err)
 	}
 }
 
-func (de *DockerExecutor) pullImage(
-	wg *sync.WaitGroup,
-	mu *sync.Mutex,
-	errors map[string]error,
-	img string,
-	dryRun bool,
-) {
-	defer wg.Done()
-
+func (de *DockerExecutor) pullImage(p imagePull, dryRun bool) error {
 	ctx := context.Background()
+	img := p.image
 
 	r, err := ref.New(img)
 	if err != nil {
-		mu.Lock()
-		errors[img] = err
-		mu.Unlock()
-		return
+		return err
 	}
 
 	if dryRun {
 		log.Printf("[dry-run] Pulling image: %s", img)
-		return
+		return nil
 	}
 
-	out, err := de.dc.ImagePull(ctx, r.Reference, image.PullOptions{})
+	var oldID string
+	if old, err := de.source.InspectImage(ctx, p.old); err == nil {
+		oldID = old.ID
+	}
+
+	progress := newPullProgress()
+	de.mu.Lock()
+	de.pulls[img] = progress
+	de.mu.Unlock()
+	defer func() {
+		de.mu.Lock()
+		delete(de.pulls, img)
+		de.mu.Unlock()
+	}()
+
+	err = withRetry(ctx, img, func() error {
+		return de.source.Pull(ctx, r.Reference, de.auth.Encoded(img), progress)
+	})
 	if err != nil {
-		mu.Lock()
-		errors[img] = err
-		mu.Unlock()
-		return
+		return err
+	}
+
+	if pulled, err := de.source.InspectImage(ctx, img); err == nil && oldID != "" && pulled.ID != oldID {
+		de.mu.Lock()
+		de.replaced[img] = oldID
+		de.mu.Unlock()
+	}
+
+	if p.old != img {
+		de.reportOldTagReferences(ctx, p, oldID)
 	}
-	defer out.Close()
 
-	if _, err := io.Copy(log.Writer(), out); err != nil {
-		mu.Lock()
-		errors[img] = err
-		mu.Unlock()
+	return nil
+}
+
+// reportOldTagReferences tells which containers and compose projects still use the old tag
+// as pulling a newer tag does not change them
+func (de *DockerExecutor) reportOldTagReferences(ctx context.Context, p imagePull, oldID string) {
+	log.Printf("Pulled %s. Images, containers and compose files referencing %s keep using the old tag", p.image, p.old)
+
+	cs, ok := de.source.(ContainerSource)
+	if !ok || oldID == "" {
+		return
+	}
+	containers, err := cs.Containers(ctx)
+	if err != nil {
+		log.Printf("Failed to list containers of %s: %v", p.old, err)
 		return
 	}
+	for _, c := range oldTagReferences(containers, p, oldID) {
+		if c.ComposeProject != "" {
+			log.Printf(
+				"Compose project %s (service %s) references %s in %s. Change it to %s and run `%s up -d`",
+				c.ComposeProject,
+				c.ComposeService,
+				p.old,
+				strings.Join(c.ComposeFiles, ","),
+				p.image,
+				strings.Join(cs.ComposeCommand(), " "),
+			)
+			continue
+		}
+		log.Printf("Container %s runs %s. Recreate it with %s to use the new tag", c.Name, p.old, p.image)
+	}
 }
 
-func dockerDiffPackageFromHash(imageName, localDigest, remoteDigest string) (*PackageInfo, error) {
-	localMathces := localHashPattern.FindStringSubmatch(localDigest)
-	if len(localMathces) < 3 {
-		return nil, fmt.Errorf("invalid local digest provided: %s", localDigest)
+// oldTagReferences returns the containers created from the old image, except the compose services updated with the pull
+func oldTagReferences(containers []Container, p imagePull, oldID string) []Container {
+	var result []Container
+	for _, c := range containers {
+		if c.ImageID != oldID {
+			continue
+		}
+		if c.ComposeProject != "" && slices.Contains(p.services, c.ComposeProject+"/"+c.ComposeService) {
+			continue
+		}
+		result = append(result, c)
 	}
-	remoteMatches := remoteHashPattern.FindStringSubmatch(remoteDigest)
-	if len(remoteMatches) < 2 {
-		return nil, fmt.Errorf("invalid remote digest provided: %s", remoteDigest)
+	return result
+}
+
+// dockerTagsToCheck returns the allowed tags of an image along with the digests their repositories were pulled with.
+// Tags without a repo digest have never been pulled from a registry and are skipped.
+func dockerTagsToCheck(repoTags, repoDigests []string, config DockerConfig) map[string][]string {
+	result := map[string][]string{}
+	for _, tag := range repoTags {
+		if tag == "<none>:<none>" || !config.Allowed(tag) {
+			continue
+		}
+		repo, _ := dockerSplitTag(tag)
+		var digests []string
+		for _, d := range repoDigests {
+			if r, digest, ok := strings.Cut(d, "@"); ok && r == repo {
+				digests = append(digests, digest)
+			}
+		}
+		if len(digests) > 0 {
+			result[tag] = digests
+		}
 	}
 
-	ld := localMathces[2]
-	rd := remoteMatches[1]
+	return result
+}
 
-	if ld == rd {
-		return nil, nil
+// dockerTagUpdates picks the highest patch, minor and major versions above the current tag
+// among tags sharing its format, e.g. 7.2.5-alpine for 7.2.3-alpine
+func dockerTagUpdates(repo, current string, tags []string) []*PackageInfo {
+	cm := semverTagPattern.FindStringSubmatch(current)
+	if cm == nil {
+		return nil
+	}
+	cv := semverTagNumbers(cm)
+
+	types := []string{UpdateTypeMajor, UpdateTypeMinor, UpdateTypePatch}
+	best := map[string][]int{}
+	bestTag := map[string]string{}
+	for _, t := range tags {
+		m := semverTagPattern.FindStringSubmatch(t)
+		// the prefix, the number of components and the suffix must match
+		if m == nil || m[1] != cm[1] || m[5] != cm[5] {
+			continue
+		}
+		v := semverTagNumbers(m)
+		if len(v) != len(cv) {
+			continue
+		}
+		for i := range v {
+			if v[i] == cv[i] {
+				continue
+			}
+			if v[i] > cv[i] && (best[types[i]] == nil || compareInts(v, best[types[i]]) > 0) {
+				best[types[i]] = v
+				bestTag[types[i]] = t
+			}
+			break
+		}
+	}
+
+	var result []*PackageInfo
+	for i := len(types) - 1; i >= 0; i-- {
+		t, ok := bestTag[types[i]]
+		if !ok {
+			continue
+		}
+		result = append(result, &PackageInfo{
+			Name:       repo + ":" + t,
+			OldVersion: current,
+			NewVersion: t,
+			UpdateType: types[i],
+		})
+	}
+
+	return result
+}
+
+func semverTagNumbers(matches []string) []int {
+	var nums []int
+	for _, m := range matches[2:5] {
+		if m == "" {
+			break
+		}
+		n, _ := strconv.Atoi(m)
+		nums = append(nums, n)
+	}
+	return nums
+}
+
+func compareInts(a, b []int) int {
+	for i := range a {
+		switch {
+		case a[i] > b[i]:
+			return 1
+		case a[i] < b[i]:
+			return -1
+		}
+	}
+	return 0
+}
+
+// dockerSplitTag splits an image reference such as localhost:5000/app:1.0 into the repository and the tag
+func dockerSplitTag(img string) (string, string) {
+	i := strings.LastIndex(img, ":")
+	if i < 0 || strings.Contains(img[i:], "/") {
+		return img, ""
+	}
+	return img[:i], img[i+1:]
+}
+
+func dockerMatchesAny(patterns []string, img string) bool {
+	repo, _ := dockerSplitTag(img)
+	for _, p := range patterns {
+		target := img
+		if _, tag := dockerSplitTag(p); tag == "" {
+			target = repo
+		}
+		if ok, err := path.Match(p, target); err == nil && ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// dockerDigestUpdate compares full digests and returns nil when the local image is the one for the platform.
+// An index moved by other platforms is not an update.
+func dockerDigestUpdate(tag string, local dockerLocalImage, remote dockerRemoteImage) *PackageInfo {
+	if slices.Contains(local.RepoDigests, remote.Digest) ||
+		slices.Contains(local.RepoDigests, remote.ManifestDigest) ||
+		local.ID == remote.ConfigDigest {
+		return nil
 	}
 
 	return &PackageInfo{
-		Name:       imageName,
-		OldVersion: ld,
-		NewVersion: rd,
-	}, nil
+		Name:       tag,
+		OldVersion: local.ID,
+		NewVersion: remote.ConfigDigest,
+	}
+}
+
+// dockerPlatform converts the OS and the architecture reported by the daemon (uname -m) into a platform
+func dockerPlatform(osType, arch string) platform.Platform {
+	plat := platform.Platform{OS: osType, Architecture: arch}
+	switch arch {
+	case "x86_64":
+		plat.Architecture = "amd64"
+	case "aarch64", "arm64":
+		plat.Architecture = "arm64"
+	case "armv7l":
+		plat.Architecture, plat.Variant = "arm", "v7"
+	case "armv6l":
+		plat.Architecture, plat.Variant = "arm", "v6"
+	case "i386", "i686":
+		plat.Architecture = "386"
+	}
+	return plat
+}
+
+func dockerDetailFromLabels(labels map[string]string) *PackageDetail {
+	source := labels["org.opencontainers.image.source"]
+	if source == "" {
+		source = repositoryFromURL(labels["org.opencontainers.image.url"])
+	}
+
+	return &PackageDetail{
+		Description: labels["org.opencontainers.image.description"],
+		Homepage:    labels["org.opencontainers.image.url"],
+		License:     labels["org.opencontainers.image.licenses"],
+		Repository:  source,
+	}
+}
+
+// dockerLabelChanges describes the labels which differ between the local and the remote image
+func dockerLabelChanges(local, remote map[string]string) string {
+	keys := make([]string, 0, len(remote))
+	for k := range remote {
+		keys = append(keys, k)
+	}
+	for k := range local {
+		if _, ok := remote[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		o, n := local[k], remote[k]
+		if o == n {
+			continue
+		}
+		fmt.Fprintf(&sb, "%s\n  %s -> %s\n", k, orNone(o), orNone(n))
+	}
+	if sb.Len() == 0 {
+		return "No label changes found"
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
 }
@@ -0,0 +1,365 @@
+package executors
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/regclient/regclient/types/platform"
+)
+
+const (
+	composeProjectLabel     = "com.docker.compose.project"
+	composeServiceLabel     = "com.docker.compose.service"
+	composeConfigFilesLabel = "com.docker.compose.project.config_files"
+	composeWorkingDirLabel  = "com.docker.compose.project.working_dir"
+	// labels set by podman-compose
+	podmanComposeProjectLabel = "io.podman.compose.project"
+	podmanComposeServiceLabel = "io.podman.compose.service"
+	// recreatedSuffix is appended to the name of the old container while the new one is created
+	recreatedSuffix = "-lazypkg-old"
+)
+
+// dockerAPISource talks to an engine serving the Docker Engine API, i.e. Docker or Podman
+type dockerAPISource struct {
+	dc      *client.Client
+	compose []string
+}
+
+// newDockerAPISource connects to the host, or to the one configured by DOCKER_HOST etc. when it is empty
+func newDockerAPISource(host string, compose []string) (*dockerAPISource, error) {
+	opts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
+	if host != "" {
+		opts = append(opts, client.WithHost(host))
+	}
+	dc, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dockerAPISource{dc: dc, compose: compose}, nil
+}
+
+func (s *dockerAPISource) Platform(ctx context.Context) (platform.Platform, error) {
+	info, err := s.dc.Info(ctx)
+	if err != nil {
+		return platform.Platform{}, err
+	}
+	return dockerPlatform(info.OSType, info.Architecture), nil
+}
+
+func (s *dockerAPISource) Images(ctx context.Context, dangling bool) ([]LocalImage, error) {
+	opts := image.ListOptions{}
+	if dangling {
+		opts.Filters = filters.NewArgs(filters.Arg("dangling", "true"))
+	}
+	summaries, err := s.dc.ImageList(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]LocalImage, 0, len(summaries))
+	for _, sm := range summaries {
+		images = append(images, LocalImage{
+			ID:          sm.ID,
+			RepoTags:    sm.RepoTags,
+			RepoDigests: sm.RepoDigests,
+			Size:        sm.Size,
+			Created:     time.Unix(sm.Created, 0),
+			Labels:      sm.Labels,
+		})
+	}
+
+	return images, nil
+}
+
+func (s *dockerAPISource) InspectImage(ctx context.Context, ref string) (LocalImage, error) {
+	resp, err := s.dc.ImageInspect(ctx, ref)
+	if err != nil {
+		return LocalImage{}, err
+	}
+
+	img := LocalImage{
+		ID:          resp.ID,
+		RepoTags:    resp.RepoTags,
+		RepoDigests: resp.RepoDigests,
+		Size:        resp.Size,
+	}
+	if created, err := time.Parse(time.RFC3339Nano, resp.Created); err == nil {
+		img.Created = created
+	}
+	if resp.Config != nil {
+		img.Labels = resp.Config.Labels
+	}
+
+	return img, nil
+}
+
+func (s *dockerAPISource) Pull(ctx context.Context, ref, auth string, progress *PullProgress) error {
+	out, err := s.dc.ImagePull(ctx, ref, image.PullOptions{RegistryAuth: auth})
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	return decodePullStream(out, ref, progress)
+}
+
+func (s *dockerAPISource) RemoveImage(ctx context.Context, id string) error {
+	_, err := s.dc.ImageRemove(ctx, id, image.RemoveOptions{PruneChildren: true})
+	return err
+}
+
+func (s *dockerAPISource) Valid(ctx context.Context) bool {
+	_, err := s.dc.Info(ctx)
+	return err == nil
+}
+
+func (s *dockerAPISource) Close() error {
+	return s.dc.Close()
+}
+
+func (s *dockerAPISource) Containers(ctx context.Context) ([]Container, error) {
+	summaries, err := s.dc.ContainerList(ctx, container.ListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	containers := make([]Container, 0, len(summaries))
+	for _, sm := range summaries {
+		containers = append(containers, containerFromSummary(sm))
+	}
+
+	return containers, nil
+}
+
+func (s *dockerAPISource) ComposeCommand() []string {
+	return s.compose
+}
+
+// RecreateContainer keeps the old container under another name until the new one starts
+// so that it can be restored on failure.
+func (s *dockerAPISource) RecreateContainer(ctx context.Context, c Container) error {
+	old, err := s.dc.ContainerInspect(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+	running := old.State != nil && old.State.Running
+
+	// the image the container was created from tells the values the user did not set
+	oldImage, err := s.dc.ImageInspect(ctx, old.Image)
+	if err != nil {
+		return err
+	}
+
+	config, hostConfig, networkingConfig := recreateConfig(old, imageConfig(oldImage), c.Image)
+
+	if running {
+		if err := s.dc.ContainerStop(ctx, c.ID, container.StopOptions{}); err != nil {
+			return err
+		}
+	}
+	if err := s.dc.ContainerRename(ctx, c.ID, c.Name+recreatedSuffix); err != nil {
+		return s.restoreContainer(ctx, c, running, "", err)
+	}
+
+	created, err := s.dc.ContainerCreate(ctx, config, hostConfig, networkingConfig, nil, c.Name)
+	if err != nil {
+		return s.restoreContainer(ctx, c, running, "", err)
+	}
+	if running {
+		if err := s.dc.ContainerStart(ctx, created.ID, container.StartOptions{}); err != nil {
+			return s.restoreContainer(ctx, c, running, created.ID, err)
+		}
+	}
+
+	return s.dc.ContainerRemove(ctx, c.ID, container.RemoveOptions{})
+}
+
+// restoreContainer brings the old container back after a failed recreation and returns the cause
+func (s *dockerAPISource) restoreContainer(ctx context.Context, c Container, running bool, createdID string, cause error) error {
+	if createdID != "" {
+		if err := s.dc.ContainerRemove(ctx, createdID, container.RemoveOptions{Force: true}); err != nil {
+			log.Printf("Failed to remove the new container of %s: %v", c.Name, err)
+		}
+	}
+	if err := s.dc.ContainerRename(ctx, c.ID, c.Name); err != nil {
+		log.Printf("Failed to restore the name of container %s: %v", c.Name, err)
+	}
+	if running {
+		if err := s.dc.ContainerStart(ctx, c.ID, container.StartOptions{}); err != nil {
+			log.Printf("Failed to restart container %s: %v", c.Name, err)
+		}
+	}
+
+	return fmt.Errorf("failed to recreate container %s, the old container is restored: %w", c.Name, cause)
+}
+
+func containerFromSummary(s container.Summary) Container {
+	c := Container{
+		ID:                s.ID,
+		Name:              strings.TrimPrefix(firstOrEmpty(s.Names), "/"),
+		ImageID:           s.ImageID,
+		ComposeProject:    s.Labels[composeProjectLabel],
+		ComposeService:    s.Labels[composeServiceLabel],
+		ComposeWorkingDir: s.Labels[composeWorkingDirLabel],
+	}
+	if c.ComposeProject == "" {
+		c.ComposeProject = s.Labels[podmanComposeProjectLabel]
+		c.ComposeService = s.Labels[podmanComposeServiceLabel]
+	}
+	if files := s.Labels[composeConfigFilesLabel]; files != "" {
+		c.ComposeFiles = strings.Split(files, ",")
+	}
+
+	return c
+}
+
+// recreateConfig returns the configuration to create a container same as the inspected one with the image.
+// The values the old container took from its image, oldImage, are left to the new image.
+func recreateConfig(old container.InspectResponse, oldImage *container.Config, img string) (*container.Config, *container.HostConfig, *network.NetworkingConfig) {
+	config := *old.Config
+	config.Image = img
+	// the hostname defaults to the container ID, which changes
+	if old.ContainerJSONBase != nil && strings.HasPrefix(old.ID, config.Hostname) {
+		config.Hostname = ""
+	}
+	withoutImageDefaults(&config, oldImage)
+
+	var hostConfig *container.HostConfig
+	if old.ContainerJSONBase != nil && old.HostConfig != nil {
+		hc := *old.HostConfig
+		// anonymous volumes, e.g. of the VOLUME of the image, would be created empty again
+		hc.Mounts = append(slices.Clone(hc.Mounts), anonymousVolumes(old.Mounts, &hc)...)
+		hostConfig = &hc
+	}
+
+	networkingConfig := &network.NetworkingConfig{EndpointsConfig: map[string]*network.EndpointSettings{}}
+	if old.NetworkSettings != nil {
+		for name, ep := range old.NetworkSettings.Networks {
+			// keep the configuration and drop the operational data
+			networkingConfig.EndpointsConfig[name] = &network.EndpointSettings{
+				IPAMConfig: ep.IPAMConfig,
+				Links:      ep.Links,
+				Aliases:    ep.Aliases,
+				DriverOpts: ep.DriverOpts,
+				GwPriority: ep.GwPriority,
+			}
+		}
+	}
+
+	return &config, hostConfig, networkingConfig
+}
+
+// imageConfig returns the values of the image which containers take unless they are set
+func imageConfig(img image.InspectResponse) *container.Config {
+	if img.Config == nil {
+		return nil
+	}
+	return &container.Config{
+		Env:        img.Config.Env,
+		Cmd:        img.Config.Cmd,
+		Entrypoint: img.Config.Entrypoint,
+		Labels:     img.Config.Labels,
+		WorkingDir: img.Config.WorkingDir,
+		User:       img.Config.User,
+		Volumes:    img.Config.Volumes,
+	}
+}
+
+// withoutImageDefaults drops the values of the config which are the ones of the image, e.g. its PATH or version variables,
+// so that the values of the new image apply
+func withoutImageDefaults(config, image *container.Config) {
+	if image == nil {
+		return
+	}
+
+	var env []string
+	for _, e := range config.Env {
+		if !slices.Contains(image.Env, e) {
+			env = append(env, e)
+		}
+	}
+	config.Env = env
+	if slices.Equal(config.Cmd, image.Cmd) {
+		config.Cmd = nil
+	}
+	if slices.Equal(config.Entrypoint, image.Entrypoint) {
+		config.Entrypoint = nil
+	}
+	if config.WorkingDir == image.WorkingDir {
+		config.WorkingDir = ""
+	}
+	if config.User == image.User {
+		config.User = ""
+	}
+
+	var labels map[string]string
+	for k, v := range config.Labels {
+		if iv, ok := image.Labels[k]; ok && iv == v {
+			continue
+		}
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[k] = v
+	}
+	config.Labels = labels
+
+	var volumes map[string]struct{}
+	for v := range config.Volumes {
+		if _, ok := image.Volumes[v]; ok {
+			continue
+		}
+		if volumes == nil {
+			volumes = map[string]struct{}{}
+		}
+		volumes[v] = struct{}{}
+	}
+	config.Volumes = volumes
+}
+
+// anonymousVolumes returns the mounts of the volumes of the container which are not configured by name,
+// so that the new container keeps their data
+func anonymousVolumes(mounts []container.MountPoint, hostConfig *container.HostConfig) []mount.Mount {
+	configured := map[string]bool{}
+	for _, b := range hostConfig.Binds {
+		if parts := strings.Split(b, ":"); len(parts) > 1 {
+			configured[parts[1]] = true
+		}
+	}
+	for _, m := range hostConfig.Mounts {
+		configured[m.Target] = true
+	}
+
+	var result []mount.Mount
+	for _, m := range mounts {
+		if m.Type != mount.TypeVolume || m.Name == "" || configured[m.Destination] {
+			continue
+		}
+		result = append(result, mount.Mount{
+			Type:     mount.TypeVolume,
+			Source:   m.Name,
+			Target:   m.Destination,
+			ReadOnly: !m.RW,
+		})
+	}
+
+	return result
+}
+
+func firstOrEmpty(s []string) string {
+	if len(s) == 0 {
+		return ""
+	}
+	return s[0]
+}
@@ -0,0 +1,159 @@
+package executors
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainerFromSummary(t *testing.T) {
+	tests := []struct {
+		name    string
+		summary container.Summary
+		want    Container
+	}{
+		{
+			name: "plain container",
+			summary: container.Summary{
+				ID:      "abc",
+				Names:   []string{"/cache"},
+				ImageID: "sha256:old",
+			},
+			want: Container{ID: "abc", Name: "cache", ImageID: "sha256:old"},
+		},
+		{
+			name: "compose container",
+			summary: container.Summary{
+				ID:    "def",
+				Names: []string{"/app-redis-1"},
+				Labels: map[string]string{
+					"com.docker.compose.project":              "app",
+					"com.docker.compose.service":              "redis",
+					"com.docker.compose.project.working_dir":  "/srv/app",
+					"com.docker.compose.project.config_files": "/srv/app/compose.yaml,/srv/app/compose.override.yaml",
+				},
+			},
+			want: Container{
+				ID:                "def",
+				Name:              "app-redis-1",
+				ComposeProject:    "app",
+				ComposeService:    "redis",
+				ComposeWorkingDir: "/srv/app",
+				ComposeFiles:      []string{"/srv/app/compose.yaml", "/srv/app/compose.override.yaml"},
+			},
+		},
+		{
+			name: "podman-compose container",
+			summary: container.Summary{
+				ID:    "ghi",
+				Names: []string{"/app_redis_1"},
+				Labels: map[string]string{
+					"io.podman.compose.project": "app",
+					"io.podman.compose.service": "redis",
+				},
+			},
+			want: Container{
+				ID:             "ghi",
+				Name:           "app_redis_1",
+				ComposeProject: "app",
+				ComposeService: "redis",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, containerFromSummary(tt.summary))
+		})
+	}
+}
+
+func TestRecreateConfig(t *testing.T) {
+	hostConfig := &container.HostConfig{Binds: []string{"/data:/data"}}
+	old := container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{
+			ID:         "0123456789abcdef",
+			HostConfig: hostConfig,
+		},
+		Config: &container.Config{
+			Hostname: "0123456789ab",
+			Image:    "redis:latest",
+			Env:      []string{"A=1"},
+		},
+		NetworkSettings: &container.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{
+				"backend": {
+					Aliases:   []string{"cache"},
+					IPAddress: "172.18.0.2",
+					NetworkID: "net",
+				},
+			},
+		},
+	}
+
+	config, gotHostConfig, networkingConfig := recreateConfig(old, nil, "redis:latest")
+	assert.Equal(t, &container.Config{Image: "redis:latest", Env: []string{"A=1"}}, config)
+	assert.Equal(t, hostConfig, gotHostConfig)
+	assert.Equal(t, &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			"backend": {Aliases: []string{"cache"}},
+		},
+	}, networkingConfig)
+	// the inspected configuration is not modified
+	assert.Equal(t, "0123456789ab", old.Config.Hostname)
+
+	t.Run("anonymous volumes", func(t *testing.T) {
+		// started with docker run -v /srv/conf:/etc/postgresql postgres, which declares VOLUME /var/lib/postgresql/data
+		old := container.InspectResponse{
+			ContainerJSONBase: &container.ContainerJSONBase{
+				ID:         "0123456789abcdef",
+				HostConfig: &container.HostConfig{Binds: []string{"/srv/conf:/etc/postgresql:ro"}},
+			},
+			Config: &container.Config{Image: "postgres:16"},
+			Mounts: []container.MountPoint{
+				{Type: mount.TypeBind, Source: "/srv/conf", Destination: "/etc/postgresql"},
+				{Type: mount.TypeVolume, Name: "4f1c0e8a9b", Destination: "/var/lib/postgresql/data", RW: true},
+			},
+		}
+
+		_, hostConfig, _ := recreateConfig(old, nil, "postgres:16")
+		assert.Equal(t, []string{"/srv/conf:/etc/postgresql:ro"}, hostConfig.Binds)
+		assert.Equal(t, []mount.Mount{
+			{Type: mount.TypeVolume, Source: "4f1c0e8a9b", Target: "/var/lib/postgresql/data"},
+		}, hostConfig.Mounts)
+		assert.Empty(t, old.HostConfig.Mounts)
+	})
+
+	t.Run("image defaults", func(t *testing.T) {
+		oldImage := &container.Config{
+			Env:        []string{"PATH=/usr/lib/postgresql/16/bin:/usr/bin", "PG_VERSION=16.3"},
+			Cmd:        []string{"postgres"},
+			Entrypoint: []string{"docker-entrypoint.sh"},
+			Labels:     map[string]string{"maintainer": "postgres"},
+			Volumes:    map[string]struct{}{"/var/lib/postgresql/data": {}},
+		}
+		old := container.InspectResponse{
+			ContainerJSONBase: &container.ContainerJSONBase{ID: "0123456789abcdef"},
+			Config: &container.Config{
+				Image:      "postgres:16",
+				Env:        []string{"POSTGRES_PASSWORD=secret", "PATH=/usr/lib/postgresql/16/bin:/usr/bin", "PG_VERSION=16.3"},
+				Cmd:        []string{"postgres", "-c", "max_connections=200"},
+				Entrypoint: []string{"docker-entrypoint.sh"},
+				Labels:     map[string]string{"maintainer": "postgres", "team": "billing"},
+				Volumes:    map[string]struct{}{"/var/lib/postgresql/data": {}},
+			},
+		}
+
+		config, _, _ := recreateConfig(old, oldImage, "postgres:16")
+		assert.Equal(t, &container.Config{
+			Image:  "postgres:16",
+			Env:    []string{"POSTGRES_PASSWORD=secret"},
+			Cmd:    []string{"postgres", "-c", "max_connections=200"},
+			Labels: map[string]string{"team": "billing"},
+		}, config)
+		assert.Len(t, old.Config.Labels, 2)
+	})
+}
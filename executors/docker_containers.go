@@ -0,0 +1,118 @@
+package executors
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+func (de *DockerExecutor) StaleContainers(pkgs []string) ([]Container, error) {
+	cs, ok := de.source.(ContainerSource)
+	if !ok {
+		return nil, nil
+	}
+
+	// image ID of the replaced image -> image to recreate with
+	images := map[string]string{}
+	tagChanged := map[string]bool{}
+	de.mu.Lock()
+	for _, pkg := range pkgs {
+		if id, ok := de.replaced[pkg]; ok {
+			images[id] = pkg
+			_, tagChanged[pkg] = de.tagUpdates[pkg]
+		}
+	}
+	de.mu.Unlock()
+	if len(images) == 0 {
+		return nil, nil
+	}
+
+	all, err := cs.Containers(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	var containers []Container
+	for _, c := range all {
+		img, ok := images[c.ImageID]
+		if !ok {
+			continue
+		}
+		// compose files keep referencing the old tag, which is reported after the pull
+		if c.ComposeProject != "" && tagChanged[img] {
+			continue
+		}
+		c.Image = img
+		containers = append(containers, c)
+	}
+
+	return containers, nil
+}
+
+func (de *DockerExecutor) Recreate(c Container, dryRun bool) error {
+	cs, ok := de.source.(ContainerSource)
+	if !ok {
+		return fmt.Errorf("containers are not supported by this image source")
+	}
+
+	if c.ComposeProject != "" {
+		return runCompose(cs.ComposeCommand(), composeUpArgs(c), dryRun)
+	}
+
+	if dryRun {
+		log.Printf("[dry-run] Recreating container %s with %s", c.Name, c.Image)
+		return nil
+	}
+
+	return cs.RecreateContainer(context.Background(), c)
+}
+
+// composeUpArgs returns the arguments of the compose command recreating the service
+func composeUpArgs(c Container) []string {
+	args := composeProjectArgs(c.ComposeProject, c.ComposeWorkingDir, c.ComposeFiles)
+	return append(args, "up", "--detach", c.ComposeService)
+}
+
+// composeProjectArgs returns the arguments of the compose command selecting the project
+func composeProjectArgs(project, dir string, files []string) []string {
+	args := []string{"--project-name", project}
+	if dir != "" {
+		args = append(args, "--project-directory", dir)
+	}
+	for _, f := range files {
+		args = append(args, "--file", f)
+	}
+
+	return args
+}
+
+// runCompose runs the compose command, e.g. docker compose, with the arguments
+func runCompose(compose []string, args []string, dryRun bool) error {
+	args = slices.Concat(compose[1:], args)
+	if dryRun {
+		log.Printf("[dry-run] Running %s %s", compose[0], strings.Join(args, " "))
+		return nil
+	}
+	log.Printf("Running %s %s", compose[0], strings.Join(args, " "))
+	cmd := exec.Command(compose[0], args...) // #nosec G204
+	// the same writer takes both outputs so that they are written one at a time
+	var output bytes.Buffer
+	w := io.MultiWriter(log.Writer(), &output)
+	cmd.Stdout = w
+	cmd.Stderr = w
+	if err := cmd.Run(); err != nil {
+		var tail outputTail
+		for _, line := range strings.Split(output.String(), "\n") {
+			if line != "" {
+				tail.add(line)
+			}
+		}
+		return tail.wrap(err)
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package executors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComposeUpArgs(t *testing.T) {
+	got := composeUpArgs(Container{
+		ComposeProject:    "app",
+		ComposeService:    "redis",
+		ComposeWorkingDir: "/srv/app",
+		ComposeFiles:      []string{"/srv/app/compose.yaml"},
+	})
+	assert.Equal(t, []string{
+		"--project-name", "app", "--project-directory", "/srv/app",
+		"--file", "/srv/app/compose.yaml", "up", "--detach", "redis",
+	}, got)
+}
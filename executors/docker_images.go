@@ -0,0 +1,69 @@
+package executors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+)
+
+func (de *DockerExecutor) DanglingImages() ([]DanglingImage, error) {
+	images, err := de.source.Images(context.Background(), true)
+	if err != nil {
+		return nil, err
+	}
+
+	replacedBy := map[string]string{}
+	de.mu.Lock()
+	for img, id := range de.replaced {
+		replacedBy[id] = img
+	}
+	de.mu.Unlock()
+
+	return danglingImagesFromLocal(images, replacedBy), nil
+}
+
+func (de *DockerExecutor) RemoveImages(imgs []DanglingImage, dryRun bool) (int64, error) {
+	ctx := context.Background()
+
+	var (
+		reclaimed int64
+		errs      []error
+	)
+	for _, img := range imgs {
+		if dryRun {
+			log.Printf("[dry-run] Removing image: %s", img.ID)
+			continue
+		}
+		if err := de.source.RemoveImage(ctx, img.ID); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", img.ID, err))
+			continue
+		}
+		log.Printf("Removed image: %s", img.ID)
+		reclaimed += img.Size
+	}
+
+	return reclaimed, errors.Join(errs...)
+}
+
+// danglingImagesFromLocal lists the images replaced by pulls first, and then the larger ones first
+func danglingImagesFromLocal(images []LocalImage, replacedBy map[string]string) []DanglingImage {
+	imgs := make([]DanglingImage, 0, len(images))
+	for _, i := range images {
+		imgs = append(imgs, DanglingImage{
+			ID:         i.ID,
+			Size:       i.Size,
+			Created:    i.Created,
+			ReplacedBy: replacedBy[i.ID],
+		})
+	}
+	sort.SliceStable(imgs, func(i, j int) bool {
+		if (imgs[i].ReplacedBy != "") != (imgs[j].ReplacedBy != "") {
+			return imgs[i].ReplacedBy != ""
+		}
+		return imgs[i].Size > imgs[j].Size
+	})
+
+	return imgs
+}
@@ -0,0 +1,23 @@
+package executors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDanglingImagesFromLocal(t *testing.T) {
+	images := []LocalImage{
+		{ID: "sha256:small", Size: 10, Created: time.Unix(1700000000, 0)},
+		{ID: "sha256:large", Size: 300, Created: time.Unix(1700000001, 0)},
+		{ID: "sha256:replaced", Size: 20, Created: time.Unix(1700000002, 0)},
+	}
+
+	got := danglingImagesFromLocal(images, map[string]string{"sha256:replaced": "redis:7"})
+	assert.Equal(t, []DanglingImage{
+		{ID: "sha256:replaced", Size: 20, Created: time.Unix(1700000002, 0), ReplacedBy: "redis:7"},
+		{ID: "sha256:large", Size: 300, Created: time.Unix(1700000001, 0)},
+		{ID: "sha256:small", Size: 10, Created: time.Unix(1700000000, 0)},
+	}, got)
+}
@@ -1,66 +1,354 @@
 package executors
 
 import (
+	"context"
 	"testing"
 
+	"github.com/regclient/regclient/types/platform"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestDockerDiffPackageFromHash(t *testing.T) {
-	imageName := "image-name:latest"
+func TestDockerDigestUpdate(t *testing.T) {
+	const (
+		index     = "sha256:51cff8aaa53c0af334e4cd8fce3e698a3d5114dbd530f983f62c8e0c41ad3f8a"
+		manifest  = "sha256:0f3e0c5a07d9e1a0d40b7c1d4bd4cb18c29b6e0a6a7bb2b5f1e13e8ad4d8e36f"
+		oldConfig = "sha256:bdc9d2a52e796649d74a8c2566897d7a45441a11bc6bc68b54a5c4c06c563eb5"
+		newConfig = "sha256:bdc9d2a0000000000000000000000000000000000000000000000000000000ff"
+	)
+	remote := dockerRemoteImage{Digest: index, ManifestDigest: manifest, ConfigDigest: newConfig}
 
 	tests := []struct {
-		localDigest  string
-		remoteDigest string
-		want         *PackageInfo
+		name   string
+		local  dockerLocalImage
+		remote dockerRemoteImage
+		want   *PackageInfo
 	}{
 		{
-			localDigest:  "ghcr.io/open-telemetry/demo@sha256:bdc9d2a52e796649d74a8c2566897d7a45441a11bc6bc68b54a5c4c06c563eb5",
-			remoteDigest: "sha256:51cff8aaa53c0af334e4cd8fce3e698a3d5114dbd530f983f62c8e0c41ad3f8a",
+			name:   "pulled with the current index",
+			local:  dockerLocalImage{ID: oldConfig, RepoDigests: []string{"sha256:aaa", index}},
+			remote: remote,
+			want:   nil,
+		},
+		{
+			name:   "pulled with the current platform manifest",
+			local:  dockerLocalImage{ID: oldConfig, RepoDigests: []string{manifest}},
+			remote: remote,
+			want:   nil,
+		},
+		{
+			name:   "index moved but the image for the platform did not",
+			local:  dockerLocalImage{ID: newConfig, RepoDigests: []string{"sha256:aaa"}},
+			remote: remote,
+			want:   nil,
+		},
+		{
+			name:   "digests sharing the prefix differ",
+			local:  dockerLocalImage{ID: oldConfig, RepoDigests: []string{"sha256:aaa"}},
+			remote: remote,
 			want: &PackageInfo{
-				Name:       imageName,
-				OldVersion: "bdc9d2a",
-				NewVersion: "51cff8a",
+				Name:       "image-name:latest",
+				OldVersion: oldConfig,
+				NewVersion: newConfig,
 			},
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, dockerDigestUpdate("image-name:latest", tt.local, tt.remote))
+		})
+	}
+}
+
+func TestDockerPlatform(t *testing.T) {
+	tests := []struct {
+		os, arch string
+		want     platform.Platform
+	}{
+		{"linux", "x86_64", platform.Platform{OS: "linux", Architecture: "amd64"}},
+		{"linux", "aarch64", platform.Platform{OS: "linux", Architecture: "arm64"}},
+		{"linux", "armv7l", platform.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}},
+		{"windows", "x86_64", platform.Platform{OS: "windows", Architecture: "amd64"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arch, func(t *testing.T) {
+			assert.Equal(t, tt.want, dockerPlatform(tt.os, tt.arch))
+		})
+	}
+}
+
+func TestDockerDetailFromLabels(t *testing.T) {
+	got := dockerDetailFromLabels(map[string]string{
+		"org.opencontainers.image.description": "OpenTelemetry demo",
+		"org.opencontainers.image.url":         "https://github.com/open-telemetry/opentelemetry-demo",
+		"org.opencontainers.image.licenses":    "Apache-2.0",
+	})
+	assert.Equal(t, &PackageDetail{
+		Description: "OpenTelemetry demo",
+		Homepage:    "https://github.com/open-telemetry/opentelemetry-demo",
+		License:     "Apache-2.0",
+		Repository:  "https://github.com/open-telemetry/opentelemetry-demo",
+	}, got)
+}
+
+func TestDockerLabelChanges(t *testing.T) {
+	got := dockerLabelChanges(
+		map[string]string{
+			"org.opencontainers.image.version":  "1.0.0",
+			"org.opencontainers.image.revision": "abc",
+			"maintainer":                        "me",
+			"removed":                           "x",
+		},
+		map[string]string{
+			"org.opencontainers.image.version":  "1.1.0",
+			"org.opencontainers.image.revision": "def",
+			"maintainer":                        "me",
+		},
+	)
+	assert.Equal(t, `org.opencontainers.image.revision
+  abc -> def
+org.opencontainers.image.version
+  1.0.0 -> 1.1.0
+removed
+  x -> (none)`, got)
+}
+
+func TestDockerConfigAllowed(t *testing.T) {
+	tests := []struct {
+		name   string
+		config DockerConfig
+		img    string
+		want   bool
+	}{
+		{
+			name: "everything is allowed by default",
+			img:  "postgres:16",
+			want: true,
+		},
+		{
+			name:   "repository pattern matches every tag",
+			config: DockerConfig{Exclude: []string{"postgres"}},
+			img:    "postgres:16",
+			want:   false,
+		},
+		{
+			name:   "tag pattern",
+			config: DockerConfig{Exclude: []string{"node:*-alpine"}},
+			img:    "node:20-alpine",
+			want:   false,
+		},
 		{
-			localDigest:  "ghcr.io/open-telemetry/demo@sha256:bdc9d2a52e796649d74a8c2566897d7a45441a11bc6bc68b54a5c4c06c563eb5",
-			remoteDigest: "sha256:bdc9d2a52e796649d74a8c2566897d7a45441a11bc6bc68b54a5c4c06c563eb5",
-			want:         nil,
+			name:   "not included",
+			config: DockerConfig{Include: []string{"ghcr.io/myorg/*"}},
+			img:    "postgres:16",
+			want:   false,
+		},
+		{
+			name:   "included",
+			config: DockerConfig{Include: []string{"ghcr.io/myorg/*"}},
+			img:    "ghcr.io/myorg/app:1.0",
+			want:   true,
+		},
+		{
+			name:   "exclude wins over include",
+			config: DockerConfig{Include: []string{"ghcr.io/myorg/*"}, Exclude: []string{"ghcr.io/myorg/app:*"}},
+			img:    "ghcr.io/myorg/app:1.0",
+			want:   false,
 		},
 	}
 
 	for _, tt := range tests {
-		t.Run("", func(t *testing.T) {
-			got, err := dockerDiffPackageFromHash(imageName, tt.localDigest, tt.remoteDigest)
-			assert.Nil(t, err)
-			assert.Equal(t, tt.want, got)
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.config.Allowed(tt.img))
 		})
 	}
 }
 
-func TestDockerDiffPackageFromHashErr(t *testing.T) {
-	imageName := "image-name:latest"
+func TestDockerTagsToCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		repoTags    []string
+		repoDigests []string
+		config      DockerConfig
+		want        map[string][]string
+	}{
+		{
+			name:        "every tag is checked, not only latest",
+			repoTags:    []string{"postgres:16", "postgres:latest", "not-latest-2:1"},
+			repoDigests: []string{"postgres@sha256:aaa"},
+			want: map[string][]string{
+				"postgres:16":     {"sha256:aaa"},
+				"postgres:latest": {"sha256:aaa"},
+			},
+		},
+		{
+			name:     "multiple repositories and repo digests",
+			repoTags: []string{"node:20-alpine", "localhost:5000/node:20"},
+			repoDigests: []string{
+				"node@sha256:aaa",
+				"node@sha256:bbb",
+				"localhost:5000/node@sha256:ccc",
+			},
+			want: map[string][]string{
+				"node:20-alpine":         {"sha256:aaa", "sha256:bbb"},
+				"localhost:5000/node:20": {"sha256:ccc"},
+			},
+		},
+		{
+			name:        "excluded and untagged images",
+			repoTags:    []string{"<none>:<none>", "redis:7"},
+			repoDigests: []string{"redis@sha256:aaa"},
+			config:      DockerConfig{Exclude: []string{"redis"}},
+			want:        map[string][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, dockerTagsToCheck(tt.repoTags, tt.repoDigests, tt.config))
+		})
+	}
+}
+
+func TestDockerTagUpdates(t *testing.T) {
+	tags := []string{
+		"latest", "7", "7.2", "7.2.3", "7.2.4", "7.2.5", "7.4.0", "7.4.0-rc1", "8.0.1", "8.0.2",
+		"7.2.3-alpine", "7.2.10-alpine", "7.4.0-alpine", "v7.9.9",
+	}
 
 	tests := []struct {
-		localDigest  string
-		remoteDigest string
+		name    string
+		current string
+		want    []*PackageInfo
 	}{
 		{
-			localDigest:  "invalid-local-digest",
-			remoteDigest: "sha256:51cff8aaa53c0af334e4cd8fce3e698a3d5114dbd530f983f62c8e0c41ad3f8a",
+			name:    "patch, minor and major",
+			current: "7.2.3",
+			want: []*PackageInfo{
+				{Name: "redis:7.2.5", OldVersion: "7.2.3", NewVersion: "7.2.5", UpdateType: UpdateTypePatch},
+				{Name: "redis:7.4.0", OldVersion: "7.2.3", NewVersion: "7.4.0", UpdateType: UpdateTypeMinor},
+				{Name: "redis:8.0.2", OldVersion: "7.2.3", NewVersion: "8.0.2", UpdateType: UpdateTypeMajor},
+			},
+		},
+		{
+			name:    "suffix is kept",
+			current: "7.2.3-alpine",
+			want: []*PackageInfo{
+				{Name: "redis:7.2.10-alpine", OldVersion: "7.2.3-alpine", NewVersion: "7.2.10-alpine", UpdateType: UpdateTypePatch},
+				{Name: "redis:7.4.0-alpine", OldVersion: "7.2.3-alpine", NewVersion: "7.4.0-alpine", UpdateType: UpdateTypeMinor},
+			},
+		},
+		{
+			name:    "number of components is kept",
+			current: "7",
+			want:    nil,
 		},
 		{
-			localDigest:  "ghcr.io/open-telemetry/demo@sha256:bdc9d2a52e796649d74a8c2566897d7a45441a11bc6bc68b54a5c4c06c563eb5",
-			remoteDigest: "invalid-remote-digest",
+			name:    "not a version",
+			current: "latest",
+			want:    nil,
 		},
 	}
 
 	for _, tt := range tests {
-		t.Run("", func(t *testing.T) {
-			got, err := dockerDiffPackageFromHash(imageName, tt.localDigest, tt.remoteDigest)
-			assert.Error(t, err)
-			assert.Nil(t, got)
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, dockerTagUpdates("redis", tt.current, tags))
 		})
 	}
 }
+
+func TestDockerCheckTagWithRegistry(t *testing.T) {
+	reg := newTestRegistry(t)
+	amd64 := platform.Platform{OS: "linux", Architecture: "amd64"}
+	arm64 := platform.Platform{OS: "linux", Architecture: "arm64"}
+
+	oldIndex := reg.PushIndex("library/redis", "",
+		testPlatformImage{amd64, `{"architecture":"amd64","os":"linux","created":"1"}`},
+		testPlatformImage{arm64, `{"architecture":"arm64","os":"linux","created":"1"}`},
+	)
+	_, amd64Config := reg.PushImage("library/redis", "", `{"architecture":"amd64","os":"linux","created":"1"}`)
+	// only the arm64 image is rebuilt
+	newIndex := reg.PushIndex("library/redis", "7",
+		testPlatformImage{amd64, `{"architecture":"amd64","os":"linux","created":"1"}`},
+		testPlatformImage{arm64, `{"architecture":"arm64","os":"linux","created":"2"}`},
+	)
+	_, arm64Config := reg.PushImage("library/redis", "", `{"architecture":"arm64","os":"linux","created":"1"}`)
+	_, newArm64Config := reg.PushImage("library/redis", "", `{"architecture":"arm64","os":"linux","created":"2"}`)
+	_, singleConfig := reg.PushImage("library/app", "1.0", `{"architecture":"amd64","os":"linux","created":"3"}`)
+
+	de := &DockerExecutor{rc: reg.Client()}
+	redis := reg.Host() + "/library/redis:7"
+	app := reg.Host() + "/library/app:1.0"
+
+	tests := []struct {
+		name    string
+		tag     string
+		local   dockerLocalImage
+		plat    platform.Platform
+		want    *PackageInfo
+		wantErr bool
+	}{
+		{
+			name:  "pulled with the current index",
+			tag:   redis,
+			local: dockerLocalImage{ID: amd64Config, RepoDigests: []string{newIndex}},
+			plat:  amd64,
+		},
+		{
+			name:  "index moved without changes for the platform",
+			tag:   redis,
+			local: dockerLocalImage{ID: amd64Config, RepoDigests: []string{oldIndex}},
+			plat:  amd64,
+		},
+		{
+			name:  "index moved with changes for the platform",
+			tag:   redis,
+			local: dockerLocalImage{ID: arm64Config, RepoDigests: []string{oldIndex}},
+			plat:  arm64,
+			want:  &PackageInfo{Name: redis, OldVersion: arm64Config, NewVersion: newArm64Config},
+		},
+		{
+			name:    "no image for the platform",
+			tag:     redis,
+			local:   dockerLocalImage{ID: amd64Config, RepoDigests: []string{oldIndex}},
+			plat:    platform.Platform{OS: "linux", Architecture: "s390x"},
+			wantErr: true,
+		},
+		{
+			name:  "single platform image",
+			tag:   app,
+			local: dockerLocalImage{ID: amd64Config, RepoDigests: []string{"sha256:aaa"}},
+			plat:  amd64,
+			want:  &PackageInfo{Name: app, OldVersion: amd64Config, NewVersion: singleConfig},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := de.checkTag(context.Background(), tt.tag, tt.local, tt.plat)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDockerCheckNewerTagsWithRegistry(t *testing.T) {
+	reg := newTestRegistry(t)
+	for _, tag := range []string{"7.2.3-alpine", "7.2.5-alpine", "7.2.6", "7.4.0-alpine"} {
+		reg.PushImage("library/redis", tag, `{"tag":"`+tag+`"}`)
+	}
+
+	de := &DockerExecutor{rc: reg.Client()}
+	repo := reg.Host() + "/library/redis"
+	got, err := de.checkNewerTags(context.Background(), repo+":7.2.3-alpine")
+	assert.Nil(t, err)
+	assert.Equal(t, []*PackageInfo{
+		{Name: repo + ":7.2.5-alpine", OldVersion: "7.2.3-alpine", NewVersion: "7.2.5-alpine", UpdateType: UpdateTypePatch},
+		{Name: repo + ":7.4.0-alpine", OldVersion: "7.2.3-alpine", NewVersion: "7.4.0-alpine", UpdateType: UpdateTypeMinor},
+	}, got)
+}
@@ -1,18 +1,141 @@
 package executors
 
 import (
+	"bufio"
 	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ymtdzzz/lazypkg/osv"
 )
 
 // ErrPassword is returned when a required password is not provided
 var ErrPassword = errors.New("password is required")
 
+// UpdateErrors are returned by a bulk update when some of the packages failed, by package.
+// The other packages of the update succeeded.
+type UpdateErrors map[string]error
+
+func (e UpdateErrors) Error() string {
+	pkgs := make([]string, 0, len(e))
+	for pkg := range e {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	// the first line names the packages, as it is the one shown when the error is summarized
+	msg := "Failed to update " + strings.Join(pkgs, ", ")
+	for _, pkg := range pkgs {
+		msg = fmt.Sprintf("%s\n - %s: %v", msg, pkg, e[pkg])
+	}
+	return msg
+}
+
+// PackageError returns the error of the package in a bulk update which returned err, nil when the package was updated.
+// Errors other than UpdateErrors are the errors of every package of the update.
+func PackageError(err error, pkg string) error {
+	var errs UpdateErrors
+	if errors.As(err, &errs) {
+		return errs[pkg]
+	}
+	return err
+}
+
 // PackageInfo represents information about a package including its name and version details
 type PackageInfo struct {
 	Name       string
 	OldVersion string
 	NewVersion string
+	// Origin is where the new version comes from, e.g. the apt suites
+	Origin string
+	// Security is true when the update fixes a known vulnerability
+	Security bool
+	// UpdateType classifies an update to another version such as a newer image tag.
+	// It is empty when the version itself is refreshed, e.g. a moved docker tag.
+	UpdateType string
+	// CheckError is set when the package could not be checked for updates.
+	// It is listed so that it does not look up to date.
+	CheckError string
+	// Group is what the package belongs to, e.g. the apt suites, the brew tap, the npm scope or the compose project.
+	// Packages can be listed under a header per group.
+	Group string
+	// Size is the size of the package in bytes, zero when unknown
+	Size int64
+	// Updated is when the installed version was installed, zero when unknown
+	Updated time.Time
+	// Source and SourceVersion are the source package the package is built from, e.g. openssl for libssl3.
+	// They are only set for installed apt packages.
+	Source        string
+	SourceVersion string
+}
+
+const (
+	UpdateTypeMajor = "major"
+	UpdateTypeMinor = "minor"
+	UpdateTypePatch = "patch"
+)
+
+// versionNumbersPattern matches the leading numbers of versions such as 1:2.34.1-1ubuntu1 or v18.15.0
+var versionNumbersPattern = regexp.MustCompile(`^(?:\d+:)?v?(\d+)(?:\.(\d+))?(?:\.(\d+))?`)
+
+// ClassifyUpdate tells whether the update from the old to the new version is a major, minor or patch update
+// by the first number which changes. It returns the UpdateType of the package when it is set,
+// and an empty string when the versions are not numeric.
+func ClassifyUpdate(pkg *PackageInfo) string {
+	if pkg.UpdateType != "" {
+		return pkg.UpdateType
+	}
+	o := versionNumbersPattern.FindStringSubmatch(pkg.OldVersion)
+	n := versionNumbersPattern.FindStringSubmatch(pkg.NewVersion)
+	if o == nil || n == nil {
+		return ""
+	}
+	switch {
+	case o[1] != n[1]:
+		return UpdateTypeMajor
+	case o[2] != n[2]:
+		return UpdateTypeMinor
+	default:
+		return UpdateTypePatch
+	}
+}
+
+// CheckErrorAuth means the registry refused the credentials, or asked for them
+const CheckErrorAuth = "auth failed"
+
+// PackageDetail represents additional metadata about a package.
+// Sizes are in bytes and zero when unknown.
+type PackageDetail struct {
+	Description   string
+	DownloadSize  int64
+	InstalledSize int64
+	Homepage      string
+	License       string
+	Repository    string
+}
+
+// PlanEntry is a single package change in an update plan
+type PlanEntry struct {
+	Name       string
+	OldVersion string
+	NewVersion string
+}
+
+// UpdatePlan describes the changes an update would make to the system
+type UpdatePlan struct {
+	Upgrades []PlanEntry
+	// Installs are packages newly pulled in by the update
+	Installs []PlanEntry
+	Removals []PlanEntry
+	// DownloadSize is in bytes and zero when unknown
+	DownloadSize int64
 }
 
 // Executor defines the interface for package management operations
@@ -38,7 +161,194 @@ type Executor interface {
 	Close()
 }
 
+// DetailFetcher is implemented by executors that can look up metadata of a package
+type DetailFetcher interface {
+	// GetDetail retrieves additional metadata of the given package.
+	GetDetail(pkg string) (*PackageDetail, error)
+}
+
+// ChangelogFetcher is implemented by executors that can describe what changed in an update
+type ChangelogFetcher interface {
+	// GetChangelog retrieves the changes between the installed and the new version of the package.
+	GetChangelog(pkg *PackageInfo) (string, error)
+}
+
+// Planner is implemented by executors that can simulate an update before running it
+type Planner interface {
+	// Plan simulates updating the given packages and returns the changes it would make.
+	Plan(pkgs []string) (*UpdatePlan, error)
+}
+
+// ProgressReporter is implemented by executors able to tell how far a running update of a package is
+type ProgressReporter interface {
+	// Progress returns the completed fraction of the update of the package, false when it is unknown
+	Progress(pkg string) (float64, bool)
+}
+
+// UpdateLister is implemented by executors which only update the packages listed by the last GetPackages,
+// e.g. compose, which updates the services to the tags it found for them
+type UpdateLister interface {
+	// UpdatesListed reports whether GetPackages listed the packages, otherwise it has to run before the update
+	UpdatesListed(pkgs []string) bool
+}
+
+// Container is a container created from an image replaced by an update
+type Container struct {
+	ID   string
+	Name string
+	// ImageID is the image the container was created from
+	ImageID string
+	// Image is the image the container is recreated with
+	Image string
+	// ComposeProject and ComposeService are set for containers managed by docker compose
+	ComposeProject    string
+	ComposeService    string
+	ComposeWorkingDir string
+	ComposeFiles      []string
+}
+
+// ContainerRecreator is implemented by executors able to recreate containers after updates
+type ContainerRecreator interface {
+	// StaleContainers returns the containers still running the images replaced by the last update of the packages
+	StaleContainers(pkgs []string) ([]Container, error)
+	// Recreate recreates the container with its current configuration and the new image
+	Recreate(c Container, dryRun bool) error
+}
+
+// DanglingImage is an untagged image left behind, e.g. by pulling a newer image
+type DanglingImage struct {
+	ID      string
+	Size    int64
+	Created time.Time
+	// ReplacedBy is the image whose pull made this image dangling, empty when unknown
+	ReplacedBy string
+}
+
+// ImagePruner is implemented by executors able to remove dangling images
+type ImagePruner interface {
+	DanglingImages() ([]DanglingImage, error)
+	// RemoveImages removes the images and returns the reclaimed size
+	RemoveImages(imgs []DanglingImage, dryRun bool) (int64, error)
+}
+
+// InstalledLister is implemented by executors that can enumerate every installed package
+type InstalledLister interface {
+	// GetInstalled retrieves all installed packages. The installed version is set to OldVersion.
+	GetInstalled() ([]*PackageInfo, error)
+
+	// Ecosystem returns the OSV ecosystem name of the packages.
+	Ecosystem() string
+}
+
+var githubRepoPattern = regexp.MustCompile(`github\.com[/:]([^/\s]+)/([^/\s#?]+)`)
+
 func cmdExists(cmd string) bool {
 	_, err := exec.LookPath(cmd)
 	return err == nil
 }
+
+// setInstalledDir sets the size of the package and when it was installed from the directory it is installed in.
+// They are left unknown when the directory is missing.
+func setInstalledDir(pkg *PackageInfo, dir string) {
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return
+	}
+	pkg.Updated = info.ModTime()
+
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		if fi, err := d.Info(); err == nil {
+			size += fi.Size()
+		}
+		return nil
+	})
+	pkg.Size = size
+}
+
+// installedVersion returns the newest of the installed versions listed by the package manager, e.g. "1.0, 1.1"
+func installedVersion(versions string) string {
+	parts := strings.Split(versions, ", ")
+	return parts[len(parts)-1]
+}
+
+// scanOutput runs the command and passes each line it writes to stdout or stderr to read.
+// Both streams are written to the same pipe, so that neither of them fills up and blocks the command while the other is read.
+func scanOutput(cmd *exec.Cmd, read func(line string)) error {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	cmd.Stdout = w
+	cmd.Stderr = w
+	err = cmd.Start()
+	// the command keeps its own copy of the write end, so the output ends when the command exits
+	w.Close()
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		read(scanner.Text())
+	}
+	// a line too long for the scanner stops it, so drain the rest to let the command finish
+	_, _ = io.Copy(io.Discard, r)
+
+	return cmd.Wait()
+}
+
+// outputTailLines is the number of the last lines of the output kept to tell why a command failed
+const outputTailLines = 20
+
+// outputTail keeps the last lines of the output of a command
+type outputTail []string
+
+func (t *outputTail) add(line string) {
+	*t = append(*t, line)
+	if len(*t) > outputTailLines {
+		*t = (*t)[len(*t)-outputTailLines:]
+	}
+}
+
+// wrap appends the kept output to the error of the command
+func (t outputTail) wrap(err error) error {
+	if len(t) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w\n%s", err, strings.Join(t, "\n"))
+}
+
+// repositoryFromURL returns the canonical GitHub repository URL found in the given urls
+func repositoryFromURL(urls ...string) string {
+	for _, u := range urls {
+		matches := githubRepoPattern.FindStringSubmatch(u)
+		if len(matches) < 3 {
+			continue
+		}
+		return "https://github.com/" + matches[1] + "/" + strings.TrimSuffix(matches[2], ".git")
+	}
+
+	return ""
+}
+
+// markSecurityUpdates flags packages whose installed version is affected by an
+// advisory in the database which the new version no longer is.
+func markSecurityUpdates(db *osv.Database, ecosystem string, pkgs []*PackageInfo) {
+	if db == nil {
+		return
+	}
+	for _, pkg := range pkgs {
+		for _, v := range db.Vulnerabilities(ecosystem, pkg.Name, pkg.OldVersion) {
+			if !v.Affects(ecosystem, pkg.Name, pkg.NewVersion) {
+				pkg.Security = true
+				break
+			}
+		}
+	}
+}
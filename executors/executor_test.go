@@ -0,0 +1,208 @@
+package executors
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ymtdzzz/lazypkg/osv"
+)
+
+func TestRepositoryFromURL(t *testing.T) {
+	tests := []struct {
+		input []string
+		want  string
+	}{
+		{
+			input: []string{"git+https://github.com/nodejs/corepack.git"},
+			want:  "https://github.com/nodejs/corepack",
+		},
+		{
+			input: []string{"git@github.com:jqlang/jq.git"},
+			want:  "https://github.com/jqlang/jq",
+		},
+		{
+			input: []string{"https://curl.se/", "https://github.com/curl/curl/tree/master"},
+			want:  "https://github.com/curl/curl",
+		},
+		{
+			input: []string{"https://example.com/repo"},
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			assert.Equal(t, tt.want, repositoryFromURL(tt.input...))
+		})
+	}
+}
+
+func TestMarkSecurityUpdates(t *testing.T) {
+	db, err := osv.Load("../osv/testdata")
+	assert.Nil(t, err)
+
+	pkgs := []*PackageInfo{
+		{Name: "semver", OldVersion: "7.5.1", NewVersion: "7.6.0"},
+		{Name: "semver", OldVersion: "7.4.0", NewVersion: "7.5.0"},
+		{Name: "corepack", OldVersion: "0.29.4", NewVersion: "0.31.0"},
+	}
+	markSecurityUpdates(db, osv.EcosystemNPM, pkgs)
+
+	assert.True(t, pkgs[0].Security)
+	assert.False(t, pkgs[1].Security)
+	assert.False(t, pkgs[2].Security)
+}
+
+func TestClassifyUpdate(t *testing.T) {
+	tests := []struct {
+		name string
+		pkg  *PackageInfo
+		want string
+	}{
+		{
+			name: "major",
+			pkg:  &PackageInfo{OldVersion: "16.14.0", NewVersion: "18.15.0"},
+			want: UpdateTypeMajor,
+		},
+		{
+			name: "minor with a v prefix",
+			pkg:  &PackageInfo{OldVersion: "v1.2.3", NewVersion: "v1.3.0"},
+			want: UpdateTypeMinor,
+		},
+		{
+			name: "patch of a debian version",
+			pkg:  &PackageInfo{OldVersion: "1:2.34.1-1ubuntu1", NewVersion: "1:2.34.1-1ubuntu1.1"},
+			want: UpdateTypePatch,
+		},
+		{
+			name: "set by the executor",
+			pkg:  &PackageInfo{OldVersion: "7.2.3", NewVersion: "8.0.0", UpdateType: UpdateTypeMajor},
+			want: UpdateTypeMajor,
+		},
+		{
+			name: "digests",
+			pkg:  &PackageInfo{OldVersion: "sha256:aaa", NewVersion: "sha256:bbb"},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClassifyUpdate(tt.pkg))
+		})
+	}
+}
+
+func TestPackageError(t *testing.T) {
+	pullErr := errors.New("manifest unknown")
+	errs := UpdateErrors{"redis:7": pullErr, "nginx:latest": errors.New("timeout")}
+	assert.Equal(t, "Failed to update nginx:latest, redis:7\n - nginx:latest: timeout\n - redis:7: manifest unknown", errs.Error())
+
+	tests := []struct {
+		name string
+		err  error
+		pkg  string
+		want error
+	}{
+		{name: "succeeded", err: nil, pkg: "curl", want: nil},
+		{name: "every package failed", err: pullErr, pkg: "curl", want: pullErr},
+		{name: "the package failed", err: errs, pkg: "redis:7", want: pullErr},
+		{name: "another package failed", err: fmt.Errorf("update failed: %w", errs), pkg: "postgres:16", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, PackageError(tt.err, tt.pkg))
+		})
+	}
+}
+
+func TestOutputTail(t *testing.T) {
+	err := errors.New("exit status 100")
+
+	var tail outputTail
+	assert.Equal(t, err, tail.wrap(err))
+
+	for i := range outputTailLines + 5 {
+		tail.add(fmt.Sprintf("line %d", i))
+	}
+	assert.Len(t, tail, outputTailLines)
+	assert.Equal(t, "line 5", tail[0])
+
+	wrapped := tail.wrap(err)
+	assert.ErrorIs(t, wrapped, err)
+	assert.True(t, strings.HasPrefix(wrapped.Error(), "exit status 100\nline 5\nline 6\n"))
+}
+
+func TestScanOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "stdout and stderr",
+			script: "echo out; echo err >&2; echo done",
+			want:   []string{"out", "err", "done"},
+		},
+		{
+			name:   "stderr larger than the pipe buffer before stdout ends",
+			script: "i=0; while [ $i -lt 2000 ]; do echo 'error output that fills the pipe buffer' >&2; i=$((i+1)); done; echo done",
+			want:   append(slices.Repeat([]string{"error output that fills the pipe buffer"}, 2000), "done"),
+		},
+		{
+			name:   "line too long for the scanner",
+			script: "head -c 100000 /dev/zero | tr '\\0' a; echo; echo done",
+			want:   []string{},
+		},
+		{
+			name:    "failure",
+			script:  "echo failed >&2; exit 1",
+			want:    []string{"failed"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := []string{}
+			err := scanOutput(exec.Command("sh", "-c", tt.script), func(line string) {
+				lines = append(lines, line)
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, lines)
+		})
+	}
+}
+
+func TestSetInstalledDir(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.MkdirAll(filepath.Join(dir, "lib"), 0o755))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "package.json"), make([]byte, 100), 0o644))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "lib", "index.js"), make([]byte, 2000), 0o644))
+	installed := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	require.Nil(t, os.Chtimes(dir, installed, installed))
+
+	pkg := &PackageInfo{Name: "typescript"}
+	setInstalledDir(pkg, dir)
+	assert.Equal(t, int64(2100), pkg.Size)
+	assert.True(t, installed.Equal(pkg.Updated))
+
+	missing := &PackageInfo{Name: "missing"}
+	setInstalledDir(missing, filepath.Join(dir, "missing"))
+	assert.Equal(t, int64(0), missing.Size)
+	assert.True(t, missing.Updated.IsZero())
+}
@@ -1,17 +1,37 @@
 package executors
 
 import (
-	"bufio"
 	"fmt"
 	"log"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/ymtdzzz/lazypkg/osv"
+	"gopkg.in/yaml.v3"
 )
 
-var gemPattern = regexp.MustCompile(`^([^\s]+)\s+\(([^\s]+)\s<\s([^\s]+)\)`)
+var (
+	gemPattern          = regexp.MustCompile(`^([^\s]+)\s+\(([^\s]+)\s<\s([^\s]+)\)`)
+	gemInstalledPattern = regexp.MustCompile(`^([^\s]+)\s+\((.+)\)$`)
+)
 
-type GemExecutor struct{}
+type gemSpecification struct {
+	Summary     string            `yaml:"summary"`
+	Description string            `yaml:"description"`
+	Homepage    string            `yaml:"homepage"`
+	Licenses    []string          `yaml:"licenses"`
+	Metadata    map[string]string `yaml:"metadata"`
+}
+
+type GemExecutor struct {
+	// GitHub is used to fetch release notes. The default client is used when nil.
+	GitHub *GitHubClient
+	// Advisories is used to detect security updates when it is set.
+	Advisories *osv.Database
+}
 
 func (ge *GemExecutor) Valid() bool {
 	return cmdExists("gem")
@@ -37,11 +57,27 @@ func (ge *GemExecutor) GetPackages(_ string) ([]*PackageInfo, error) {
 		}
 	}
 
+	log.Print("Running gem environment gempath")
+	if output, err := exec.Command("gem", "environment", "gempath").Output(); err == nil {
+		paths := filepath.SplitList(strings.TrimSpace(string(output)))
+		for _, pkg := range packages {
+			if dir := gemInstalledDir(paths, pkg); dir != "" {
+				setInstalledDir(pkg, dir)
+			}
+		}
+	}
+	markSecurityUpdates(ge.Advisories, osv.EcosystemRubyGems, packages)
+
 	return packages, nil
 }
 
-func (ge *GemExecutor) Update(pkg, _ string, dryRun bool) error {
-	cmds := []string{"gem", "update", pkg}
+func (ge *GemExecutor) Update(pkg, password string, dryRun bool) error {
+	return ge.BulkUpdate([]string{pkg}, password, dryRun)
+}
+
+func (ge *GemExecutor) BulkUpdate(pkgs []string, _ string, dryRun bool) error {
+	cmds := []string{"gem", "update"}
+	cmds = append(cmds, pkgs...)
 	if dryRun {
 		log.Printf("[dry-run] %s", strings.Join(cmds, " "))
 		return nil
@@ -51,64 +87,74 @@ func (ge *GemExecutor) Update(pkg, _ string, dryRun bool) error {
 	// #nosec G204: commands are not input values
 	cmd := exec.Command(cmds[0], cmds[1:]...)
 
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return err
-	}
-
-	if err := cmd.Start(); err != nil {
-		return err
-	}
-
-	scanner := bufio.NewScanner(stdout)
-	for scanner.Scan() {
-		line := scanner.Text()
+	var tail outputTail
+	err := scanOutput(cmd, func(line string) {
 		log.Print(line)
-	}
-
-	if err := cmd.Wait(); err != nil {
-		return err
+		tail.add(line)
+	})
+	if err != nil {
+		return tail.wrap(err)
 	}
 
 	return nil
 }
 
-func (ge *GemExecutor) BulkUpdate(pkgs []string, _ string, dryRun bool) error {
-	cmds := []string{"gem", "update"}
-	cmds = append(cmds, pkgs...)
-	if dryRun {
-		log.Printf("[dry-run] %s", strings.Join(cmds, " "))
-		return nil
+func (ge *GemExecutor) GetDetail(pkg string) (*PackageDetail, error) {
+	log.Printf("Running gem specification --remote %s", pkg)
+	// #nosec G204: package name is taken from gem outdated output
+	cmd := exec.Command("gem", "specification", "--remote", pkg)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
 	}
 
-	log.Printf("Running %s", strings.Join(cmds, " "))
-	// #nosec G204: commands are not input values
-	cmd := exec.Command(cmds[0], cmds[1:]...)
+	return gemDetailFromYAML(output)
+}
 
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return err
-	}
+func (ge *GemExecutor) GetChangelog(pkg *PackageInfo) (string, error) {
+	return githubReleaseNotes(ge, ge.GitHub, pkg)
+}
 
-	if err := cmd.Start(); err != nil {
-		return err
+func (ge *GemExecutor) GetInstalled() ([]*PackageInfo, error) {
+	log.Print("Running gem list --local")
+	cmd := exec.Command("gem", "list", "--local")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
 	}
 
-	scanner := bufio.NewScanner(stdout)
-	for scanner.Scan() {
-		line := scanner.Text()
-		log.Print(line)
+	var packages []*PackageInfo
+	for _, line := range strings.Split(string(output), "\n") {
+		packages = append(packages, gemInstalledFromString(line)...)
 	}
 
-	if err := cmd.Wait(); err != nil {
-		return err
-	}
+	return packages, nil
+}
 
-	return nil
+func (ge *GemExecutor) Ecosystem() string {
+	return osv.EcosystemRubyGems
 }
 
 func (ge *GemExecutor) Close() {}
 
+// gemInstalledDir looks up the directory the installed version of the gem is unpacked to in the gem paths,
+// which may be suffixed with the platform, e.g. nokogiri-1.16.0-x86_64-linux
+func gemInstalledDir(paths []string, pkg *PackageInfo) string {
+	prefix := pkg.Name + "-" + installedVersion(pkg.OldVersion)
+	for _, p := range paths {
+		entries, err := os.ReadDir(filepath.Join(p, "gems"))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() && (e.Name() == prefix || strings.HasPrefix(e.Name(), prefix+"-")) {
+				return filepath.Join(p, "gems", e.Name())
+			}
+		}
+	}
+	return ""
+}
+
 func gemPackageFromString(input string) (*PackageInfo, error) {
 	matches := gemPattern.FindStringSubmatch(input)
 	if len(matches) < 4 {
@@ -120,3 +166,51 @@ func gemPackageFromString(input string) (*PackageInfo, error) {
 		NewVersion: matches[3],
 	}, nil
 }
+
+func gemDetailFromYAML(input []byte) (*PackageDetail, error) {
+	var spec gemSpecification
+	if err := yaml.Unmarshal(input, &spec); err != nil {
+		return nil, err
+	}
+
+	desc := strings.TrimSpace(spec.Description)
+	if desc == "" {
+		desc = spec.Summary
+	}
+
+	return &PackageDetail{
+		Description: desc,
+		Homepage:    spec.Homepage,
+		License:     strings.Join(spec.Licenses, ", "),
+		Repository: repositoryFromURL(
+			spec.Metadata["source_code_uri"],
+			spec.Metadata["homepage_uri"],
+			spec.Homepage,
+		),
+	}, nil
+}
+
+// gemInstalledFromString parses a row like "json (2.7.2, default: 2.7.1)"
+// which lists every installed version of the gem
+func gemInstalledFromString(input string) []*PackageInfo {
+	matches := gemInstalledPattern.FindStringSubmatch(strings.TrimSpace(input))
+	if len(matches) < 3 {
+		return nil
+	}
+
+	var packages []*PackageInfo
+	for _, v := range strings.Split(matches[2], ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "default: ")
+		// platform suffix such as "1.16.5 x86_64-linux"
+		v, _, _ = strings.Cut(v, " ")
+		if v == "" {
+			continue
+		}
+		packages = append(packages, &PackageInfo{
+			Name:       matches[1],
+			OldVersion: v,
+		})
+	}
+
+	return packages
+}
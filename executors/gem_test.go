@@ -1,9 +1,12 @@
 package executors
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGemPackageFromString(t *testing.T) {
@@ -51,3 +54,79 @@ func TestGemPackageFromStringErr(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, got)
 }
+
+func TestGemDetailFromYAML(t *testing.T) {
+	input := `--- !ruby/object:Gem::Specification
+name: rake
+version: !ruby/object:Gem::Version
+  version: 13.2.1
+summary: Rake is a Make-like program implemented in Ruby
+description: |
+  Rake is a Make-like program implemented in Ruby. Tasks and dependencies are
+  specified in standard Ruby syntax.
+homepage: https://github.com/ruby/rake
+licenses:
+- MIT
+metadata:
+  bug_tracker_uri: https://github.com/ruby/rake/issues
+  source_code_uri: https://github.com/ruby/rake/tree/v13.2.1
+`
+
+	got, err := gemDetailFromYAML([]byte(input))
+	assert.Nil(t, err)
+	assert.Equal(t, &PackageDetail{
+		Description: "Rake is a Make-like program implemented in Ruby. Tasks and dependencies are\nspecified in standard Ruby syntax.",
+		Homepage:    "https://github.com/ruby/rake",
+		License:     "MIT",
+		Repository:  "https://github.com/ruby/rake",
+	}, got)
+}
+
+func TestGemInstalledFromString(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []*PackageInfo
+	}{
+		{
+			input: "json (2.7.2, default: 2.7.1)",
+			want: []*PackageInfo{
+				{Name: "json", OldVersion: "2.7.2"},
+				{Name: "json", OldVersion: "2.7.1"},
+			},
+		},
+		{
+			input: "nokogiri (1.16.5 x86_64-linux)",
+			want: []*PackageInfo{
+				{Name: "nokogiri", OldVersion: "1.16.5"},
+			},
+		},
+		{
+			input: "*** LOCAL GEMS ***",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			assert.Equal(t, tt.want, gemInstalledFromString(tt.input))
+		})
+	}
+}
+
+func TestGemInstalledDir(t *testing.T) {
+	user, system := t.TempDir(), t.TempDir()
+	for _, dir := range []string{
+		filepath.Join(user, "gems", "rails-7.1.0.1"),
+		filepath.Join(system, "gems", "rails-7.1.0"),
+		filepath.Join(system, "gems", "nokogiri-1.16.0-x86_64-linux"),
+	} {
+		require.Nil(t, os.MkdirAll(dir, 0o755))
+	}
+	paths := []string{user, system, filepath.Join(user, "missing")}
+
+	assert.Equal(t, filepath.Join(system, "gems", "rails-7.1.0"),
+		gemInstalledDir(paths, &PackageInfo{Name: "rails", OldVersion: "7.1.0"}))
+	assert.Equal(t, filepath.Join(system, "gems", "nokogiri-1.16.0-x86_64-linux"),
+		gemInstalledDir(paths, &PackageInfo{Name: "nokogiri", OldVersion: "1.16.0"}))
+	assert.Equal(t, "", gemInstalledDir(paths, &PackageInfo{Name: "rack", OldVersion: "3.0.0"}))
+}
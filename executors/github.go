@@ -0,0 +1,198 @@
+package executors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	defaultGitHubBaseURL = "https://api.github.com"
+	maxReleaseNotes      = 10
+	releasesPerPage      = 100
+	// maxReleasePages limits the requests made for a package, as they count against the rate limit
+	maxReleasePages = 10
+)
+
+var (
+	defaultGitHubClient = NewGitHubClient(defaultGitHubBaseURL)
+	// homebrewRevisionPattern matches the revision of a formula rebuilt without a new upstream version, e.g. the _1 of 1.7.1_1
+	homebrewRevisionPattern = regexp.MustCompile(`_\d+$`)
+)
+
+type githubRelease struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Body        string    `json:"body"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// GitHubClient fetches release notes through the GitHub REST API
+type GitHubClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewGitHubClient creates a client for the API served at baseURL.
+// GITHUB_TOKEN is used for authentication when it is set.
+func NewGitHubClient(baseURL string) *GitHubClient {
+	return &GitHubClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   os.Getenv("GITHUB_TOKEN"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ReleaseNotes returns the notes of the releases published after oldVersion up to newVersion
+func (c *GitHubClient) ReleaseNotes(repository, oldVersion, newVersion string) (string, error) {
+	owner, repo, err := githubOwnerRepo(repository)
+	if err != nil {
+		return "", err
+	}
+
+	var releases []githubRelease
+	for page := 1; page <= maxReleasePages; page++ {
+		rs, err := c.releases(owner, repo, page)
+		if err != nil {
+			return "", err
+		}
+		releases = append(releases, rs...)
+		if len(rs) < releasesPerPage || slices.ContainsFunc(rs, func(r githubRelease) bool {
+			return tagMatchesVersion(r.TagName, oldVersion)
+		}) {
+			break
+		}
+	}
+
+	between, err := releasesBetween(releases, oldVersion, newVersion)
+	if err != nil {
+		return "", err
+	}
+
+	return formatReleaseNotes(between), nil
+}
+
+// releases fetches a page of the releases of the repository
+func (c *GitHubClient) releases(owner, repo string, page int) ([]githubRelease, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=%d&page=%d", c.baseURL, owner, repo, releasesPerPage, page)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status from GitHub: %s", resp.Status)
+	}
+
+	var releases []githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, err
+	}
+
+	return releases, nil
+}
+
+// releasesBetween picks releases newer than oldVersion and not newer than newVersion.
+// GitHub returns releases ordered from the newest one.
+// Both versions must have a release, otherwise the range is unknown.
+func releasesBetween(releases []githubRelease, oldVersion, newVersion string) ([]githubRelease, error) {
+	start := slices.IndexFunc(releases, func(r githubRelease) bool {
+		return tagMatchesVersion(r.TagName, newVersion)
+	})
+	if start < 0 {
+		return nil, fmt.Errorf("release notes not found for %s", newVersion)
+	}
+
+	end := slices.IndexFunc(releases[start:], func(r githubRelease) bool {
+		return tagMatchesVersion(r.TagName, oldVersion)
+	})
+	if end < 0 {
+		return nil, fmt.Errorf("release notes not found for %s", oldVersion)
+	}
+
+	return releases[start : start+end], nil
+}
+
+// formatReleaseNotes renders the notes of the newest maxReleaseNotes releases and tells how many older ones are left out
+func formatReleaseNotes(releases []githubRelease) string {
+	if len(releases) == 0 {
+		return "No release notes found"
+	}
+
+	var sb strings.Builder
+	for _, r := range releases[:min(len(releases), maxReleaseNotes)] {
+		title := r.Name
+		if title == "" {
+			title = r.TagName
+		}
+		fmt.Fprintf(&sb, "## %s (%s)\n\n", title, r.PublishedAt.Format(time.DateOnly))
+		sb.WriteString(strings.TrimSpace(strings.ReplaceAll(r.Body, "\r\n", "\n")))
+		sb.WriteString("\n\n")
+	}
+	if hidden := len(releases) - maxReleaseNotes; hidden > 0 {
+		fmt.Fprintf(&sb, "… %d older releases not shown", hidden)
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// tagMatchesVersion reports whether a tag such as v1.2.3 or jq-1.2.3 names the version
+func tagMatchesVersion(tag, version string) bool {
+	if version == "" {
+		return false
+	}
+	if tag == version {
+		return true
+	}
+	if len(tag) <= len(version) || !strings.HasSuffix(tag, version) {
+		return false
+	}
+	return strings.ContainsRune("v-_@/", rune(tag[len(tag)-len(version)-1]))
+}
+
+func githubOwnerRepo(repository string) (string, string, error) {
+	matches := githubRepoPattern.FindStringSubmatch(repository)
+	if len(matches) < 3 {
+		return "", "", fmt.Errorf("not a GitHub repository: %s", repository)
+	}
+	return matches[1], strings.TrimSuffix(matches[2], ".git"), nil
+}
+
+// githubReleaseNotes looks up the repository of the package and returns its release notes
+func githubReleaseNotes(fetcher DetailFetcher, client *GitHubClient, pkg *PackageInfo) (string, error) {
+	detail, err := fetcher.GetDetail(pkg.Name)
+	if err != nil {
+		return "", err
+	}
+	if detail.Repository == "" {
+		return "", fmt.Errorf("no repository is known for %s", pkg.Name)
+	}
+	if client == nil {
+		client = defaultGitHubClient
+	}
+
+	return client.ReleaseNotes(detail.Repository, releaseVersion(installedVersion(pkg.OldVersion)), releaseVersion(pkg.NewVersion))
+}
+
+// releaseVersion returns the upstream version releases are tagged with, without the revision of a Homebrew formula
+// such as the _1 of 1.7.1_1 or the build of a cask such as the ,1234 of 1.6,1234
+func releaseVersion(version string) string {
+	version, _, _ = strings.Cut(version, ",")
+	return homebrewRevisionPattern.ReplaceAllString(version, "")
+}
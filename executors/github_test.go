@@ -0,0 +1,230 @@
+package executors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitHubClientReleaseNotes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/nodejs/corepack/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`[
+  {"tag_name": "v0.32.0", "name": "v0.32.0", "body": "Not yet installable", "published_at": "2025-03-01T00:00:00Z"},
+  {"tag_name": "v0.31.0", "name": "", "body": "- Feature B\r\n- Fix C", "published_at": "2025-01-10T00:00:00Z"},
+  {"tag_name": "v0.30.0", "name": "v0.30.0", "body": "- Feature A", "published_at": "2024-11-20T00:00:00Z"},
+  {"tag_name": "v0.29.4", "name": "v0.29.4", "body": "Installed", "published_at": "2024-09-07T00:00:00Z"}
+]`))
+	}))
+	defer srv.Close()
+
+	c := NewGitHubClient(srv.URL)
+
+	t.Run("releases between versions", func(t *testing.T) {
+		got, err := c.ReleaseNotes("https://github.com/nodejs/corepack", "0.29.4", "0.31.0")
+		assert.Nil(t, err)
+		assert.Equal(t, "## v0.31.0 (2025-01-10)\n\n- Feature B\n- Fix C\n\n## v0.30.0 (2024-11-20)\n\n- Feature A", got)
+	})
+
+	t.Run("Homebrew versions", func(t *testing.T) {
+		want := "## v0.31.0 (2025-01-10)\n\n- Feature B\n- Fix C\n\n## v0.30.0 (2024-11-20)\n\n- Feature A"
+		fetcher := repositoryFetcher("https://github.com/nodejs/corepack")
+		for _, pkg := range []*PackageInfo{
+			{Name: "corepack", OldVersion: "0.29.4_1", NewVersion: "0.31.0_2"},
+			{Name: "corepack", OldVersion: "0.29.3, 0.29.4_1", NewVersion: "0.31.0"},
+			{Name: "corepack", OldVersion: "0.29.4,1234", NewVersion: "0.31.0,5678"},
+		} {
+			got, err := githubReleaseNotes(fetcher, c, pkg)
+			assert.Nil(t, err)
+			assert.Equal(t, want, got)
+		}
+	})
+
+	t.Run("unknown repository", func(t *testing.T) {
+		_, err := c.ReleaseNotes("https://github.com/nodejs/unknown", "0.29.4", "0.31.0")
+		assert.Error(t, err)
+	})
+
+	t.Run("not a GitHub repository", func(t *testing.T) {
+		_, err := c.ReleaseNotes("https://gitlab.com/foo/bar", "0.29.4", "0.31.0")
+		assert.Error(t, err)
+	})
+}
+
+func TestGitHubClientReleaseNotesPages(t *testing.T) {
+	// 250 releases from v1.249.0 down to v1.0.0, served 100 per page
+	var requests []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		requests = append(requests, page)
+
+		releases := []githubRelease{}
+		for i := (page - 1) * perPage; i < page*perPage && i < 250; i++ {
+			tag := fmt.Sprintf("v1.%d.0", 249-i)
+			releases = append(releases, githubRelease{TagName: tag, Body: "Release " + tag, PublishedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)})
+		}
+		_ = json.NewEncoder(w).Encode(releases)
+	}))
+	defer srv.Close()
+
+	c := NewGitHubClient(srv.URL)
+
+	tests := []struct {
+		name         string
+		oldVersion   string
+		newVersion   string
+		want         string
+		wantErr      string
+		wantRequests []int
+	}{
+		{
+			name:         "versions on a later page",
+			oldVersion:   "1.118.0",
+			newVersion:   "1.120.0",
+			want:         "## v1.120.0 (2025-01-01)\n\nRelease v1.120.0\n\n## v1.119.0 (2025-01-01)\n\nRelease v1.119.0",
+			wantRequests: []int{1, 2},
+		},
+		{
+			name:         "versions on different pages",
+			oldVersion:   "1.149.0",
+			newVersion:   "1.151.0",
+			want:         "## v1.151.0 (2025-01-01)\n\nRelease v1.151.0\n\n## v1.150.0 (2025-01-01)\n\nRelease v1.150.0",
+			wantRequests: []int{1, 2},
+		},
+		{
+			name:         "old version not released",
+			oldVersion:   "0.9.0",
+			newVersion:   "1.249.0",
+			wantErr:      "release notes not found for 0.9.0",
+			wantRequests: []int{1, 2, 3},
+		},
+		{
+			name:         "new version not released",
+			oldVersion:   "1.0.0",
+			newVersion:   "2.0.0",
+			wantErr:      "release notes not found for 2.0.0",
+			wantRequests: []int{1, 2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = nil
+			got, err := c.ReleaseNotes("https://github.com/foo/bar", tt.oldVersion, tt.newVersion)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.Equal(t, tt.wantRequests, requests)
+		})
+	}
+}
+
+func TestFormatReleaseNotes(t *testing.T) {
+	var releases []githubRelease
+	for i := 12; i > 0; i-- {
+		releases = append(releases, githubRelease{TagName: fmt.Sprintf("v1.%d.0", i), Body: "Fixes"})
+	}
+
+	got := formatReleaseNotes(releases)
+	assert.True(t, strings.HasPrefix(got, "## v1.12.0 (0001-01-01)\n\nFixes\n\n"))
+	assert.Contains(t, got, "## v1.3.0 (0001-01-01)\n\nFixes\n\n… 2 older releases not shown")
+	assert.NotContains(t, got, "v1.2.0")
+	assert.Equal(t, "No release notes found", formatReleaseNotes(nil))
+}
+
+func TestReleaseVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{version: "1.7.1", want: "1.7.1"},
+		{version: "1.7.1_1", want: "1.7.1"},
+		{version: "1.6,1234", want: "1.6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			assert.Equal(t, tt.want, releaseVersion(tt.version))
+		})
+	}
+}
+
+func TestReleasesBetween(t *testing.T) {
+	releases := []githubRelease{
+		{TagName: "v3.0.0"},
+		{TagName: "v2.1.0"},
+		{TagName: "v2.0.0"},
+		{TagName: "v1.0.0"},
+	}
+
+	tests := []struct {
+		name       string
+		oldVersion string
+		newVersion string
+		want       []string
+		wantErr    string
+	}{
+		{name: "range", oldVersion: "1.0.0", newVersion: "2.1.0", want: []string{"v2.1.0", "v2.0.0"}},
+		{name: "same version", oldVersion: "2.0.0", newVersion: "2.0.0", want: []string{}},
+		{name: "new version not found", oldVersion: "1.0.0", newVersion: "2.2.0", wantErr: "release notes not found for 2.2.0"},
+		{name: "old version not found", oldVersion: "0.9.0", newVersion: "2.1.0", wantErr: "release notes not found for 0.9.0"},
+		{name: "old version newer than new version", oldVersion: "3.0.0", newVersion: "2.1.0", wantErr: "release notes not found for 3.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := releasesBetween(releases, tt.oldVersion, tt.newVersion)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.Nil(t, err)
+			tags := []string{}
+			for _, r := range got {
+				tags = append(tags, r.TagName)
+			}
+			assert.Equal(t, tt.want, tags)
+		})
+	}
+}
+
+// repositoryFetcher returns the repository as the detail of every package
+type repositoryFetcher string
+
+func (r repositoryFetcher) GetDetail(string) (*PackageDetail, error) {
+	return &PackageDetail{Repository: string(r)}, nil
+}
+
+func TestTagMatchesVersion(t *testing.T) {
+	tests := []struct {
+		tag     string
+		version string
+		want    bool
+	}{
+		{tag: "1.2.3", version: "1.2.3", want: true},
+		{tag: "v1.2.3", version: "1.2.3", want: true},
+		{tag: "jq-1.7.1", version: "1.7.1", want: true},
+		{tag: "11.2.3", version: "1.2.3", want: false},
+		{tag: "v1.2.3", version: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			assert.Equal(t, tt.want, tagMatchesVersion(tt.tag, tt.version))
+		})
+	}
+}
@@ -1,17 +1,47 @@
 package executors
 
 import (
-	"bufio"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os/exec"
+	"path"
+	"path/filepath"
 	"regexp"
 	"strings"
 )
 
-var homebrewPattern = regexp.MustCompile(`(\S+) \(([^)]+)\) < (\S+)`)
+var (
+	homebrewPattern        = regexp.MustCompile(`(\S+) \(([^)]+)\) < (\S+)`)
+	homebrewUpgradePattern = regexp.MustCompile(`^(\S+) (\S+) -> (\S+)$`)
+)
+
+type homebrewInfo struct {
+	Formulae []struct {
+		Desc     string `json:"desc"`
+		Homepage string `json:"homepage"`
+		License  string `json:"license"`
+		URLs     struct {
+			Stable struct {
+				URL string `json:"url"`
+			} `json:"stable"`
+			Head struct {
+				URL string `json:"url"`
+			} `json:"head"`
+		} `json:"urls"`
+	} `json:"formulae"`
+	Casks []struct {
+		Desc     string `json:"desc"`
+		Homepage string `json:"homepage"`
+		URL      string `json:"url"`
+	} `json:"casks"`
+}
 
-type HomebrewExecutor struct{}
+type HomebrewExecutor struct {
+	// GitHub is used to fetch release notes. The default client is used when nil.
+	GitHub   *GitHubClient
+	progress progressTracker
+}
 
 func (he *HomebrewExecutor) Valid() bool {
 	return cmdExists("brew")
@@ -44,73 +74,81 @@ func (he *HomebrewExecutor) GetPackages(_ string) ([]*PackageInfo, error) {
 		}
 	}
 
+	log.Print("Running brew --cellar")
+	if output, err := exec.Command("brew", "--cellar").Output(); err == nil {
+		cellar := strings.TrimSpace(string(output))
+		for _, pkg := range packages {
+			setInstalledDir(pkg, homebrewKegDir(cellar, pkg))
+		}
+	}
+
 	return packages, nil
 }
 
-func (he *HomebrewExecutor) Update(pkg, _ string, dryRun bool) error {
+func (he *HomebrewExecutor) Update(pkg, password string, dryRun bool) error {
+	return he.BulkUpdate([]string{pkg}, password, dryRun)
+}
+
+func (he *HomebrewExecutor) BulkUpdate(pkgs []string, password string, dryRun bool) error {
 	cmds := []string{"brew", "upgrade"}
 	if dryRun {
 		cmds = append(cmds, "--dry-run")
 	}
-	cmds = append(cmds, pkg)
+	cmds = append(cmds, pkgs...)
+
+	progress := newStageProgress(homebrewProgressPatterns, pkgs)
+	he.progress.start(progress)
+	defer he.progress.stop()
 
 	log.Printf("Running %s", strings.Join(cmds, " "))
 	// #nosec G204: commands are not input values
 	cmd := exec.Command(cmds[0], cmds[1:]...)
 
-	stdout, err := cmd.StdoutPipe()
+	var tail outputTail
+	err := scanOutput(cmd, func(line string) {
+		log.Print(line)
+		progress.read(line)
+		tail.add(line)
+	})
 	if err != nil {
-		return err
+		return tail.wrap(err)
 	}
 
-	if err := cmd.Start(); err != nil {
-		return err
-	}
+	return nil
+}
 
-	scanner := bufio.NewScanner(stdout)
-	for scanner.Scan() {
-		line := scanner.Text()
-		log.Print(line)
-	}
+// Progress returns how far the running upgrade of the formula is, from the stages brew prints for it
+func (he *HomebrewExecutor) Progress(pkg string) (float64, bool) {
+	return he.progress.progress(pkg)
+}
 
-	if err := cmd.Wait(); err != nil {
-		return err
+func (he *HomebrewExecutor) GetDetail(pkg string) (*PackageDetail, error) {
+	log.Printf("Running brew info --json=v2 %s", pkg)
+	// #nosec G204: package name is taken from brew outdated output
+	cmd := exec.Command("brew", "info", "--json=v2", pkg)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return homebrewDetailFromJSON(output)
 }
 
-func (he *HomebrewExecutor) BulkUpdate(pkgs []string, password string, dryRun bool) error {
-	cmds := []string{"brew", "upgrade"}
-	if dryRun {
-		cmds = append(cmds, "--dry-run")
-	}
-	cmds = append(cmds, pkgs...)
+func (he *HomebrewExecutor) GetChangelog(pkg *PackageInfo) (string, error) {
+	return githubReleaseNotes(he, he.GitHub, pkg)
+}
 
+func (he *HomebrewExecutor) Plan(pkgs []string) (*UpdatePlan, error) {
+	cmds := append([]string{"brew", "upgrade", "--dry-run"}, pkgs...)
 	log.Printf("Running %s", strings.Join(cmds, " "))
 	// #nosec G204: commands are not input values
 	cmd := exec.Command(cmds[0], cmds[1:]...)
-
-	stdout, err := cmd.StdoutPipe()
+	output, err := cmd.Output()
 	if err != nil {
-		return err
-	}
-
-	if err := cmd.Start(); err != nil {
-		return err
-	}
-
-	scanner := bufio.NewScanner(stdout)
-	for scanner.Scan() {
-		line := scanner.Text()
-		log.Print(line)
-	}
-
-	if err := cmd.Wait(); err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return homebrewPlanFromString(string(output)), nil
 }
 
 func (he *HomebrewExecutor) Close() {}
@@ -124,5 +162,87 @@ func homebrewPackageFromString(input string) (*PackageInfo, error) {
 		Name:       matches[1],
 		OldVersion: matches[2],
 		NewVersion: matches[3],
+		Group:      homebrewTap(matches[1]),
 	}, nil
 }
+
+// homebrewKegDir returns the directory the installed version of the formula is poured to,
+// which is named without the tap
+func homebrewKegDir(cellar string, pkg *PackageInfo) string {
+	return filepath.Join(cellar, path.Base(pkg.Name), installedVersion(pkg.OldVersion))
+}
+
+// homebrewTap returns the tap of the formula. brew prints the full names of formulae outside homebrew/core.
+func homebrewTap(name string) string {
+	parts := strings.Split(name, "/")
+	if len(parts) < 3 {
+		return "homebrew/core"
+	}
+	return parts[0] + "/" + parts[1]
+}
+
+func homebrewDetailFromJSON(input []byte) (*PackageDetail, error) {
+	var info homebrewInfo
+	if err := json.Unmarshal(input, &info); err != nil {
+		return nil, err
+	}
+
+	if len(info.Formulae) > 0 {
+		f := info.Formulae[0]
+		return &PackageDetail{
+			Description: f.Desc,
+			Homepage:    f.Homepage,
+			License:     f.License,
+			Repository:  repositoryFromURL(f.URLs.Head.URL, f.URLs.Stable.URL, f.Homepage),
+		}, nil
+	}
+	if len(info.Casks) > 0 {
+		c := info.Casks[0]
+		return &PackageDetail{
+			Description: c.Desc,
+			Homepage:    c.Homepage,
+			Repository:  repositoryFromURL(c.URL, c.Homepage),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no formula or cask found: %s", input)
+}
+
+// homebrewPlanFromString parses the output of brew upgrade --dry-run
+func homebrewPlanFromString(input string) *UpdatePlan {
+	plan := &UpdatePlan{}
+	section := ""
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "==> Would upgrade"):
+			section = "upgrade"
+			continue
+		case strings.HasPrefix(line, "==> Would install"):
+			section = "install"
+			continue
+		case strings.HasPrefix(line, "==>"):
+			section = ""
+			continue
+		}
+
+		switch section {
+		case "upgrade":
+			if matches := homebrewUpgradePattern.FindStringSubmatch(line); len(matches) > 3 {
+				plan.Upgrades = append(plan.Upgrades, PlanEntry{
+					Name:       matches[1],
+					OldVersion: matches[2],
+					NewVersion: matches[3],
+				})
+			}
+		case "install":
+			for _, name := range strings.FieldsFunc(line, func(r rune) bool { return r == ' ' || r == ',' }) {
+				plan.Installs = append(plan.Installs, PlanEntry{Name: name})
+			}
+		}
+	}
+
+	return plan
+}
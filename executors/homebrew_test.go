@@ -17,6 +17,7 @@ func TestHomebrewPackageFromString(t *testing.T) {
 				Name:       "fastfetch",
 				OldVersion: "2.33.0",
 				NewVersion: "2.35.0",
+				Group:      "homebrew/core",
 			},
 		},
 		{
@@ -25,6 +26,16 @@ func TestHomebrewPackageFromString(t *testing.T) {
 				Name:       "openjdk",
 				OldVersion: "23.0.1",
 				NewVersion: "23.0.2",
+				Group:      "homebrew/core",
+			},
+		},
+		{
+			input: "ymtdzzz/tap/lazypkg (0.1.0) < 0.2.0",
+			want: &PackageInfo{
+				Name:       "ymtdzzz/tap/lazypkg",
+				OldVersion: "0.1.0",
+				NewVersion: "0.2.0",
+				Group:      "ymtdzzz/tap",
 			},
 		},
 	}
@@ -43,3 +54,69 @@ func TestHomebrewPackageFromStringErr(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, got)
 }
+
+func TestHomebrewDetailFromJSON(t *testing.T) {
+	tests := []struct {
+		input string
+		want  *PackageDetail
+	}{
+		{
+			input: `{"formulae":[{"name":"jq","desc":"Lightweight and flexible command-line JSON processor","homepage":"https://jqlang.github.io/jq/","license":"MIT","urls":{"stable":{"url":"https://github.com/jqlang/jq/releases/download/jq-1.7.1/jq-1.7.1.tar.gz"},"head":{"url":"https://github.com/jqlang/jq.git"}}}],"casks":[]}`,
+			want: &PackageDetail{
+				Description: "Lightweight and flexible command-line JSON processor",
+				Homepage:    "https://jqlang.github.io/jq/",
+				License:     "MIT",
+				Repository:  "https://github.com/jqlang/jq",
+			},
+		},
+		{
+			input: `{"formulae":[],"casks":[{"token":"iterm2","desc":"Terminal emulator as alternative to Apple's Terminal app","homepage":"https://iterm2.com/","url":"https://iterm2.com/downloads/stable/iTerm2-3_5_4.zip"}]}`,
+			want: &PackageDetail{
+				Description: "Terminal emulator as alternative to Apple's Terminal app",
+				Homepage:    "https://iterm2.com/",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			got, err := homebrewDetailFromJSON([]byte(tt.input))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHomebrewDetailFromJSONErr(t *testing.T) {
+	got, err := homebrewDetailFromJSON([]byte(`{"formulae":[],"casks":[]}`))
+	assert.Error(t, err)
+	assert.Nil(t, got)
+}
+
+func TestHomebrewPlanFromString(t *testing.T) {
+	input := `==> Would upgrade 2 outdated packages:
+fastfetch 2.33.0 -> 2.35.0
+wget 1.21.3 -> 1.21.4
+==> Would install 2 dependencies for wget:
+libidn2 libunistring
+`
+
+	got := homebrewPlanFromString(input)
+	assert.Equal(t, &UpdatePlan{
+		Upgrades: []PlanEntry{
+			{Name: "fastfetch", OldVersion: "2.33.0", NewVersion: "2.35.0"},
+			{Name: "wget", OldVersion: "1.21.3", NewVersion: "1.21.4"},
+		},
+		Installs: []PlanEntry{
+			{Name: "libidn2"},
+			{Name: "libunistring"},
+		},
+	}, got)
+}
+
+func TestHomebrewKegDir(t *testing.T) {
+	assert.Equal(t, "/opt/homebrew/Cellar/fastfetch/2.33.0",
+		homebrewKegDir("/opt/homebrew/Cellar", &PackageInfo{Name: "fastfetch", OldVersion: "2.33.0"}))
+	assert.Equal(t, "/opt/homebrew/Cellar/kubectx/0.9.5",
+		homebrewKegDir("/opt/homebrew/Cellar", &PackageInfo{Name: "ahmetb/tap/kubectx", OldVersion: "0.9.4, 0.9.5"}))
+}
@@ -0,0 +1,44 @@
+package executors
+
+import (
+	"context"
+	"time"
+
+	"github.com/regclient/regclient/types/platform"
+)
+
+// LocalImage is an image stored by a container engine
+type LocalImage struct {
+	ID          string
+	RepoTags    []string
+	RepoDigests []string
+	Size        int64
+	Created     time.Time
+	Labels      map[string]string
+}
+
+// ImageSource is a container engine whose images are checked against registries
+type ImageSource interface {
+	// Platform returns the platform of the images the engine pulls
+	Platform(ctx context.Context) (platform.Platform, error)
+	// Images lists the images, or the dangling ones only
+	Images(ctx context.Context, dangling bool) ([]LocalImage, error)
+	InspectImage(ctx context.Context, ref string) (LocalImage, error)
+	// Pull pulls the image and reports the progress of its layers to progress when the engine tells it.
+	// auth is the encoded registry login of the Docker API, empty to pull anonymously
+	// or when the engine reads the credentials itself.
+	Pull(ctx context.Context, ref, auth string, progress *PullProgress) error
+	RemoveImage(ctx context.Context, id string) error
+	Valid(ctx context.Context) bool
+	Close() error
+}
+
+// ContainerSource is implemented by image sources able to manage containers
+type ContainerSource interface {
+	// Containers lists every container including stopped ones
+	Containers(ctx context.Context) ([]Container, error)
+	// RecreateContainer replaces the container with a new one created from the same configuration and its Image
+	RecreateContainer(ctx context.Context, c Container) error
+	// ComposeCommand returns the command running compose for the engine, e.g. docker compose
+	ComposeCommand() []string
+}
@@ -0,0 +1,132 @@
+package executors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/regclient/regclient/types/platform"
+)
+
+type nerdctlImage struct {
+	ID          string   `json:"Id"`
+	RepoTags    []string `json:"RepoTags"`
+	RepoDigests []string `json:"RepoDigests"`
+	Created     string   `json:"Created"`
+	Size        int64    `json:"Size"`
+	Config      struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+}
+
+// nerdctlSource manages the images of containerd through the nerdctl CLI
+type nerdctlSource struct{}
+
+func (s *nerdctlSource) Platform(_ context.Context) (platform.Platform, error) {
+	// nerdctl talks to the containerd running on this host
+	return platform.Local(), nil
+}
+
+func (s *nerdctlSource) Images(ctx context.Context, dangling bool) ([]LocalImage, error) {
+	args := []string{"images", "--quiet", "--no-trunc"}
+	if dangling {
+		args = append(args, "--filter", "dangling=true")
+	}
+	log.Printf("Running nerdctl %s", strings.Join(args, " "))
+	out, err := exec.CommandContext(ctx, "nerdctl", args...).Output() // #nosec G204
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	seen := map[string]bool{}
+	for _, id := range strings.Fields(string(out)) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return s.inspect(ctx, ids...)
+}
+
+func (s *nerdctlSource) InspectImage(ctx context.Context, ref string) (LocalImage, error) {
+	images, err := s.inspect(ctx, ref)
+	if err != nil {
+		return LocalImage{}, err
+	}
+	if len(images) == 0 {
+		return LocalImage{}, fmt.Errorf("no such image: %s", ref)
+	}
+	return images[0], nil
+}
+
+func (s *nerdctlSource) inspect(ctx context.Context, refs ...string) ([]LocalImage, error) {
+	args := append([]string{"image", "inspect", "--mode=dockercompat"}, refs...)
+	out, err := exec.CommandContext(ctx, "nerdctl", args...).Output() // #nosec G204
+	if err != nil {
+		return nil, err
+	}
+	return nerdctlImagesFromJSON(out)
+}
+
+// Pull ignores auth as nerdctl reads ~/.docker/config.json itself.
+// The progress is written to the log as nerdctl has no machine readable output for it.
+func (s *nerdctlSource) Pull(ctx context.Context, ref, _ string, _ *PullProgress) error {
+	log.Printf("Running nerdctl pull %s", ref)
+	cmd := exec.CommandContext(ctx, "nerdctl", "pull", ref) // #nosec G204
+	cmd.Stdout = log.Writer()
+	cmd.Stderr = log.Writer()
+	return cmd.Run()
+}
+
+func (s *nerdctlSource) RemoveImage(ctx context.Context, id string) error {
+	log.Printf("Running nerdctl rmi %s", id)
+	out, err := exec.CommandContext(ctx, "nerdctl", "rmi", id).CombinedOutput() // #nosec G204
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (s *nerdctlSource) Valid(ctx context.Context) bool {
+	if !cmdExists("nerdctl") {
+		return false
+	}
+	return exec.CommandContext(ctx, "nerdctl", "info").Run() == nil
+}
+
+func (s *nerdctlSource) Close() error {
+	return nil
+}
+
+func nerdctlImagesFromJSON(b []byte) ([]LocalImage, error) {
+	var raw []nerdctlImage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+
+	images := make([]LocalImage, 0, len(raw))
+	for _, r := range raw {
+		img := LocalImage{
+			ID:          r.ID,
+			RepoTags:    r.RepoTags,
+			RepoDigests: r.RepoDigests,
+			Size:        r.Size,
+			Labels:      r.Config.Labels,
+		}
+		if created, err := time.Parse(time.RFC3339Nano, r.Created); err == nil {
+			img.Created = created
+		}
+		images = append(images, img)
+	}
+
+	return images, nil
+}
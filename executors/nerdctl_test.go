@@ -0,0 +1,46 @@
+package executors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNerdctlImagesFromJSON(t *testing.T) {
+	input := `[
+  {
+    "Id": "sha256:5d2d5b5e0ae36a3d4ad9bfc5e3b7c2e4a2b3c8f0b1b0d8b8a1e7d6c7b8a9f0e1",
+    "RepoTags": ["redis:7"],
+    "RepoDigests": ["redis@sha256:0f3e0c5a07d9e1a0d40b7c1d4bd4cb18c29b6e0a6a7bb2b5f1e13e8ad4d8e36f"],
+    "Created": "2024-05-01T10:00:00.123456789Z",
+    "Size": 45678912,
+    "Config": {"Labels": {"org.opencontainers.image.version": "7.2.4"}}
+  },
+  {
+    "Id": "sha256:aaaa",
+    "RepoTags": null,
+    "RepoDigests": null,
+    "Created": "",
+    "Size": 1024,
+    "Config": {}
+  }
+]`
+
+	got, err := nerdctlImagesFromJSON([]byte(input))
+	assert.Nil(t, err)
+	assert.Equal(t, []LocalImage{
+		{
+			ID:          "sha256:5d2d5b5e0ae36a3d4ad9bfc5e3b7c2e4a2b3c8f0b1b0d8b8a1e7d6c7b8a9f0e1",
+			RepoTags:    []string{"redis:7"},
+			RepoDigests: []string{"redis@sha256:0f3e0c5a07d9e1a0d40b7c1d4bd4cb18c29b6e0a6a7bb2b5f1e13e8ad4d8e36f"},
+			Created:     time.Date(2024, 5, 1, 10, 0, 0, 123456789, time.UTC),
+			Size:        45678912,
+			Labels:      map[string]string{"org.opencontainers.image.version": "7.2.4"},
+		},
+		{
+			ID:   "sha256:aaaa",
+			Size: 1024,
+		},
+	}, got)
+}
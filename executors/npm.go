@@ -1,17 +1,54 @@
 package executors
 
 import (
-	"bufio"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+
+	"github.com/ymtdzzz/lazypkg/osv"
 )
 
 var npmPattern = regexp.MustCompile(`^([^\s]+)\s+([^\s]+)\s+([^\s]+)\s+([^\s]+)\s+([^\s]+)\s+global`)
 
-type NpmExecutor struct{}
+type npmView struct {
+	Description string          `json:"description"`
+	Homepage    string          `json:"homepage"`
+	License     json.RawMessage `json:"license"`
+	Repository  json.RawMessage `json:"repository"`
+	Dist        struct {
+		UnpackedSize int64 `json:"unpackedSize"`
+	} `json:"dist"`
+}
+
+type npmAudit struct {
+	Vulnerabilities map[string]struct {
+		Name  string `json:"name"`
+		Range string `json:"range"`
+	} `json:"vulnerabilities"`
+	// Error is reported instead, e.g. EAUDITGLOBAL by npm 7 and later which cannot audit global packages
+	Error *struct {
+		Code    string `json:"code"`
+		Summary string `json:"summary"`
+	} `json:"error"`
+}
+
+type npmList struct {
+	Dependencies map[string]struct {
+		Version string `json:"version"`
+	} `json:"dependencies"`
+}
+
+type NpmExecutor struct {
+	// GitHub is used to fetch release notes. The default client is used when nil.
+	GitHub *GitHubClient
+	// Advisories is used to detect security updates when it is set.
+	Advisories *osv.Database
+}
 
 func (ne *NpmExecutor) Valid() bool {
 	return cmdExists("npm")
@@ -35,73 +72,101 @@ func (he *NpmExecutor) GetPackages(_ string) ([]*PackageInfo, error) {
 		}
 	}
 
+	log.Print("Running npm root -g")
+	if output, err := exec.Command("npm", "root", "-g").Output(); err == nil {
+		root := strings.TrimSpace(string(output))
+		for _, pkg := range packages {
+			setInstalledDir(pkg, filepath.Join(root, pkg.Name))
+		}
+	}
+
+	log.Print("Running npm audit --global --json")
+	cmd = exec.Command("npm", "audit", "--global", "--json")
+	// NOTE: npm audit returns exit code 1 when vulnerabilities are found
+	output, _ = cmd.Output()
+	if affected, err := npmAuditFromJSON(output); err == nil {
+		for _, pkg := range packages {
+			r, ok := affected[pkg.Name]
+			pkg.Security = ok && npmRangeContains(r, pkg.OldVersion) && !npmRangeContains(r, pkg.NewVersion)
+		}
+	} else {
+		log.Printf("Failed to get npm audit result: %v", err)
+	}
+	// npm audit does not work for global packages on current npm, the OSV database does
+	markSecurityUpdates(he.Advisories, osv.EcosystemNPM, packages)
+
 	return packages, nil
 }
 
-func (he *NpmExecutor) Update(pkg, _ string, dryRun bool) error {
+func (he *NpmExecutor) Update(pkg, password string, dryRun bool) error {
+	return he.BulkUpdate([]string{pkg}, password, dryRun)
+}
+
+func (he *NpmExecutor) BulkUpdate(pkgs []string, _ string, dryRun bool) error {
 	cmds := []string{"npm", "update", "-g"}
 	if dryRun {
 		cmds = append(cmds, "--dry-run")
 	}
-	cmds = append(cmds, pkg)
+	cmds = append(cmds, pkgs...)
 
 	log.Printf("Running %s", strings.Join(cmds, " "))
 	// #nosec G204: commands are not input values
 	cmd := exec.Command(cmds[0], cmds[1:]...)
 
-	stdout, err := cmd.StdoutPipe()
+	var tail outputTail
+	err := scanOutput(cmd, func(line string) {
+		log.Print(line)
+		tail.add(line)
+	})
 	if err != nil {
-		return err
-	}
-
-	if err := cmd.Start(); err != nil {
-		return err
+		return tail.wrap(err)
 	}
 
-	scanner := bufio.NewScanner(stdout)
-	for scanner.Scan() {
-		line := scanner.Text()
-		log.Print(line)
-	}
+	return nil
+}
 
-	if err := cmd.Wait(); err != nil {
-		return err
+func (he *NpmExecutor) GetDetail(pkg string) (*PackageDetail, error) {
+	log.Printf("Running npm view --json %s", pkg)
+	// #nosec G204: package name is taken from npm outdated output
+	cmd := exec.Command("npm", "view", "--json", pkg)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return npmDetailFromJSON(output)
 }
 
-func (he *NpmExecutor) BulkUpdate(pkgs []string, _ string, dryRun bool) error {
-	cmds := []string{"npm", "update", "-g"}
-	if dryRun {
-		cmds = append(cmds, "--dry-run")
-	}
-	cmds = append(cmds, pkgs...)
+func (he *NpmExecutor) GetChangelog(pkg *PackageInfo) (string, error) {
+	return githubReleaseNotes(he, he.GitHub, pkg)
+}
 
+func (he *NpmExecutor) Plan(pkgs []string) (*UpdatePlan, error) {
+	cmds := append([]string{"npm", "update", "-g", "--dry-run"}, pkgs...)
 	log.Printf("Running %s", strings.Join(cmds, " "))
 	// #nosec G204: commands are not input values
 	cmd := exec.Command(cmds[0], cmds[1:]...)
-
-	stdout, err := cmd.StdoutPipe()
+	output, err := cmd.Output()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if err := cmd.Start(); err != nil {
-		return err
-	}
+	return npmPlanFromString(string(output)), nil
+}
 
-	scanner := bufio.NewScanner(stdout)
-	for scanner.Scan() {
-		line := scanner.Text()
-		log.Print(line)
+func (he *NpmExecutor) GetInstalled() ([]*PackageInfo, error) {
+	log.Print("Running npm ls -g --depth=0 --json")
+	cmd := exec.Command("npm", "ls", "-g", "--depth=0", "--json")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
 	}
 
-	if err := cmd.Wait(); err != nil {
-		return err
-	}
+	return npmInstalledFromJSON(output)
+}
 
-	return nil
+func (he *NpmExecutor) Ecosystem() string {
+	return osv.EcosystemNPM
 }
 
 func (he *NpmExecutor) Close() {}
@@ -115,5 +180,158 @@ func npmPackageFromString(input string) (*PackageInfo, error) {
 		Name:       matches[1],
 		OldVersion: matches[2],
 		NewVersion: matches[3], // Wanted version
+		Group:      npmScope(matches[1]),
+	}, nil
+}
+
+// npmScope returns the scope of the package such as @types, empty for unscoped packages
+func npmScope(name string) string {
+	if scope, _, ok := strings.Cut(name, "/"); ok && strings.HasPrefix(scope, "@") {
+		return scope
+	}
+	return ""
+}
+
+func npmDetailFromJSON(input []byte) (*PackageDetail, error) {
+	var view npmView
+	if err := json.Unmarshal(input, &view); err != nil {
+		return nil, err
+	}
+
+	return &PackageDetail{
+		Description:   view.Description,
+		InstalledSize: view.Dist.UnpackedSize,
+		Homepage:      view.Homepage,
+		License:       npmStringOrField(view.License, "type"),
+		Repository:    repositoryFromURL(npmStringOrField(view.Repository, "url"), view.Homepage),
 	}, nil
 }
+
+// npmStringOrField handles package.json fields which can be either a string or an object
+func npmStringOrField(raw json.RawMessage, field string) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(raw, &obj); err == nil {
+		if v, ok := obj[field].(string); ok {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// npmAuditFromJSON returns the range of the vulnerable versions of each package
+func npmAuditFromJSON(input []byte) (map[string]string, error) {
+	var audit npmAudit
+	if err := json.Unmarshal(input, &audit); err != nil {
+		return nil, err
+	}
+	if audit.Error != nil {
+		return nil, fmt.Errorf("%s: %s", audit.Error.Code, audit.Error.Summary)
+	}
+
+	result := map[string]string{}
+	for name, v := range audit.Vulnerabilities {
+		result[name] = v.Range
+	}
+
+	return result, nil
+}
+
+// npmRangeContains reports whether the version is in a range such as "<1.2.3", ">=1.0.0 <1.0.5 || 2.0.0 - 2.0.3" or "*"
+func npmRangeContains(r, version string) bool {
+	for _, set := range strings.Split(r, "||") {
+		fields := strings.Fields(set)
+		if len(fields) == 3 && fields[1] == "-" {
+			fields = []string{">=" + fields[0], "<=" + fields[2]}
+		}
+		contains := true
+		for _, f := range fields {
+			if !npmComparatorMatches(f, version) {
+				contains = false
+				break
+			}
+		}
+		if contains {
+			return true
+		}
+	}
+
+	return false
+}
+
+func npmComparatorMatches(comparator, version string) bool {
+	if comparator == "*" || comparator == "x" {
+		return true
+	}
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if v, ok := strings.CutPrefix(comparator, op); ok {
+			c := osv.Compare(osv.EcosystemNPM, version, v)
+			switch op {
+			case ">=":
+				return c >= 0
+			case "<=":
+				return c <= 0
+			case ">":
+				return c > 0
+			case "<":
+				return c < 0
+			default:
+				return c == 0
+			}
+		}
+	}
+
+	return osv.Compare(osv.EcosystemNPM, version, comparator) == 0
+}
+
+func npmInstalledFromJSON(input []byte) ([]*PackageInfo, error) {
+	var list npmList
+	if err := json.Unmarshal(input, &list); err != nil {
+		return nil, err
+	}
+
+	packages := make([]*PackageInfo, 0, len(list.Dependencies))
+	for name, dep := range list.Dependencies {
+		packages = append(packages, &PackageInfo{
+			Name:       name,
+			OldVersion: dep.Version,
+		})
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+
+	return packages, nil
+}
+
+// npmPlanFromString parses the output of npm update --dry-run
+// which lists changes like "change corepack 0.29.4 => 0.31.0"
+func npmPlanFromString(input string) *UpdatePlan {
+	plan := &UpdatePlan{}
+	for _, line := range strings.Split(input, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		switch fields[0] {
+		case "add":
+			plan.Installs = append(plan.Installs, PlanEntry{Name: fields[1], NewVersion: fields[2]})
+		case "remove":
+			plan.Removals = append(plan.Removals, PlanEntry{Name: fields[1], OldVersion: fields[2]})
+		case "change":
+			if len(fields) < 5 {
+				continue
+			}
+			plan.Upgrades = append(plan.Upgrades, PlanEntry{Name: fields[1], OldVersion: fields[2], NewVersion: fields[4]})
+		}
+	}
+
+	return plan
+}
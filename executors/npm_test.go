@@ -19,6 +19,15 @@ func TestNpmPackageFromString(t *testing.T) {
 				NewVersion: "0.31.0",
 			},
 		},
+		{
+			input: "@angular/cli   17.3.0  17.3.8  18.0.1  node_modules/@angular/cli  global",
+			want: &PackageInfo{
+				Name:       "@angular/cli",
+				OldVersion: "17.3.0",
+				NewVersion: "17.3.8",
+				Group:      "@angular",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -35,3 +44,128 @@ func TestNpmPackageFromStringErr(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, got)
 }
+
+func TestNpmDetailFromJSON(t *testing.T) {
+	tests := []struct {
+		input string
+		want  *PackageDetail
+	}{
+		{
+			input: `{
+  "description": "Zero-runtime-dependency package acting as bridge between Node projects and their package managers",
+  "homepage": "https://github.com/nodejs/corepack#readme",
+  "license": "MIT",
+  "repository": {"type": "git", "url": "git+https://github.com/nodejs/corepack.git"},
+  "dist": {"unpackedSize": 2047810}
+}`,
+			want: &PackageDetail{
+				Description:   "Zero-runtime-dependency package acting as bridge between Node projects and their package managers",
+				InstalledSize: 2047810,
+				Homepage:      "https://github.com/nodejs/corepack#readme",
+				License:       "MIT",
+				Repository:    "https://github.com/nodejs/corepack",
+			},
+		},
+		{
+			input: `{"description": "old style", "license": {"type": "ISC"}, "repository": "github:npm/cli"}`,
+			want: &PackageDetail{
+				Description: "old style",
+				License:     "ISC",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			got, err := npmDetailFromJSON([]byte(tt.input))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNpmAuditFromJSON(t *testing.T) {
+	t.Run("vulnerabilities", func(t *testing.T) {
+		input := `{
+  "auditReportVersion": 2,
+  "vulnerabilities": {
+    "semver": {"name": "semver", "severity": "high", "isDirect": false, "via": [], "effects": ["npm-check-updates"], "range": "7.0.0 - 7.5.1", "nodes": [], "fixAvailable": true},
+    "tar": {"name": "tar", "severity": "moderate", "isDirect": true, "via": [], "effects": [], "range": "<6.2.1", "nodes": [], "fixAvailable": {"name": "tar", "version": "6.2.1"}}
+  }
+}`
+
+		got, err := npmAuditFromJSON([]byte(input))
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{
+			"semver": "7.0.0 - 7.5.1",
+			"tar":    "<6.2.1",
+		}, got)
+	})
+
+	t.Run("global packages not supported", func(t *testing.T) {
+		input := "{\"error\": {\"code\": \"EAUDITGLOBAL\", \"summary\": \"`npm audit` does not support testing globals\", \"detail\": \"\"}}"
+
+		_, err := npmAuditFromJSON([]byte(input))
+		assert.EqualError(t, err, "EAUDITGLOBAL: `npm audit` does not support testing globals")
+	})
+}
+
+func TestNpmRangeContains(t *testing.T) {
+	tests := []struct {
+		r       string
+		version string
+		want    bool
+	}{
+		{r: "<6.2.1", version: "6.1.0", want: true},
+		{r: "<6.2.1", version: "6.2.1", want: false},
+		{r: "7.0.0 - 7.5.1", version: "7.5.1", want: true},
+		{r: "7.0.0 - 7.5.1", version: "7.5.2", want: false},
+		{r: ">=1.0.0 <1.0.5 || >=2.0.0 <2.0.3", version: "2.0.1", want: true},
+		{r: ">=1.0.0 <1.0.5 || >=2.0.0 <2.0.3", version: "1.0.5", want: false},
+		{r: "1.2.3", version: "1.2.3", want: true},
+		{r: "*", version: "9.9.9", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.r+" "+tt.version, func(t *testing.T) {
+			assert.Equal(t, tt.want, npmRangeContains(tt.r, tt.version))
+		})
+	}
+}
+
+func TestNpmInstalledFromJSON(t *testing.T) {
+	got, err := npmInstalledFromJSON([]byte(`{
+  "name": "lib",
+  "dependencies": {
+    "npm": {"version": "10.8.2", "overridden": false},
+    "corepack": {"version": "0.29.4", "overridden": false}
+  }
+}`))
+	assert.Nil(t, err)
+	assert.Equal(t, []*PackageInfo{
+		{Name: "corepack", OldVersion: "0.29.4"},
+		{Name: "npm", OldVersion: "10.8.2"},
+	}, got)
+}
+
+func TestNpmPlanFromString(t *testing.T) {
+	input := `add @types/node 22.13.1
+change corepack 0.29.4 => 0.31.0
+remove left-pad 1.3.0
+
+changed 1 package in 2s
+`
+
+	got := npmPlanFromString(input)
+	assert.Equal(t, &UpdatePlan{
+		Upgrades: []PlanEntry{
+			{Name: "corepack", OldVersion: "0.29.4", NewVersion: "0.31.0"},
+		},
+		Installs: []PlanEntry{
+			{Name: "@types/node", NewVersion: "22.13.1"},
+		},
+		Removals: []PlanEntry{
+			{Name: "left-pad", OldVersion: "1.3.0"},
+		},
+	}, got)
+}
@@ -0,0 +1,93 @@
+package executors
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	// aptProgressPatterns match the stages of a package in the output of apt install, and of its dry run
+	aptProgressPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^Get:\d+ \S+ \S+ \S+ (\S+) `),
+		regexp.MustCompile(`^(?:Unpacking|Inst) (\S+) `),
+		regexp.MustCompile(`^(?:Setting up|Conf) (\S+) `),
+	}
+	// homebrewProgressPatterns match the stages of a formula in the output of brew upgrade
+	homebrewProgressPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^==> Upgrading (\S+)`),
+		regexp.MustCompile(`^==> Pouring (.+?)--`),
+		regexp.MustCompile(`^🍺\s+\S*/Cellar/([^/]+)/`),
+	}
+)
+
+// stageProgress tracks the packages of an update through the stages read from the output of the package manager
+type stageProgress struct {
+	mu       sync.Mutex
+	patterns []*regexp.Regexp
+	// reached is the number of stages each package of the update has gone through
+	reached map[string]int
+}
+
+func newStageProgress(patterns []*regexp.Regexp, pkgs []string) *stageProgress {
+	reached := make(map[string]int, len(pkgs))
+	for _, pkg := range pkgs {
+		reached[pkg] = 0
+	}
+	return &stageProgress{patterns: patterns, reached: reached}
+}
+
+// read moves the package of the output line to its stage. Lines of other packages, e.g. dependencies, are ignored.
+func (p *stageProgress) read(line string) {
+	for i, pattern := range p.patterns {
+		matches := pattern.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		// apt suffixes the packages with the architecture, e.g. curl:amd64
+		pkg, _, _ := strings.Cut(matches[1], ":")
+		p.mu.Lock()
+		if stage, ok := p.reached[pkg]; ok && stage < i+1 {
+			p.reached[pkg] = i + 1
+		}
+		p.mu.Unlock()
+		return
+	}
+}
+
+// fraction returns the completed fraction of the update of the package, false when it is not in the update
+func (p *stageProgress) fraction(pkg string) (float64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stage, ok := p.reached[pkg]
+	if !ok {
+		return 0, false
+	}
+	return float64(stage) / float64(len(p.patterns)), true
+}
+
+// progressTracker holds the progress of the running update of an executor
+type progressTracker struct {
+	mu      sync.Mutex
+	running *stageProgress
+}
+
+func (t *progressTracker) start(p *stageProgress) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.running = p
+}
+
+func (t *progressTracker) stop() {
+	t.start(nil)
+}
+
+func (t *progressTracker) progress(pkg string) (float64, bool) {
+	t.mu.Lock()
+	p := t.running
+	t.mu.Unlock()
+	if p == nil {
+		return 0, false
+	}
+	return p.fraction(pkg)
+}
@@ -0,0 +1,92 @@
+package executors
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStageProgress(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []*regexp.Regexp
+		pkgs     []string
+		output   []string
+		want     map[string]float64
+	}{
+		{
+			name:     "apt",
+			patterns: aptProgressPatterns,
+			pkgs:     []string{"curl", "libcurl4", "git"},
+			output: []string{
+				"Get:1 http://archive.ubuntu.com/ubuntu jammy-updates/main amd64 curl amd64 7.81.0-1ubuntu1.16 [194 kB]",
+				"Get:2 http://archive.ubuntu.com/ubuntu jammy-updates/main amd64 libcurl4 amd64 7.81.0-1ubuntu1.16 [290 kB]",
+				"Preparing to unpack .../curl_7.81.0-1ubuntu1.16_amd64.deb ...",
+				"Unpacking curl (7.81.0-1ubuntu1.16) over (7.81.0-1ubuntu1.15) ...",
+				"Unpacking libcurl4:amd64 (7.81.0-1ubuntu1.16) over (7.81.0-1ubuntu1.15) ...",
+				"Setting up libcurl4:amd64 (7.81.0-1ubuntu1.16) ...",
+				// a dependency which is not in the update
+				"Setting up libssl3:amd64 (3.0.2-0ubuntu1.15) ...",
+			},
+			want: map[string]float64{"curl": 2.0 / 3, "libcurl4": 1, "git": 0},
+		},
+		{
+			name:     "apt dry run",
+			patterns: aptProgressPatterns,
+			pkgs:     []string{"curl"},
+			output: []string{
+				"Inst curl [7.81.0-1ubuntu1.15] (7.81.0-1ubuntu1.16 Ubuntu:22.04/jammy-updates [amd64])",
+				"Conf curl (7.81.0-1ubuntu1.16 Ubuntu:22.04/jammy-updates [amd64])",
+			},
+			want: map[string]float64{"curl": 1},
+		},
+		{
+			name:     "homebrew",
+			patterns: homebrewProgressPatterns,
+			pkgs:     []string{"wget", "node"},
+			output: []string{
+				"==> Upgrading 2 outdated packages:",
+				"wget 1.21.1 -> 1.21.4",
+				"==> Upgrading wget",
+				"==> Pouring wget--1.21.4.arm64_ventura.bottle.tar.gz",
+				"🍺  /opt/homebrew/Cellar/wget/1.21.4: 91 files, 4.4MB",
+				"==> Upgrading node",
+			},
+			want: map[string]float64{"wget": 1, "node": 1.0 / 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newStageProgress(tt.patterns, tt.pkgs)
+			for _, line := range tt.output {
+				p.read(line)
+			}
+			for pkg, want := range tt.want {
+				got, ok := p.fraction(pkg)
+				assert.True(t, ok, pkg)
+				assert.InDelta(t, want, got, 1e-9, pkg)
+			}
+			_, ok := p.fraction("vim")
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestProgressTracker(t *testing.T) {
+	var tracker progressTracker
+	_, ok := tracker.progress("curl")
+	assert.False(t, ok)
+
+	p := newStageProgress(aptProgressPatterns, []string{"curl"})
+	tracker.start(p)
+	p.read("Unpacking curl (7.81.0-1ubuntu1.16) over (7.81.0-1ubuntu1.15) ...")
+	got, ok := tracker.progress("curl")
+	assert.True(t, ok)
+	assert.InDelta(t, 2.0/3, got, 1e-9)
+
+	tracker.stop()
+	_, ok = tracker.progress("curl")
+	assert.False(t, ok)
+}
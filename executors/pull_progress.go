@@ -0,0 +1,116 @@
+package executors
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"sync"
+)
+
+// PullProgress tracks the layers of an image being pulled
+type PullProgress struct {
+	mu     sync.Mutex
+	layers map[string]*layerProgress
+	// order keeps the layers in the order they were reported
+	order []string
+}
+
+type layerProgress struct {
+	current, total int64
+	done           bool
+}
+
+func newPullProgress() *PullProgress {
+	return &PullProgress{layers: map[string]*layerProgress{}}
+}
+
+func (p *PullProgress) layer(id string) *layerProgress {
+	l, ok := p.layers[id]
+	if !ok {
+		l = &layerProgress{}
+		p.layers[id] = l
+		p.order = append(p.order, id)
+	}
+	return l
+}
+
+// Fraction returns the completed fraction of the layers, false until the size of a layer is known
+func (p *PullProgress) Fraction() (float64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var current, total int64
+	known := false
+	for _, id := range p.order {
+		l := p.layers[id]
+		if l.total > 0 {
+			known = true
+			total += l.total
+			if l.done {
+				current += l.total
+			} else {
+				current += min(l.current, l.total)
+			}
+		}
+	}
+	if !known {
+		return 0, false
+	}
+	return float64(current) / float64(total), true
+}
+
+// pullMessage is a message of the JSON stream returned by the image pull API
+type pullMessage struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	ProgressDetail struct {
+		Current int64 `json:"current"`
+		Total   int64 `json:"total"`
+	} `json:"progressDetail"`
+	ErrorDetail *struct {
+		Message string `json:"message"`
+	} `json:"errorDetail"`
+	Error string `json:"error"`
+}
+
+// decodePullStream reads the pull API stream into the progress and logs the messages which are not progress updates.
+// It returns the error reported in the stream as the API responds with 200 before pulling.
+func decodePullStream(r io.Reader, ref string, p *PullProgress) error {
+	dec := json.NewDecoder(r)
+	for {
+		var msg pullMessage
+		err := dec.Decode(&msg)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if msg.ErrorDetail != nil {
+			return errors.New(msg.ErrorDetail.Message)
+		}
+		if msg.Error != "" {
+			return errors.New(msg.Error)
+		}
+
+		// e.g. {"status":"Pulling from library/redis","id":"7"}
+		if msg.ID == "" || strings.HasPrefix(msg.Status, "Pulling from") {
+			log.Printf("%s: %s", ref, msg.Status)
+			continue
+		}
+
+		p.mu.Lock()
+		l := p.layer(msg.ID)
+		switch msg.Status {
+		case "Downloading":
+			l.current = msg.ProgressDetail.Current
+			l.total = msg.ProgressDetail.Total
+		case "Download complete", "Pull complete", "Already exists":
+			l.done = true
+		}
+		p.mu.Unlock()
+	}
+}
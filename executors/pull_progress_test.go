@@ -0,0 +1,71 @@
+package executors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodePullStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		stream    string
+		want      float64
+		wantKnown bool
+		wantErr   string
+	}{
+		{
+			name: "downloading layers",
+			stream: `{"status":"Pulling from library/redis","id":"7"}
+{"status":"Already exists","progressDetail":{},"id":"aaa"}
+{"status":"Pulling fs layer","progressDetail":{},"id":"bbb"}
+{"status":"Pulling fs layer","progressDetail":{},"id":"ccc"}
+{"status":"Downloading","progressDetail":{"current":100,"total":100},"id":"bbb"}
+{"status":"Download complete","progressDetail":{},"id":"bbb"}
+{"status":"Downloading","progressDetail":{"current":50,"total":300},"id":"ccc"}
+`,
+			want:      0.375,
+			wantKnown: true,
+		},
+		{
+			name: "pulled",
+			stream: `{"status":"Pulling from library/redis","id":"7"}
+{"status":"Downloading","progressDetail":{"current":10,"total":100},"id":"bbb"}
+{"status":"Pull complete","progressDetail":{},"id":"bbb"}
+{"status":"Digest: sha256:1234"}
+{"status":"Status: Downloaded newer image for redis:7"}
+`,
+			want:      1,
+			wantKnown: true,
+		},
+		{
+			name: "up to date",
+			stream: `{"status":"Pulling from library/redis","id":"7"}
+{"status":"Status: Image is up to date for redis:7"}
+`,
+		},
+		{
+			name: "error in the stream",
+			stream: `{"status":"Pulling from library/redis","id":"7"}
+{"errorDetail":{"message":"toomanyrequests: You have reached your pull rate limit."},"error":"toomanyrequests: You have reached your pull rate limit."}
+`,
+			wantErr: "toomanyrequests: You have reached your pull rate limit.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPullProgress()
+			err := decodePullStream(strings.NewReader(tt.stream), "redis:7", p)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			got, known := p.Fraction()
+			assert.Equal(t, tt.wantKnown, known)
+			assert.InDelta(t, tt.want, got, 0.001)
+		})
+	}
+}
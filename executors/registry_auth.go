@@ -0,0 +1,101 @@
+package executors
+
+import (
+	"log"
+	"os"
+
+	"github.com/docker/docker/api/types/registry"
+	"github.com/regclient/regclient"
+	regconfig "github.com/regclient/regclient/config"
+	"github.com/regclient/regclient/types/ref"
+)
+
+// RegistryCredential is a registry login given in the config file.
+// It overrides the login of the same registry in ~/.docker/config.json.
+type RegistryCredential struct {
+	// Host is the registry such as ghcr.io or docker.io
+	Host     string `yaml:"host"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// PasswordEnv is the environment variable holding the password, which is preferred over Password
+	PasswordEnv string `yaml:"password_env"`
+}
+
+func (c RegistryCredential) password() string {
+	if c.PasswordEnv != "" {
+		return os.Getenv(c.PasswordEnv)
+	}
+	return c.Password
+}
+
+// registryHosts converts the credentials into regclient hosts
+func registryHosts(creds []RegistryCredential) []regconfig.Host {
+	var hosts []regconfig.Host
+	for _, c := range creds {
+		h := regconfig.HostNewName(c.Host)
+		h.User = c.Username
+		h.Pass = c.password()
+		hosts = append(hosts, *h)
+	}
+	return hosts
+}
+
+// newRegClient returns a client logging in with the credentials of ~/.docker/config.json,
+// including credential helpers, and the given ones
+func newRegClient(creds []RegistryCredential) *regclient.RegClient {
+	return regclient.New(regclient.WithDockerCreds(), regclient.WithConfigHost(registryHosts(creds)...))
+}
+
+// registryAuth resolves the logins of registries for pulls through the Docker API
+type registryAuth struct {
+	hosts map[string]regconfig.Host
+}
+
+func newRegistryAuth(creds []RegistryCredential) *registryAuth {
+	docker, err := regconfig.DockerLoad()
+	if err != nil {
+		log.Printf("Failed to load the docker credentials: %v", err)
+	}
+	return newRegistryAuthFromHosts(append(docker, registryHosts(creds)...))
+}
+
+// newRegistryAuthFromHosts indexes the hosts by name. Later hosts win.
+func newRegistryAuthFromHosts(hosts []regconfig.Host) *registryAuth {
+	a := &registryAuth{hosts: map[string]regconfig.Host{}}
+	for _, h := range hosts {
+		a.hosts[h.Name] = h
+	}
+	return a
+}
+
+// Encoded returns the X-Registry-Auth header value for the registry of the image,
+// or an empty string to pull anonymously
+func (a *registryAuth) Encoded(img string) string {
+	if a == nil {
+		return ""
+	}
+	r, err := ref.New(img)
+	if err != nil {
+		return ""
+	}
+	h, ok := a.hosts[r.Registry]
+	if !ok {
+		return ""
+	}
+	cred := h.GetCred()
+	if cred.User == "" && cred.Password == "" && cred.Token == "" {
+		return ""
+	}
+
+	auth, err := registry.EncodeAuthConfig(registry.AuthConfig{
+		Username:      cred.User,
+		Password:      cred.Password,
+		IdentityToken: cred.Token,
+		ServerAddress: r.Registry,
+	})
+	if err != nil {
+		log.Printf("Failed to encode the credentials of %s: %v", r.Registry, err)
+		return ""
+	}
+	return auth
+}
@@ -0,0 +1,101 @@
+package executors
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/regclient/regclient/config"
+	"github.com/regclient/regclient/types/errs"
+	"github.com/regclient/regclient/types/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryHosts(t *testing.T) {
+	t.Setenv("LAZYPKG_TEST_PASSWORD", "from-env")
+
+	hosts := registryHosts([]RegistryCredential{
+		{Host: "ghcr.io", Username: "octocat", Password: "secret"},
+		{Host: "https://index.docker.io/v1/", Username: "whale", Password: "ignored", PasswordEnv: "LAZYPKG_TEST_PASSWORD"},
+	})
+
+	require.Len(t, hosts, 2)
+	assert.Equal(t, "ghcr.io", hosts[0].Name)
+	assert.Equal(t, "octocat", hosts[0].User)
+	assert.Equal(t, "secret", hosts[0].Pass)
+	assert.Equal(t, "docker.io", hosts[1].Name)
+	assert.Equal(t, "whale", hosts[1].User)
+	assert.Equal(t, "from-env", hosts[1].Pass)
+}
+
+func TestRegistryAuthEncoded(t *testing.T) {
+	auth := newRegistryAuthFromHosts([]config.Host{
+		{Name: "docker.io", User: "old", Pass: "old"},
+		{Name: "ghcr.io", Token: "token"},
+		{Name: "docker.io", User: "whale", Pass: "secret"},
+	})
+
+	tests := []struct {
+		name string
+		img  string
+		want map[string]string
+	}{
+		{
+			name: "docker hub with the later login",
+			img:  "redis:7",
+			want: map[string]string{"username": "whale", "password": "secret", "serveraddress": "docker.io"},
+		},
+		{
+			name: "identity token",
+			img:  "ghcr.io/myorg/app:1.0",
+			want: map[string]string{"identitytoken": "token", "serveraddress": "ghcr.io"},
+		},
+		{
+			name: "unknown registry",
+			img:  "quay.io/app:1.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := auth.Encoded(tt.img)
+			if tt.want == nil {
+				assert.Empty(t, encoded)
+				return
+			}
+			b, err := base64.URLEncoding.DecodeString(encoded)
+			require.NoError(t, err)
+			got := map[string]string{}
+			require.NoError(t, json.Unmarshal(b, &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	assert.Empty(t, (*registryAuth)(nil).Encoded("redis:7"))
+}
+
+func TestDockerCheckTagWithRegistryAuth(t *testing.T) {
+	reg := newTestRegistry(t)
+	reg.RequireAuth("octocat", "secret")
+	_, cfg := reg.PushImage("myorg/app", "1.0", `{"architecture":"amd64","os":"linux"}`)
+	digest, _ := reg.PushImage("myorg/app", "1.0", `{"architecture":"amd64","os":"linux","created":"2"}`)
+	tag := reg.Host() + "/myorg/app:1.0"
+	local := dockerLocalImage{ID: cfg, RepoDigests: []string{digest}}
+	amd64 := platform.Platform{OS: "linux", Architecture: "amd64"}
+
+	t.Run("without credentials", func(t *testing.T) {
+		de := &DockerExecutor{rc: reg.Client()}
+		_, err := de.checkTag(context.Background(), tag, local, amd64)
+		assert.ErrorIs(t, err, errs.ErrHTTPUnauthorized)
+	})
+
+	t.Run("with credentials of the config file", func(t *testing.T) {
+		hosts := registryHosts([]RegistryCredential{{Host: reg.Host(), Username: "octocat", Password: "secret"}})
+		de := &DockerExecutor{rc: reg.ClientWithHost(hosts[0])}
+		pkg, err := de.checkTag(context.Background(), tag, local, amd64)
+		require.NoError(t, err)
+		assert.Nil(t, pkg)
+	})
+}
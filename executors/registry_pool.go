@@ -0,0 +1,82 @@
+package executors
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/regclient/regclient/types/errs"
+)
+
+// defaultParallelism is the number of images checked or pulled at the same time when not configured
+const defaultParallelism = 4
+
+// retryBackoff is the wait before each retry of a rate limited or failed registry request
+var retryBackoff = []time.Duration{5 * time.Second, 15 * time.Second, 45 * time.Second}
+
+// runPool calls fn for 0..count-1 with at most n calls running at the same time
+func runPool(n, count int, fn func(i int)) {
+	if n < 1 {
+		n = 1
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(n, count) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	for i := range count {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// withRetry calls fn again after a backoff while it fails with a rate limit or a transient error
+func withRetry(ctx context.Context, name string, fn func() error) error {
+	err := fn()
+	for _, wait := range retryBackoff {
+		if err == nil || !retryable(err) {
+			return err
+		}
+		log.Printf("Retrying %s in %s: %v", name, wait, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		err = fn()
+	}
+	return err
+}
+
+// retryable reports whether the error is a rate limit, e.g. the pull limit of Docker Hub, or a network failure
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, errs.ErrHTTPRateLimit) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	// the daemon reports the registry errors of pulls as text
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "toomanyrequests") || strings.Contains(msg, "too many requests")
+}
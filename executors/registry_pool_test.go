@@ -0,0 +1,130 @@
+package executors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/regclient/regclient/types/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunPool(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		running int
+		peak    int
+	)
+	done := make([]bool, 10)
+
+	runPool(3, len(done), func(i int) {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+		done[i] = true
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+	})
+
+	assert.Equal(t, 3, peak)
+	for i, d := range done {
+		assert.True(t, d, "job %d", i)
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	backoff := retryBackoff
+	retryBackoff = []time.Duration{time.Millisecond, time.Millisecond}
+	t.Cleanup(func() { retryBackoff = backoff })
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "succeeds after a rate limit",
+			errs:      []error{fmt.Errorf("%w [http 429]", errs.ErrHTTPRateLimit), nil},
+			wantCalls: 2,
+		},
+		{
+			name:      "gives up after the backoffs",
+			errs:      []error{io.ErrUnexpectedEOF, io.ErrUnexpectedEOF, io.ErrUnexpectedEOF, nil},
+			wantCalls: 3,
+			wantErr:   true,
+		},
+		{
+			name:      "does not retry an authentication failure",
+			errs:      []error{errs.ErrHTTPUnauthorized, nil},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := withRetry(context.Background(), "redis:7", func() error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+			assert.Equal(t, tt.wantCalls, calls)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "registry rate limit",
+			err:  fmt.Errorf("failed to get manifest: %w", errs.ErrHTTPRateLimit),
+			want: true,
+		},
+		{
+			name: "docker hub pull limit reported by the daemon",
+			err:  errors.New("toomanyrequests: You have reached your pull rate limit."),
+			want: true,
+		},
+		{
+			name: "connection closed",
+			err:  io.ErrUnexpectedEOF,
+			want: true,
+		},
+		{
+			name: "canceled",
+			err:  context.Canceled,
+			want: false,
+		},
+		{
+			name: "unknown manifest",
+			err:  errs.ErrNotFound,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, retryable(tt.err))
+		})
+	}
+}
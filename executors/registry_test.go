@@ -0,0 +1,179 @@
+package executors
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/regclient/regclient"
+	"github.com/regclient/regclient/config"
+	"github.com/regclient/regclient/types/platform"
+)
+
+const (
+	ociManifestType = "application/vnd.oci.image.manifest.v1+json"
+	ociIndexType    = "application/vnd.oci.image.index.v1+json"
+	ociConfigType   = "application/vnd.oci.image.config.v1+json"
+)
+
+type testManifest struct {
+	mediaType string
+	content   []byte
+}
+
+// testRegistry is an in-memory registry serving manifests and tag lists of the distribution API
+type testRegistry struct {
+	*httptest.Server
+
+	mu sync.Mutex
+	// manifests are indexed by repository and then by tag or digest
+	manifests map[string]map[string]testManifest
+	tags      map[string][]string
+	// user and pass are the basic auth login required when user is set
+	user, pass string
+}
+
+func newTestRegistry(t *testing.T) *testRegistry {
+	r := &testRegistry{
+		manifests: map[string]map[string]testManifest{},
+		tags:      map[string][]string{},
+	}
+	r.Server = httptest.NewServer(http.HandlerFunc(r.serve))
+	t.Cleanup(r.Close)
+
+	return r
+}
+
+// Host returns the registry host such as 127.0.0.1:12345
+func (r *testRegistry) Host() string {
+	return strings.TrimPrefix(r.URL, "http://")
+}
+
+// Client returns a regclient talking to the registry over plain HTTP
+func (r *testRegistry) Client() *regclient.RegClient {
+	return r.ClientWithHost(config.Host{Name: r.Host()})
+}
+
+// ClientWithHost returns a regclient talking to the registry over plain HTTP with the settings of h
+func (r *testRegistry) ClientWithHost(h config.Host) *regclient.RegClient {
+	h.TLS = config.TLSDisabled
+	return regclient.New(regclient.WithConfigHost(h))
+}
+
+// RequireAuth makes the registry refuse requests without the basic auth login
+func (r *testRegistry) RequireAuth(user, pass string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.user, r.pass = user, pass
+}
+
+// PushImage stores an image manifest whose config is the given content and returns the manifest and config digests
+func (r *testRegistry) PushImage(repo, tag, cfg string) (string, string) {
+	configDigest := digestOf([]byte(cfg))
+	content, _ := json.Marshal(map[string]any{
+		"schemaVersion": 2,
+		"mediaType":     ociManifestType,
+		"config": map[string]any{
+			"mediaType": ociConfigType,
+			"digest":    configDigest,
+			"size":      len(cfg),
+		},
+		"layers": []any{},
+	})
+
+	return r.put(repo, tag, testManifest{ociManifestType, content}), configDigest
+}
+
+// testPlatformImage is an image of a multi-arch index
+type testPlatformImage struct {
+	platform platform.Platform
+	config   string
+}
+
+// PushIndex stores a multi-arch index referencing the images of each platform and returns its digest
+func (r *testRegistry) PushIndex(repo, tag string, images ...testPlatformImage) string {
+	var manifests []map[string]any
+	for _, img := range images {
+		digest, _ := r.PushImage(repo, "", img.config)
+		m := r.manifests[repo][digest]
+		manifests = append(manifests, map[string]any{
+			"mediaType": ociManifestType,
+			"digest":    digest,
+			"size":      len(m.content),
+			"platform":  img.platform,
+		})
+	}
+	content, _ := json.Marshal(map[string]any{
+		"schemaVersion": 2,
+		"mediaType":     ociIndexType,
+		"manifests":     manifests,
+	})
+
+	return r.put(repo, tag, testManifest{ociIndexType, content})
+}
+
+func (r *testRegistry) put(repo, tag string, m testManifest) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	digest := digestOf(m.content)
+	if _, ok := r.manifests[repo]; !ok {
+		r.manifests[repo] = map[string]testManifest{}
+	}
+	r.manifests[repo][digest] = m
+	if tag != "" {
+		if _, ok := r.manifests[repo][tag]; !ok {
+			r.tags[repo] = append(r.tags[repo], tag)
+		}
+		r.manifests[repo][tag] = m
+	}
+
+	return digest
+}
+
+func (r *testRegistry) serve(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.user != "" {
+		if user, pass, ok := req.BasicAuth(); !ok || user != r.user || pass != r.pass {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			http.Error(w, `{"errors":[{"code":"UNAUTHORIZED"}]}`, http.StatusUnauthorized)
+			return
+		}
+	}
+
+	p := strings.TrimPrefix(req.URL.Path, "/v2/")
+	switch {
+	case p == "":
+		w.WriteHeader(http.StatusOK)
+	case strings.HasSuffix(p, "/tags/list"):
+		repo := strings.TrimSuffix(p, "/tags/list")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"name": repo, "tags": r.tags[repo]})
+	case strings.Contains(p, "/manifests/"):
+		repo, reference, _ := strings.Cut(p, "/manifests/")
+		m, ok := r.manifests[repo][reference]
+		if !ok {
+			http.Error(w, `{"errors":[{"code":"MANIFEST_UNKNOWN"}]}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", m.mediaType)
+		w.Header().Set("Content-Length", fmt.Sprint(len(m.content)))
+		w.Header().Set("Docker-Content-Digest", digestOf(m.content))
+		if req.Method != http.MethodHead {
+			_, _ = w.Write(m.content)
+		}
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func digestOf(b []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(b))
+}
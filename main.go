@@ -1,8 +1,10 @@
 package main
 
 import (
+	"bufio"
 	"log"
 	"os"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
@@ -17,6 +19,12 @@ func main() {
 		excludes       []string
 		enableFeatures []string
 		demo           bool
+		osvDB          string
+		configPath     string
+		passwordStdin  bool
+		theme          string
+		noIcons        bool
+		logFile        string
 	)
 
 	rootCmd := &cobra.Command{
@@ -24,19 +32,70 @@ func main() {
 		Short:   "A TUI package management application across package managers",
 		Version: version,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			m, err := components.NewAppModel(components.NewConfig(dryRun, excludes, enableFeatures, demo))
+			config := components.NewConfig(dryRun, excludes, enableFeatures, demo, osvDB)
+			config.Theme = theme
+			config.NoIcons = noIcons
+			config.LogFile = logFile
+			if err := config.LoadFile(configPath); err != nil {
+				return err
+			}
+			m, err := components.NewAppModel(config)
 			if err != nil {
 				return err
 			}
 			defer m.Close()
-			_, err = tea.NewProgram(m, tea.WithAltScreen()).Run()
+			_, err = tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion()).Run()
 			return err
 		},
 	}
 
+	scanCmd := &cobra.Command{
+		Use:   "scan",
+		Short: "Check every installed package against an offline OSV database",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return components.ScanPackages(components.NewConfig(false, excludes, nil, false, osvDB), os.Stdout)
+		},
+	}
+	scanCmd.Flags().StringArrayVar(&excludes, "exclude", []string{}, "Package manager name to be excluded from the scan")
+	rootCmd.AddCommand(scanCmd)
+
+	planCmd := &cobra.Command{
+		Use:   "plan [name]",
+		Short: "Run a saved update plan, or list the saved plans without a name",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// a failed plan is not a usage error
+			cmd.SilenceUsage = true
+			config := components.NewConfig(dryRun, nil, nil, false, osvDB)
+			if err := config.LoadFile(configPath); err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				return components.ListPlans(config, os.Stdout)
+			}
+			var password string
+			if passwordStdin {
+				line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+				if err != nil && line == "" {
+					return err
+				}
+				password = strings.TrimRight(line, "\r\n")
+			}
+			return components.RunPlan(config, args[0], password, os.Stdout)
+		},
+	}
+	planCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Perform update commands with --dry-run option")
+	planCmd.Flags().BoolVar(&passwordStdin, "password-stdin", false, "Read the sudo password from stdin")
+	rootCmd.AddCommand(planCmd)
+
 	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Perform update commands with --dry-run option")
 	rootCmd.Flags().StringArrayVar(&excludes, "exclude", []string{}, "Package manager name to be excluded in lazypkg")
-	rootCmd.Flags().StringArrayVar(&enableFeatures, "enable-feature", []string{}, "Optional feature name to be enabled in lazypkg [docker]")
+	rootCmd.Flags().StringArrayVar(&enableFeatures, "enable-feature", []string{}, "Optional feature name to be enabled in lazypkg [docker, podman, nerdctl, compose]")
+	rootCmd.PersistentFlags().StringVar(&osvDB, "osv-db", "", "Path to an OSV advisory export (zip or directory) used to detect security updates")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", components.DefaultConfigPath(), "Path to the config file")
+	rootCmd.Flags().StringVar(&theme, "theme", "", "Color theme [dark, light, high-contrast] or a theme of the config file")
+	rootCmd.Flags().BoolVar(&noIcons, "no-icons", false, "Show ASCII labels instead of the Nerd Font icons")
+	rootCmd.Flags().StringVar(&logFile, "log-file", "", "Append every log to the file")
 	rootCmd.Flags().BoolVar(&demo, "demo", false, "")
 
 	if err := rootCmd.Flags().MarkHidden("demo"); err != nil {
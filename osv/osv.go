@@ -0,0 +1,381 @@
+// Package osv reads advisories exported in the Open Source Vulnerability format
+// (https://ossf.github.io/osv-schema/) and matches them against package versions.
+package osv
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	EcosystemNPM      = "npm"
+	EcosystemRubyGems = "RubyGems"
+	EcosystemDebian   = "Debian"
+	EcosystemUbuntu   = "Ubuntu"
+)
+
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+}
+
+type Range struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+type Package struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+}
+
+type Affected struct {
+	Package  Package  `json:"package"`
+	Ranges   []Range  `json:"ranges"`
+	Versions []string `json:"versions"`
+}
+
+type Severity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+// Vulnerability is a single OSV advisory
+type Vulnerability struct {
+	ID               string     `json:"id"`
+	Aliases          []string   `json:"aliases"`
+	Summary          string     `json:"summary"`
+	Severity         []Severity `json:"severity"`
+	Affected         []Affected `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+// Database is an in-memory index of advisories by ecosystem and package name
+type Database struct {
+	index map[string]map[string][]*Vulnerability
+	// ecosystems are the ecosystems of the advisories along with their release, e.g. "Debian:12"
+	ecosystems map[string]bool
+	count      int
+}
+
+// Load reads advisories from path, which is either a zip archive as published
+// by OSV (e.g. npm/all.zip) or a directory containing JSON files and zip archives.
+func Load(path string) (*Database, error) {
+	db := &Database{
+		index:      map[string]map[string][]*Vulnerability{},
+		ecosystems: map[string]bool{},
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		if err := db.loadFile(path); err != nil {
+			return nil, err
+		}
+		return db, nil
+	}
+
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		return db.loadFile(p)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Len returns the number of advisories in the database
+func (db *Database) Len() int {
+	return db.count
+}
+
+// Ecosystem returns the ecosystem the advisories are matched on for the host ecosystem, e.g. "Ubuntu:24.04".
+// It is the base ecosystem when the database has no advisory for the release,
+// so that the advisories of every release apply rather than none.
+func (db *Database) Ecosystem(ecosystem string) string {
+	if ecosystem == baseEcosystem(ecosystem) {
+		return ecosystem
+	}
+	for e := range db.ecosystems {
+		if sameRelease(e, ecosystem) {
+			return ecosystem
+		}
+	}
+
+	return baseEcosystem(ecosystem)
+}
+
+// Vulnerabilities returns the advisories affecting the version of the package.
+// An ecosystem with a release such as "Debian:12" only matches the advisories of the release,
+// see Ecosystem for the one to match on.
+func (db *Database) Vulnerabilities(ecosystem, name, version string) []*Vulnerability {
+	var result []*Vulnerability
+	for _, v := range db.index[baseEcosystem(ecosystem)][name] {
+		if v.Affects(ecosystem, name, version) {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+// Affects reports whether the advisory applies to the version of the package
+func (v *Vulnerability) Affects(ecosystem, name, version string) bool {
+	for _, a := range v.affected(ecosystem, name) {
+		for _, av := range a.Versions {
+			if av == version {
+				return true
+			}
+		}
+		for _, r := range a.Ranges {
+			if r.Type == "GIT" {
+				continue
+			}
+			if r.affects(ecosystem, version) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// FixedVersion returns the lowest version fixing the advisory which is newer than version.
+// It returns an empty string when no fix is known.
+func (v *Vulnerability) FixedVersion(ecosystem, name, version string) string {
+	var fixed []string
+	for _, a := range v.affected(ecosystem, name) {
+		for _, r := range a.Ranges {
+			for _, e := range r.Events {
+				if e.Fixed != "" && Compare(ecosystem, e.Fixed, version) > 0 {
+					fixed = append(fixed, e.Fixed)
+				}
+			}
+		}
+	}
+	if len(fixed) == 0 {
+		return ""
+	}
+	sort.Slice(fixed, func(i, j int) bool {
+		return Compare(ecosystem, fixed[i], fixed[j]) < 0
+	})
+
+	return fixed[0]
+}
+
+// CVEs returns the CVE identifiers of the advisory, or its own ID when it has none
+func (v *Vulnerability) CVEs() []string {
+	var ids []string
+	if strings.HasPrefix(v.ID, "CVE-") {
+		ids = append(ids, v.ID)
+	}
+	for _, a := range v.Aliases {
+		if strings.HasPrefix(a, "CVE-") {
+			ids = append(ids, a)
+		}
+	}
+	if len(ids) == 0 {
+		ids = append(ids, v.ID)
+	}
+
+	return ids
+}
+
+// SeverityLabel returns a human readable severity of the advisory
+func (v *Vulnerability) SeverityLabel() string {
+	if v.DatabaseSpecific.Severity != "" {
+		return strings.ToUpper(v.DatabaseSpecific.Severity)
+	}
+	for _, s := range v.Severity {
+		if s.Score != "" {
+			return s.Score
+		}
+	}
+
+	return "UNKNOWN"
+}
+
+func (v *Vulnerability) affected(ecosystem, name string) []Affected {
+	var result []Affected
+	for _, a := range v.Affected {
+		if a.Package.Name != name || baseEcosystem(a.Package.Ecosystem) != baseEcosystem(ecosystem) {
+			continue
+		}
+		if ecosystem == baseEcosystem(ecosystem) || sameRelease(a.Package.Ecosystem, ecosystem) {
+			result = append(result, a)
+		}
+	}
+
+	return result
+}
+
+// affects evaluates the events of the range in version order
+func (r Range) affects(ecosystem, version string) bool {
+	events := make([]Event, len(r.Events))
+	copy(events, r.Events)
+	sort.SliceStable(events, func(i, j int) bool {
+		return Compare(ecosystem, eventVersion(events[i]), eventVersion(events[j])) < 0
+	})
+
+	affected := false
+	for _, e := range events {
+		switch {
+		case e.Introduced != "":
+			if e.Introduced == "0" || Compare(ecosystem, version, e.Introduced) >= 0 {
+				affected = true
+			}
+		case e.Fixed != "":
+			if Compare(ecosystem, version, e.Fixed) >= 0 {
+				affected = false
+			}
+		case e.LastAffected != "":
+			if Compare(ecosystem, version, e.LastAffected) > 0 {
+				affected = false
+			}
+		}
+	}
+
+	return affected
+}
+
+func eventVersion(e Event) string {
+	switch {
+	case e.Introduced != "":
+		return e.Introduced
+	case e.Fixed != "":
+		return e.Fixed
+	default:
+		return e.LastAffected
+	}
+}
+
+func (db *Database) loadFile(path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".zip":
+		return db.loadZip(path)
+	case ".json":
+		f, err := os.Open(path) // #nosec G304: path is given by the user
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return db.add(f, path)
+	}
+
+	return nil
+}
+
+func (db *Database) loadZip(path string) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, zf := range zr.File {
+		if !strings.EqualFold(filepath.Ext(zf.Name), ".json") {
+			continue
+		}
+		f, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		err = db.add(f, filepath.Join(path, zf.Name))
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (db *Database) add(r io.Reader, source string) error {
+	var v Vulnerability
+	if err := json.NewDecoder(r).Decode(&v); err != nil {
+		return fmt.Errorf("failed to parse advisory %s: %w", source, err)
+	}
+
+	seen := map[string]bool{}
+	for _, a := range v.Affected {
+		db.ecosystems[a.Package.Ecosystem] = true
+		eco := baseEcosystem(a.Package.Ecosystem)
+		if seen[eco+"/"+a.Package.Name] {
+			continue
+		}
+		seen[eco+"/"+a.Package.Name] = true
+		if _, ok := db.index[eco]; !ok {
+			db.index[eco] = map[string][]*Vulnerability{}
+		}
+		db.index[eco][a.Package.Name] = append(db.index[eco][a.Package.Name], &v)
+	}
+	db.count++
+
+	return nil
+}
+
+// baseEcosystem strips the release suffix such as "Debian:12"
+func baseEcosystem(ecosystem string) string {
+	base, _, _ := strings.Cut(ecosystem, ":")
+	return base
+}
+
+// sameRelease reports whether the ecosystem of an advisory is the release of the host,
+// which OSV may qualify further, e.g. "Ubuntu:24.04:LTS" for "Ubuntu:24.04"
+func sameRelease(advisory, host string) bool {
+	return advisory == host || strings.HasPrefix(advisory, host+":")
+}
+
+// Finding is an advisory affecting an installed package
+type Finding struct {
+	Name          string
+	Version       string
+	Vulnerability *Vulnerability
+	// FixedVersion is the lowest version fixing the advisory, empty when no fix is known
+	FixedVersion string
+	// ProposedVersion is the version the package would be updated to, empty when up to date
+	ProposedVersion string
+}
+
+// Resolved reports whether updating to the proposed version fixes the advisory
+func (f Finding) Resolved(ecosystem string) bool {
+	if f.ProposedVersion == "" {
+		return false
+	}
+	return !f.Vulnerability.Affects(ecosystem, f.Name, f.ProposedVersion)
+}
+
+// Scan checks the installed version of the package and the version proposed to update to
+func (db *Database) Scan(ecosystem, name, version, proposed string) []Finding {
+	var findings []Finding
+	for _, v := range db.Vulnerabilities(ecosystem, name, version) {
+		findings = append(findings, Finding{
+			Name:            name,
+			Version:         version,
+			Vulnerability:   v,
+			FixedVersion:    v.FixedVersion(ecosystem, name, version),
+			ProposedVersion: proposed,
+		})
+	}
+
+	return findings
+}
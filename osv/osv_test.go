@@ -0,0 +1,132 @@
+package osv
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("directory", func(t *testing.T) {
+		db, err := Load("testdata")
+		require.Nil(t, err)
+		assert.Equal(t, 3, db.Len())
+	})
+
+	t.Run("zip archive", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "all.zip")
+		f, err := os.Create(path)
+		require.Nil(t, err)
+		zw := zip.NewWriter(f)
+		w, err := zw.Create("GHSA-c2qf-rxjj-qqgw.json")
+		require.Nil(t, err)
+		b, err := os.ReadFile("testdata/GHSA-c2qf-rxjj-qqgw.json")
+		require.Nil(t, err)
+		_, err = w.Write(b)
+		require.Nil(t, err)
+		require.Nil(t, zw.Close())
+		require.Nil(t, f.Close())
+
+		db, err := Load(path)
+		require.Nil(t, err)
+		assert.Equal(t, 1, db.Len())
+		assert.Len(t, db.Vulnerabilities(EcosystemNPM, "semver", "7.5.1"), 1)
+	})
+
+	t.Run("missing path", func(t *testing.T) {
+		_, err := Load("testdata/missing")
+		assert.Error(t, err)
+	})
+}
+
+func TestDatabaseVulnerabilities(t *testing.T) {
+	db, err := Load("testdata")
+	require.Nil(t, err)
+
+	tests := []struct {
+		ecosystem string
+		name      string
+		version   string
+		wantIDs   []string
+		wantFixed string
+	}{
+		{ecosystem: EcosystemNPM, name: "semver", version: "7.5.1", wantIDs: []string{"GHSA-c2qf-rxjj-qqgw"}, wantFixed: "7.5.2"},
+		{ecosystem: EcosystemNPM, name: "semver", version: "5.7.1", wantIDs: []string{"GHSA-c2qf-rxjj-qqgw"}, wantFixed: "5.7.2"},
+		{ecosystem: EcosystemNPM, name: "semver", version: "7.5.2"},
+		{ecosystem: EcosystemNPM, name: "semver", version: "6.3.1"},
+		{ecosystem: EcosystemRubyGems, name: "rexml", version: "3.3.2", wantIDs: []string{"GHSA-vvfq-8hwr-qm4m"}},
+		{ecosystem: EcosystemRubyGems, name: "rexml", version: "3.3.3"},
+		{ecosystem: EcosystemNPM, name: "rexml", version: "3.3.2"},
+		// the advisories of the release apply, with its fixed version
+		{ecosystem: "Ubuntu:24.04", name: "openssl", version: "3.0.13-0ubuntu3.1", wantIDs: []string{"UBUNTU-CVE-2024-5535"}, wantFixed: "3.0.13-0ubuntu3.2"},
+		{ecosystem: "Ubuntu:24.04", name: "openssl", version: "3.0.13-0ubuntu3.2"},
+		{ecosystem: "Ubuntu:22.04", name: "openssl", version: "3.0.2-0ubuntu1.16", wantIDs: []string{"UBUNTU-CVE-2024-5535"}, wantFixed: "3.0.2-0ubuntu1.17"},
+		// the fix of 22.04 is older than the version of 24.04 but does not apply to it
+		{ecosystem: "Ubuntu:20.04", name: "openssl", version: "1.1.1f-1ubuntu2.22"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ecosystem+"/"+tt.name+"@"+tt.version, func(t *testing.T) {
+			vulns := db.Vulnerabilities(tt.ecosystem, tt.name, tt.version)
+			var ids []string
+			for _, v := range vulns {
+				ids = append(ids, v.ID)
+				assert.Equal(t, tt.wantFixed, v.FixedVersion(tt.ecosystem, tt.name, tt.version))
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+func TestVulnerabilityCVEsAndSeverity(t *testing.T) {
+	db, err := Load("testdata")
+	require.Nil(t, err)
+
+	v := db.Vulnerabilities(EcosystemRubyGems, "rexml", "3.2.0")[0]
+	assert.Equal(t, []string{"CVE-2024-41123", "CVE-2024-41946"}, v.CVEs())
+	assert.Equal(t, "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:L", v.SeverityLabel())
+
+	v = db.Vulnerabilities(EcosystemNPM, "semver", "7.0.0")[0]
+	assert.Equal(t, []string{"CVE-2022-25883"}, v.CVEs())
+	assert.Equal(t, "HIGH", v.SeverityLabel())
+}
+
+func TestDatabaseScan(t *testing.T) {
+	db, err := Load("testdata")
+	require.Nil(t, err)
+
+	findings := db.Scan(EcosystemNPM, "semver", "7.5.1", "7.6.0")
+	require.Len(t, findings, 1)
+	assert.Equal(t, "7.5.2", findings[0].FixedVersion)
+	assert.True(t, findings[0].Resolved(EcosystemNPM))
+
+	findings = db.Scan(EcosystemNPM, "semver", "7.4.0", "7.5.0")
+	require.Len(t, findings, 1)
+	assert.False(t, findings[0].Resolved(EcosystemNPM))
+
+	findings = db.Scan(EcosystemRubyGems, "rexml", "3.3.2", "")
+	require.Len(t, findings, 1)
+	assert.Equal(t, "", findings[0].FixedVersion)
+	assert.False(t, findings[0].Resolved(EcosystemRubyGems))
+
+	assert.Empty(t, db.Scan(EcosystemNPM, "semver", "7.5.2", ""))
+}
+
+func TestDatabaseEcosystem(t *testing.T) {
+	db, err := Load("testdata")
+	require.Nil(t, err)
+
+	assert.Equal(t, "Ubuntu:24.04", db.Ecosystem("Ubuntu:24.04"))
+	// a release without advisories falls back to the advisories of every release
+	assert.Equal(t, EcosystemUbuntu, db.Ecosystem("Ubuntu:24.10"))
+	assert.Equal(t, EcosystemDebian, db.Ecosystem("Debian:12"))
+	assert.Equal(t, EcosystemNPM, db.Ecosystem(EcosystemNPM))
+
+	vulns := db.Vulnerabilities(db.Ecosystem("Ubuntu:24.10"), "openssl", "3.0.13-0ubuntu3.1")
+	require.Len(t, vulns, 1)
+	assert.Equal(t, "3.0.13-0ubuntu3.2", vulns[0].FixedVersion(EcosystemUbuntu, "openssl", "3.0.13-0ubuntu3.1"))
+}
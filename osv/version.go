@@ -0,0 +1,239 @@
+package osv
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Compare compares two versions following the rules of the ecosystem.
+// It returns -1 if a < b, 0 if a == b and 1 if a > b.
+func Compare(ecosystem, a, b string) int {
+	switch baseEcosystem(ecosystem) {
+	case EcosystemRubyGems:
+		return compareGem(a, b)
+	case EcosystemDebian, EcosystemUbuntu:
+		return compareDebian(a, b)
+	default:
+		return compareSemver(a, b)
+	}
+}
+
+// compareSemver compares semantic versions. A leading "v" is ignored and
+// missing minor and patch numbers are treated as zero.
+func compareSemver(a, b string) int {
+	a, b = strings.TrimPrefix(a, "v"), strings.TrimPrefix(b, "v")
+	a, _, _ = strings.Cut(a, "+")
+	b, _, _ = strings.Cut(b, "+")
+	ac, apre, _ := strings.Cut(a, "-")
+	bc, bpre, _ := strings.Cut(b, "-")
+
+	as, bs := strings.Split(ac, "."), strings.Split(bc, ".")
+	for i := 0; i < max(len(as), len(bs), 3); i++ {
+		if c := compareNumeric(segment(as, i), segment(bs, i)); c != 0 {
+			return c
+		}
+	}
+
+	// a version without pre-release has higher precedence
+	switch {
+	case apre == bpre:
+		return 0
+	case apre == "":
+		return 1
+	case bpre == "":
+		return -1
+	}
+
+	ap, bp := strings.Split(apre, "."), strings.Split(bpre, ".")
+	for i := 0; i < max(len(ap), len(bp)); i++ {
+		switch {
+		case i >= len(ap):
+			return -1
+		case i >= len(bp):
+			return 1
+		}
+		_, aerr := strconv.Atoi(ap[i])
+		_, berr := strconv.Atoi(bp[i])
+		var c int
+		switch {
+		case aerr == nil && berr == nil:
+			c = compareNumeric(ap[i], bp[i])
+		case aerr == nil:
+			c = -1
+		case berr == nil:
+			c = 1
+		default:
+			c = strings.Compare(ap[i], bp[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+// compareGem compares versions following Gem::Version. Segments are split on
+// dots and on boundaries between digits and letters, and a segment with
+// letters marks a pre-release which sorts before numeric segments.
+func compareGem(a, b string) int {
+	as, bs := gemSegments(a), gemSegments(b)
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		// missing segments are treated as zero
+		av, bv := segment(as, i), segment(bs, i)
+		an, bn := isNumeric(av), isNumeric(bv)
+		var c int
+		switch {
+		case an && bn:
+			c = compareNumeric(av, bv)
+		case an:
+			c = 1
+		case bn:
+			c = -1
+		default:
+			c = strings.Compare(av, bv)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+// compareDebian compares versions following dpkg's [epoch:]upstream[-revision] rules
+func compareDebian(a, b string) int {
+	ae, au, ar := debianParts(a)
+	be, bu, br := debianParts(b)
+	if c := compareNumeric(ae, be); c != 0 {
+		return c
+	}
+	if c := compareDebianPart(au, bu); c != 0 {
+		return c
+	}
+	return compareDebianPart(ar, br)
+}
+
+func debianParts(v string) (epoch, upstream, revision string) {
+	epoch = "0"
+	if e, rest, ok := strings.Cut(v, ":"); ok {
+		epoch, v = e, rest
+	}
+	upstream = v
+	if i := strings.LastIndex(v, "-"); i >= 0 {
+		upstream, revision = v[:i], v[i+1:]
+	}
+	return epoch, upstream, revision
+}
+
+// compareDebianPart compares alternating non-digit and digit runs like dpkg's verrevcmp
+func compareDebianPart(a, b string) int {
+	for a != "" || b != "" {
+		var an, bn string
+		an, a = splitWhile(a, func(r rune) bool { return !unicode.IsDigit(r) })
+		bn, b = splitWhile(b, func(r rune) bool { return !unicode.IsDigit(r) })
+		if c := compareDebianString(an, bn); c != 0 {
+			return c
+		}
+		an, a = splitWhile(a, unicode.IsDigit)
+		bn, b = splitWhile(b, unicode.IsDigit)
+		if c := compareNumeric(an, bn); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareDebianString(a, b string) int {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		if c := debianOrder(a, i) - debianOrder(b, i); c != 0 {
+			if c < 0 {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// debianOrder returns the sort weight of the character, where ~ sorts
+// before the end of the string and letters sort before other characters
+func debianOrder(s string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+	c := rune(s[i])
+	switch {
+	case c == '~':
+		return -1
+	case unicode.IsLetter(c):
+		return int(c)
+	default:
+		return int(c) + 256
+	}
+}
+
+func splitWhile(s string, f func(rune) bool) (string, string) {
+	for i, r := range s {
+		if !f(r) {
+			return s[:i], s[i:]
+		}
+	}
+	return s, ""
+}
+
+func gemSegments(v string) []string {
+	var (
+		segments []string
+		current  []rune
+	)
+	flush := func() {
+		if len(current) > 0 {
+			segments = append(segments, string(current))
+			current = nil
+		}
+	}
+	for _, r := range strings.TrimSpace(v) {
+		switch {
+		case r == '.' || r == '-':
+			flush()
+		case len(current) > 0 && unicode.IsDigit(r) != unicode.IsDigit(current[len(current)-1]):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+
+	return segments
+}
+
+func segment(s []string, i int) string {
+	if i < len(s) {
+		return s[i]
+	}
+	return "0"
+}
+
+func isNumeric(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// compareNumeric compares digit strings of any length
+func compareNumeric(a, b string) int {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,41 @@
+package osv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		ecosystem string
+		a, b      string
+		want      int
+	}{
+		{ecosystem: EcosystemNPM, a: "1.2.3", b: "1.2.3", want: 0},
+		{ecosystem: EcosystemNPM, a: "1.2.3", b: "1.10.0", want: -1},
+		{ecosystem: EcosystemNPM, a: "v2.0.0", b: "1.99.99", want: 1},
+		{ecosystem: EcosystemNPM, a: "1.0.0-alpha", b: "1.0.0", want: -1},
+		{ecosystem: EcosystemNPM, a: "1.0.0-alpha.2", b: "1.0.0-alpha.10", want: -1},
+		{ecosystem: EcosystemNPM, a: "1.0.0-beta", b: "1.0.0-alpha.1", want: 1},
+		{ecosystem: EcosystemNPM, a: "1.0", b: "1.0.0", want: 0},
+		{ecosystem: EcosystemRubyGems, a: "3.3.2", b: "3.3.10", want: -1},
+		{ecosystem: EcosystemRubyGems, a: "1.0", b: "1.0.0", want: 0},
+		{ecosystem: EcosystemRubyGems, a: "1.0.0.rc1", b: "1.0.0", want: -1},
+		{ecosystem: EcosystemRubyGems, a: "1.0.0.pre", b: "1.0.0.rc1", want: -1},
+		{ecosystem: EcosystemRubyGems, a: "1.13.10-x86_64-linux", b: "1.13.9", want: 1},
+		{ecosystem: EcosystemDebian, a: "8.5.0-2ubuntu10.6", b: "8.5.0-2ubuntu10.6", want: 0},
+		{ecosystem: EcosystemDebian, a: "8.5.0-2ubuntu10.5", b: "8.5.0-2ubuntu10.10", want: -1},
+		{ecosystem: EcosystemDebian, a: "1:1.0", b: "2.0", want: 1},
+		{ecosystem: EcosystemDebian, a: "1.0~rc1", b: "1.0", want: -1},
+		{ecosystem: EcosystemDebian, a: "1.0a", b: "1.0+", want: -1},
+		{ecosystem: EcosystemUbuntu + ":24.04:LTS", a: "3.0.13-0ubuntu3.4", b: "3.0.13-0ubuntu3.5", want: -1},
+		{ecosystem: EcosystemDebian, a: "6.11.0-17.17~24.04.2+2", b: "6.8.0-52.53", want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.want, Compare(tt.ecosystem, tt.a, tt.b))
+		})
+	}
+}